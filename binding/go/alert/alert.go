@@ -0,0 +1,447 @@
+// Package alert pages on sensitive governance events.
+//
+// A Daemon subscribes through the generated Watch* methods to the factory's
+// governance events and to upgrade, status and reclaim events of every pool,
+// following new pools as PoolCreated fires. Subscriptions survive node
+// restarts, see package subscription. Each event is evaluated against
+// the configured rules; matches are deduplicated and delivered to every sink.
+//
+// In the default fast delivery mode alerts go out as soon as an event is
+// seen, and an alert whose event a reorg removes is followed by a retraction.
+// In final mode events are held back until their block is final under the
+// network profile, see package finality.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/subscription"
+)
+
+// Source tells which kind of contract emitted an event.
+type Source string
+
+// Event sources.
+const (
+	SourceFactory Source = "factory"
+	SourcePool    Source = "pool"
+	// SourceRouter marks alerts raised by the router leftover monitor, see
+	// package leftover.
+	SourceRouter Source = "router"
+	// SourceWCROSS marks alerts raised by the WCROSS backing check, see
+	// package wcross. They come from state reads, not logs.
+	SourceWCROSS Source = "wcross"
+)
+
+// Watched lists the event names the daemon subscribes to.
+var Watched = []string{
+	"Upgraded", "PoolImplementationSet", "RouterSet", "RoleGranted", "RoleRevoked",
+	"DefaultAdminTransferScheduled", "ReclaimedFromPool", "PoolCreated",
+	"PoolStatusChanged", "Paused", "Unpaused", "RewardTokenRemoved", "TokensReclaimed",
+}
+
+var managerRole = crypto.Keccak256Hash([]byte("MANAGER_ROLE"))
+
+// Event is a watched event reduced to the fields rules match on.
+type Event struct {
+	Name     string         `json:"event"`
+	Source   Source         `json:"source"`
+	Contract common.Address `json:"contract"`
+	PoolID   *big.Int       `json:"poolId,omitempty"`
+	// Actor is the account behind the change: the role sender or pauser
+	// where the event names one, otherwise the transaction sender.
+	Actor   common.Address    `json:"actor"`
+	Amount  *big.Int          `json:"amount,omitempty"`
+	Status  *uint8            `json:"status,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+
+	Block    uint64      `json:"block"`
+	TxHash   common.Hash `json:"txHash"`
+	LogIndex uint        `json:"logIndex"`
+
+	blockHash common.Hash
+	removed   bool
+}
+
+func newEvent(name string, src Source, l types.Log) *Event {
+	return &Event{
+		Name: name, Source: src, Contract: l.Address,
+		Block: l.BlockNumber, TxHash: l.TxHash, LogIndex: l.Index,
+		blockHash: l.BlockHash, removed: l.Removed,
+	}
+}
+
+// log returns the log position of ev, for package finality.
+func (ev *Event) log() types.Log {
+	return types.Log{
+		Address: ev.Contract, BlockNumber: ev.Block, BlockHash: ev.blockHash,
+		TxHash: ev.TxHash, Index: ev.LogIndex, Removed: ev.removed,
+	}
+}
+
+// Alert is one rule match.
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Severity string    `json:"severity,omitempty"`
+	At       time.Time `json:"at"`
+	// Retracted marks the withdrawal of an earlier alert whose event was
+	// removed by a reorg.
+	Retracted bool `json:"retracted,omitempty"`
+	Event
+}
+
+// Text renders the alert as a single line.
+func (a *Alert) Text() string {
+	var b strings.Builder
+	if a.Retracted {
+		b.WriteString("RETRACTED ")
+	}
+	if a.Severity != "" {
+		fmt.Fprintf(&b, "[%s] ", strings.ToUpper(a.Severity))
+	}
+	fmt.Fprintf(&b, "%s: %s on %s %s", a.Rule, a.Name, a.Source, a.Contract.Hex())
+	if a.PoolID != nil {
+		fmt.Fprintf(&b, " (pool %s)", a.PoolID)
+	}
+	if a.Amount != nil {
+		fmt.Fprintf(&b, " amount %s", a.Amount)
+	}
+	for _, k := range sortedKeys(a.Details) {
+		fmt.Fprintf(&b, " %s=%s", k, a.Details[k])
+	}
+	if a.TxHash == (common.Hash{}) {
+		fmt.Fprintf(&b, " in block %d", a.Block)
+		return b.String()
+	}
+	fmt.Fprintf(&b, " by %s in block %d tx %s", a.Actor.Hex(), a.Block, a.TxHash.Hex())
+	return b.String()
+}
+
+// Daemon evaluates watched events against rules and delivers alerts.
+type Daemon struct {
+	net    *config.Network
+	rules  []Rule
+	sinks  []Sink
+	mode   finality.Mode
+	now    func() time.Time
+	sender func(ctx context.Context, ev *Event) (common.Address, error)
+
+	// OnError receives delivery, actor lookup and subscription failures;
+	// nil drops them.
+	OnError func(error)
+
+	mu     sync.Mutex
+	dedupe *dedupe
+}
+
+// New returns a Daemon for net using the rules and sinks in cfg.
+func New(net *config.Network, cfg *Config) (*Daemon, error) {
+	sinks, err := cfg.BuildSinks()
+	if err != nil {
+		return nil, err
+	}
+	d := &Daemon{
+		net:    net,
+		rules:  cfg.Rules,
+		sinks:  sinks,
+		mode:   cfg.Mode(),
+		now:    time.Now,
+		dedupe: newDedupe(cfg.Dedupe()),
+	}
+	d.sender = d.txSender
+	return d, nil
+}
+
+// SetSinks replaces the configured sinks.
+func (d *Daemon) SetSinks(sinks ...Sink) {
+	d.sinks = sinks
+}
+
+// Handle evaluates ev, delivers every new match to all sinks and returns the
+// alerts raised. Logs removed by a reorg never alert; see Retract.
+func (d *Daemon) Handle(ctx context.Context, ev *Event) []*Alert {
+	if ev.removed {
+		return nil
+	}
+	if ev.Actor == (common.Address{}) {
+		actor, err := d.sender(ctx, ev)
+		if err != nil {
+			d.report(fmt.Errorf("alert: sender of %s: %w", ev.TxHash.Hex(), err))
+		}
+		ev.Actor = actor
+	}
+	now := d.now()
+	var out []*Alert
+	for i := range d.rules {
+		r := &d.rules[i]
+		if !r.Match(ev) {
+			continue
+		}
+		d.mu.Lock()
+		first := d.dedupe.first(alertKey(r, ev), now)
+		d.mu.Unlock()
+		if !first {
+			continue
+		}
+		a := &Alert{Rule: r.Name, Severity: r.Severity, At: now, Event: *ev}
+		d.send(ctx, a)
+		out = append(out, a)
+	}
+	return out
+}
+
+// Retract withdraws the alerts raised for ev, an event since removed by a
+// reorg, and returns the retractions sent. ev must be the event as passed to
+// Handle; events that never alerted are ignored.
+func (d *Daemon) Retract(ctx context.Context, ev *Event) []*Alert {
+	now := d.now()
+	var out []*Alert
+	for i := range d.rules {
+		r := &d.rules[i]
+		key := alertKey(r, ev)
+		d.mu.Lock()
+		sent := d.dedupe.has(key, now) && d.dedupe.first(key+"/retracted", now)
+		d.mu.Unlock()
+		if !sent {
+			continue
+		}
+		a := &Alert{Rule: r.Name, Severity: r.Severity, At: now, Retracted: true, Event: *ev}
+		d.send(ctx, a)
+		out = append(out, a)
+	}
+	return out
+}
+
+func alertKey(r *Rule, ev *Event) string {
+	return fmt.Sprintf("%s/%s/%d", r.Name, ev.TxHash.Hex(), ev.LogIndex)
+}
+
+func (d *Daemon) send(ctx context.Context, a *Alert) {
+	for _, s := range d.sinks {
+		if err := s.Send(ctx, a); err != nil {
+			d.report(err)
+		}
+	}
+}
+
+func (d *Daemon) report(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+func (d *Daemon) txSender(ctx context.Context, ev *Event) (common.Address, error) {
+	tx, _, err := d.net.Client.TransactionByHash(ctx, ev.TxHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	receipt, err := d.net.Client.TransactionReceipt(ctx, ev.TxHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return d.net.Client.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+}
+
+// Run subscribes to the factory and all pools and handles events until ctx
+// is done, holding them back or retracting them according to the delivery
+// mode. Subscriptions reconnect on failure and backfill the blocks they
+// missed; connection errors are passed to OnError. The RPC endpoint must
+// support subscriptions (ws:// or ipc).
+func (d *Daemon) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := &watcher{ctx: ctx, head: d.net.Client, out: make(chan *Event, 64), onError: d.report}
+
+	pools, err := d.net.Pools(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return err
+	}
+	watchFactory(w, d.net.Factory)
+	ids := make(map[common.Address]*big.Int, len(pools))
+	for _, p := range pools {
+		ids[p.Address] = p.ID
+		watchPool(w, p.Pool, p.ID, 0)
+	}
+
+	fin := finality.New(d.net.Client, (*Event).log, finality.Config{
+		Mode:   d.mode,
+		Policy: finality.ForProfile(d.net.Profile),
+	})
+	deliver := func(ups []finality.Update[*Event]) {
+		for _, u := range ups {
+			if u.Retracted {
+				d.Retract(ctx, u.Event)
+			} else {
+				d.Handle(ctx, u.Event)
+			}
+		}
+	}
+	tick := time.NewTicker(finality.DefaultPollInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case ev := <-w.out:
+			if ev.Name == "PoolCreated" && !ev.removed {
+				addr := common.HexToAddress(ev.Details["pool"])
+				if _, ok := ids[addr]; !ok {
+					ids[addr] = ev.PoolID
+					pool, err := d.net.Pool(addr)
+					if err != nil {
+						return fmt.Errorf("alert: watch pool %s: %w", ev.PoolID, err)
+					}
+					// Start at the creation block so nothing emitted
+					// right after creation is missed.
+					watchPool(w, pool, ev.PoolID, ev.Block)
+				}
+			}
+			deliver(fin.Add(ev))
+		case <-tick.C:
+			ups, err := fin.Advance(ctx)
+			if err != nil {
+				d.report(err)
+			}
+			deliver(ups)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type watcher struct {
+	ctx     context.Context
+	head    ethereum.BlockNumberReader
+	out     chan *Event
+	onError func(error)
+}
+
+// watch runs a resilient subscription for pair from block from (zero for
+// the head) and forwards its events, converted by conv, to w.out.
+func watch[T any](w *watcher, from uint64, pair subscription.Pair[T], conv func(T) *Event) {
+	sub := subscription.New(w.head, pair, subscription.Config{From: from, OnError: w.onError})
+	go sub.Run(w.ctx)
+	go func() {
+		for v := range sub.C() {
+			select {
+			case w.out <- conv(v):
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func watchFactory(w *watcher, f *binding.CrossGameReward) {
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardUpgraded]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardUpgraded) (event.Subscription, error) {
+			return f.WatchUpgraded(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterUpgraded(o, nil) },
+	}, fromFactoryUpgraded)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardPoolImplementationSet]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolImplementationSet) (event.Subscription, error) {
+			return f.WatchPoolImplementationSet(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterPoolImplementationSet(o, nil) },
+	}, fromPoolImplementationSet)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardRouterSet]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardRouterSet) (event.Subscription, error) {
+			return f.WatchRouterSet(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterRouterSet(o, nil) },
+	}, fromRouterSet)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardRoleGranted]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardRoleGranted) (event.Subscription, error) {
+			return f.WatchRoleGranted(o, ch, nil, nil, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterRoleGranted(o, nil, nil, nil) },
+	}, fromRoleGranted)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardRoleRevoked]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardRoleRevoked) (event.Subscription, error) {
+			return f.WatchRoleRevoked(o, ch, nil, nil, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterRoleRevoked(o, nil, nil, nil) },
+	}, fromRoleRevoked)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardDefaultAdminTransferScheduled]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardDefaultAdminTransferScheduled) (event.Subscription, error) {
+			return f.WatchDefaultAdminTransferScheduled(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) {
+			return f.FilterDefaultAdminTransferScheduled(o, nil)
+		},
+	}, fromAdminTransferScheduled)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardReclaimedFromPool]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardReclaimedFromPool) (event.Subscription, error) {
+			return f.WatchReclaimedFromPool(o, ch, nil, nil, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) {
+			return f.FilterReclaimedFromPool(o, nil, nil, nil)
+		},
+	}, fromReclaimedFromPool)
+	watch(w, 0, subscription.Pair[*binding.CrossGameRewardPoolCreated]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolCreated) (event.Subscription, error) {
+			return f.WatchPoolCreated(o, ch, nil, nil, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return f.FilterPoolCreated(o, nil, nil, nil) },
+	}, fromPoolCreated)
+}
+
+func watchPool(w *watcher, p *binding.CrossGameRewardPool, id *big.Int, from uint64) {
+	withID := func(ev *Event) *Event {
+		ev.PoolID = id
+		return ev
+	}
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolUpgraded]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolUpgraded) (event.Subscription, error) {
+			return p.WatchUpgraded(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterUpgraded(o, nil) },
+	}, func(e *binding.CrossGameRewardPoolUpgraded) *Event { return withID(fromPoolUpgraded(e)) })
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolPoolStatusChanged]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolPoolStatusChanged) (event.Subscription, error) {
+			return p.WatchPoolStatusChanged(o, ch)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterPoolStatusChanged(o) },
+	}, func(e *binding.CrossGameRewardPoolPoolStatusChanged) *Event { return withID(fromPoolStatusChanged(e)) })
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolPaused]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolPaused) (event.Subscription, error) {
+			return p.WatchPaused(o, ch)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterPaused(o) },
+	}, func(e *binding.CrossGameRewardPoolPaused) *Event { return withID(fromPaused(e)) })
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolUnpaused]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolUnpaused) (event.Subscription, error) {
+			return p.WatchUnpaused(o, ch)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterUnpaused(o) },
+	}, func(e *binding.CrossGameRewardPoolUnpaused) *Event { return withID(fromUnpaused(e)) })
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolRewardTokenRemoved]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolRewardTokenRemoved) (event.Subscription, error) {
+			return p.WatchRewardTokenRemoved(o, ch, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterRewardTokenRemoved(o, nil) },
+	}, func(e *binding.CrossGameRewardPoolRewardTokenRemoved) *Event {
+		return withID(fromRewardTokenRemoved(e))
+	})
+	watch(w, from, subscription.Pair[*binding.CrossGameRewardPoolTokensReclaimed]{
+		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolTokensReclaimed) (event.Subscription, error) {
+			return p.WatchTokensReclaimed(o, ch, nil, nil)
+		},
+		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) { return p.FilterTokensReclaimed(o, nil, nil) },
+	}, func(e *binding.CrossGameRewardPoolTokensReclaimed) *Event { return withID(fromTokensReclaimed(e)) })
+}
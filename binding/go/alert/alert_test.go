@@ -0,0 +1,219 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	factoryAddr = common.HexToAddress("0xfa")
+	operator    = common.HexToAddress("0x0a")
+	attacker    = common.HexToAddress("0xee")
+)
+
+// standIn records the bodies posted to it.
+type standIn struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (s *standIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.bodies = append(s.bodies, string(raw))
+	s.mu.Unlock()
+}
+
+func (s *standIn) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func writeConfig(t *testing.T, webhook, slack string) *Config {
+	t.Helper()
+	raw := `{
+		"dedupeWindow": "10m",
+		"sinks": [{"type": "webhook", "url": "` + webhook + `"}, {"type": "slack", "url": "` + slack + `"}],
+		"rules": [
+			{"name": "large-reclaim", "severity": "high", "events": ["ReclaimedFromPool"], "minAmount": 1000, "allow": ["` + operator.Hex() + `"]},
+			{"name": "paused", "events": ["PoolStatusChanged"], "statuses": [2], "pools": [1]}
+		]
+	}`
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func reclaim(amount int64, tx byte) *Event {
+	return fromReclaimedFromPool(&binding.CrossGameRewardReclaimedFromPool{
+		PoolId: big.NewInt(1), Token: common.HexToAddress("0x70"), To: common.HexToAddress("0x71"),
+		Amount: big.NewInt(amount),
+		Raw:    types.Log{Address: factoryAddr, TxHash: common.Hash{tx}, BlockNumber: 9},
+	})
+}
+
+func TestDaemonDeliversToHTTPSinks(t *testing.T) {
+	webhook, slack := new(standIn), new(standIn)
+	whSrv, slSrv := httptest.NewServer(webhook), httptest.NewServer(slack)
+	defer whSrv.Close()
+	defer slSrv.Close()
+
+	d, err := New(nil, writeConfig(t, whSrv.URL, slSrv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.OnError = func(err error) { t.Error(err) }
+	d.now = func() time.Time { return time.Unix(1_700_000_000, 0) }
+	d.sender = func(context.Context, *Event) (common.Address, error) { return attacker, nil }
+
+	ctx := context.Background()
+	if got := d.Handle(ctx, reclaim(5000, 1)); len(got) != 1 {
+		t.Fatalf("alerts = %d, want 1", len(got))
+	}
+	if got := d.Handle(ctx, reclaim(5000, 1)); len(got) != 0 {
+		t.Fatal("duplicate delivery was not suppressed")
+	}
+	if got := d.Handle(ctx, reclaim(10, 2)); len(got) != 0 {
+		t.Fatal("amount below threshold alerted")
+	}
+
+	hooks := webhook.received()
+	if len(hooks) != 1 {
+		t.Fatalf("webhook received %d posts, want 1", len(hooks))
+	}
+	var a Alert
+	if err := json.Unmarshal([]byte(hooks[0]), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Rule != "large-reclaim" || a.Actor != attacker || a.Amount.Int64() != 5000 || a.PoolID.Int64() != 1 {
+		t.Fatalf("unexpected alert: %+v", a)
+	}
+	texts := slack.received()
+	if len(texts) != 1 || !strings.Contains(texts[0], `"text":"[HIGH] large-reclaim: ReclaimedFromPool`) {
+		t.Fatalf("unexpected slack posts: %q", texts)
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	cfg := writeConfig(t, "http://unused", "http://unused")
+	reclaimRule, pausedRule := &cfg.Rules[0], &cfg.Rules[1]
+
+	allowed := reclaim(5000, 1)
+	allowed.Actor = operator
+	if reclaimRule.Match(allowed) {
+		t.Error("allow-listed actor matched")
+	}
+
+	status := func(pool int64, to uint8) *Event {
+		ev := fromPoolStatusChanged(&binding.CrossGameRewardPoolPoolStatusChanged{OldStatus: 0, NewStatus: to})
+		ev.PoolID = big.NewInt(pool)
+		return ev
+	}
+	for _, tc := range []struct {
+		ev   *Event
+		want bool
+	}{
+		{status(1, 2), true},
+		{status(1, 1), false},
+		{status(2, 2), false},
+		{reclaim(5000, 1), false},
+	} {
+		if got := pausedRule.Match(tc.ev); got != tc.want {
+			t.Errorf("%s pool %s status %v: match = %v, want %v", tc.ev.Name, tc.ev.PoolID, *tc.ev.Status, got, tc.want)
+		}
+	}
+}
+
+func TestRemovedLogsNeverAlert(t *testing.T) {
+	d, err := New(nil, writeConfig(t, "http://unused", "http://unused"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetSinks()
+	d.sender = func(context.Context, *Event) (common.Address, error) { return attacker, nil }
+	ev := reclaim(5000, 1)
+	ev.removed = true
+	if got := d.Handle(context.Background(), ev); len(got) != 0 {
+		t.Fatal("removed log alerted")
+	}
+}
+
+func TestReorgRetractsFastAlert(t *testing.T) {
+	d, err := New(nil, writeConfig(t, "http://unused", "http://unused"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetSinks()
+	d.sender = func(context.Context, *Event) (common.Address, error) { return attacker, nil }
+	fin := finality.New(nil, (*Event).log, finality.Config{Mode: finality.Fast})
+	ctx := context.Background()
+
+	ev := reclaim(5000, 1)
+	ups := fin.Add(ev)
+	if len(ups) != 1 || len(d.Handle(ctx, ups[0].Event)) == 0 {
+		t.Fatal("reclaim did not alert")
+	}
+	removed := reclaim(5000, 1)
+	removed.removed = true
+	ups = fin.Add(removed)
+	if len(ups) != 1 || !ups[0].Retracted {
+		t.Fatalf("updates after removal = %+v", ups)
+	}
+	got := d.Retract(ctx, ups[0].Event)
+	if len(got) == 0 || !got[0].Retracted || !strings.HasPrefix(got[0].Text(), "RETRACTED ") {
+		t.Fatalf("retractions = %+v", got)
+	}
+	if again := d.Retract(ctx, ups[0].Event); len(again) != 0 {
+		t.Fatal("retraction sent twice")
+	}
+	if never := d.Retract(ctx, reclaim(5000, 2)); len(never) != 0 {
+		t.Fatal("retracted an event that never alerted")
+	}
+}
+
+func TestDedupeWindowExpires(t *testing.T) {
+	d := newDedupe(time.Minute)
+	now := time.Unix(0, 0)
+	if !d.first("k", now) || d.first("k", now.Add(30*time.Second)) {
+		t.Fatal("repeat inside window was not suppressed")
+	}
+	if !d.first("k", now.Add(2*time.Minute)) {
+		t.Fatal("key not released after window")
+	}
+}
+
+func TestLoadConfigRejectsUnknownEvent(t *testing.T) {
+	c := &Config{Rules: []Rule{{Name: "x", Events: []string{"Transfer"}}}}
+	if err := c.validate(); err == nil {
+		t.Fatal("unwatched event accepted")
+	}
+}
+
+func TestExampleConfig(t *testing.T) {
+	if _, err := LoadConfig("rules.example.json"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,133 @@
+package alert
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+func roleName(role [32]byte) string {
+	switch common.Hash(role) {
+	case common.Hash{}:
+		return "DEFAULT_ADMIN_ROLE"
+	case managerRole:
+		return "MANAGER_ROLE"
+	}
+	return common.Hash(role).Hex()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fromFactoryUpgraded(e *binding.CrossGameRewardUpgraded) *Event {
+	ev := newEvent("Upgraded", SourceFactory, e.Raw)
+	ev.Details = map[string]string{"implementation": e.Implementation.Hex()}
+	return ev
+}
+
+func fromPoolImplementationSet(e *binding.CrossGameRewardPoolImplementationSet) *Event {
+	ev := newEvent("PoolImplementationSet", SourceFactory, e.Raw)
+	ev.Details = map[string]string{"implementation": e.Implementation.Hex()}
+	return ev
+}
+
+func fromRouterSet(e *binding.CrossGameRewardRouterSet) *Event {
+	ev := newEvent("RouterSet", SourceFactory, e.Raw)
+	ev.Details = map[string]string{"router": e.Router.Hex()}
+	return ev
+}
+
+func fromRoleGranted(e *binding.CrossGameRewardRoleGranted) *Event {
+	ev := newEvent("RoleGranted", SourceFactory, e.Raw)
+	ev.Actor = e.Sender
+	ev.Details = map[string]string{"role": roleName(e.Role), "account": e.Account.Hex()}
+	return ev
+}
+
+func fromRoleRevoked(e *binding.CrossGameRewardRoleRevoked) *Event {
+	ev := newEvent("RoleRevoked", SourceFactory, e.Raw)
+	ev.Actor = e.Sender
+	ev.Details = map[string]string{"role": roleName(e.Role), "account": e.Account.Hex()}
+	return ev
+}
+
+func fromAdminTransferScheduled(e *binding.CrossGameRewardDefaultAdminTransferScheduled) *Event {
+	ev := newEvent("DefaultAdminTransferScheduled", SourceFactory, e.Raw)
+	ev.Details = map[string]string{"newAdmin": e.NewAdmin.Hex(), "acceptSchedule": e.AcceptSchedule.String()}
+	return ev
+}
+
+func fromReclaimedFromPool(e *binding.CrossGameRewardReclaimedFromPool) *Event {
+	ev := newEvent("ReclaimedFromPool", SourceFactory, e.Raw)
+	ev.PoolID = e.PoolId
+	ev.Amount = e.Amount
+	ev.Details = map[string]string{"token": e.Token.Hex(), "to": e.To.Hex()}
+	return ev
+}
+
+func fromPoolCreated(e *binding.CrossGameRewardPoolCreated) *Event {
+	ev := newEvent("PoolCreated", SourceFactory, e.Raw)
+	ev.PoolID = e.PoolId
+	ev.Details = map[string]string{"pool": e.PoolAddress.Hex(), "depositToken": e.DepositToken.Hex(), "name": e.Name}
+	return ev
+}
+
+func fromPoolUpgraded(e *binding.CrossGameRewardPoolUpgraded) *Event {
+	ev := newEvent("Upgraded", SourcePool, e.Raw)
+	ev.Details = map[string]string{"implementation": e.Implementation.Hex()}
+	return ev
+}
+
+func fromPoolStatusChanged(e *binding.CrossGameRewardPoolPoolStatusChanged) *Event {
+	ev := newEvent("PoolStatusChanged", SourcePool, e.Raw)
+	status := e.NewStatus
+	ev.Status = &status
+	ev.Details = map[string]string{"from": statusName(e.OldStatus), "to": statusName(e.NewStatus)}
+	return ev
+}
+
+func fromPaused(e *binding.CrossGameRewardPoolPaused) *Event {
+	ev := newEvent("Paused", SourcePool, e.Raw)
+	ev.Actor = e.Account
+	return ev
+}
+
+func fromUnpaused(e *binding.CrossGameRewardPoolUnpaused) *Event {
+	ev := newEvent("Unpaused", SourcePool, e.Raw)
+	ev.Actor = e.Account
+	return ev
+}
+
+func fromRewardTokenRemoved(e *binding.CrossGameRewardPoolRewardTokenRemoved) *Event {
+	ev := newEvent("RewardTokenRemoved", SourcePool, e.Raw)
+	ev.Details = map[string]string{"token": e.Token.Hex()}
+	return ev
+}
+
+func fromTokensReclaimed(e *binding.CrossGameRewardPoolTokensReclaimed) *Event {
+	ev := newEvent("TokensReclaimed", SourcePool, e.Raw)
+	ev.Amount = e.Amount
+	ev.Details = map[string]string{"token": e.Token.Hex(), "to": e.To.Hex()}
+	return ev
+}
+
+func statusName(s uint8) string {
+	switch s {
+	case 0:
+		return "Active"
+	case 1:
+		return "Inactive"
+	case 2:
+		return "Paused"
+	}
+	return strconv.Itoa(int(s))
+}
@@ -0,0 +1,179 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+)
+
+// ErrInvalidConfig is returned for rule files that cannot be used.
+var ErrInvalidConfig = errors.New("alert: invalid config")
+
+// DefaultDedupeWindow is used when the config does not set dedupeWindow.
+const DefaultDedupeWindow = time.Hour
+
+// Config is the rules file read by LoadConfig.
+type Config struct {
+	Rules []Rule       `json:"rules"`
+	Sinks []SinkConfig `json:"sinks"`
+	// DedupeWindow is how long an alert is suppressed after it was sent,
+	// as a Go duration string.
+	DedupeWindow string `json:"dedupeWindow,omitempty"`
+	// Delivery is "fast" (default) to alert as soon as an event is seen and
+	// send a retraction if a reorg removes it, or "final" to alert only once
+	// the event's block is final under the network profile.
+	Delivery string `json:"delivery,omitempty"`
+
+	dedupe time.Duration
+	mode   finality.Mode
+}
+
+// Dedupe returns the parsed dedupe window.
+func (c *Config) Dedupe() time.Duration {
+	return c.dedupe
+}
+
+// Mode returns the parsed delivery mode.
+func (c *Config) Mode() finality.Mode {
+	return c.mode
+}
+
+// Rule selects the events that raise an alert. Empty fields match anything.
+type Rule struct {
+	Name     string `json:"name"`
+	Severity string `json:"severity,omitempty"`
+	// Events are event names such as "Upgraded" or "RoleGranted".
+	Events []string `json:"events,omitempty"`
+	// Sources restricts matches to "factory" or "pool" emitters.
+	Sources []Source `json:"sources,omitempty"`
+	// Pools are pool IDs. Factory events without a pool ID never match a
+	// rule that lists pools.
+	Pools []uint64 `json:"pools,omitempty"`
+	// Statuses are new PoolStatus values (0 Active, 1 Inactive, 2 Paused)
+	// for PoolStatusChanged.
+	Statuses []uint8 `json:"statuses,omitempty"`
+	// MinAmount only matches events carrying an amount of at least this much.
+	MinAmount *big.Int `json:"minAmount,omitempty"`
+	// Allow lists actors whose actions never alert.
+	Allow []common.Address `json:"allow,omitempty"`
+}
+
+// Match reports whether ev raises an alert under r.
+func (r *Rule) Match(ev *Event) bool {
+	if len(r.Events) > 0 && !slices.Contains(r.Events, ev.Name) {
+		return false
+	}
+	if len(r.Sources) > 0 && !slices.Contains(r.Sources, ev.Source) {
+		return false
+	}
+	if len(r.Pools) > 0 && (ev.PoolID == nil || !ev.PoolID.IsUint64() || !slices.Contains(r.Pools, ev.PoolID.Uint64())) {
+		return false
+	}
+	if len(r.Statuses) > 0 && (ev.Status == nil || !slices.Contains(r.Statuses, *ev.Status)) {
+		return false
+	}
+	if r.MinAmount != nil && (ev.Amount == nil || ev.Amount.Cmp(r.MinAmount) < 0) {
+		return false
+	}
+	return !slices.Contains(r.Allow, ev.Actor)
+}
+
+// LoadConfig reads and validates the rules file at path.
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("alert: parse %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	c.dedupe = DefaultDedupeWindow
+	if c.DedupeWindow != "" {
+		d, err := time.ParseDuration(c.DedupeWindow)
+		if err != nil || d < 0 {
+			return fmt.Errorf("%w: dedupeWindow %q", ErrInvalidConfig, c.DedupeWindow)
+		}
+		c.dedupe = d
+	}
+	mode, err := finality.ParseMode(c.Delivery)
+	if err != nil {
+		return fmt.Errorf("%w: delivery %q", ErrInvalidConfig, c.Delivery)
+	}
+	c.mode = mode
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("%w: no rules", ErrInvalidConfig)
+	}
+	seen := make(map[string]bool, len(c.Rules))
+	for i, r := range c.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("%w: rule %d has no name", ErrInvalidConfig, i)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("%w: duplicate rule %q", ErrInvalidConfig, r.Name)
+		}
+		seen[r.Name] = true
+		for _, name := range r.Events {
+			if !slices.Contains(Watched, name) {
+				return fmt.Errorf("%w: rule %q: event %q is not watched", ErrInvalidConfig, r.Name, name)
+			}
+		}
+		for _, s := range r.Sources {
+			if s != SourceFactory && s != SourcePool {
+				return fmt.Errorf("%w: rule %q: unknown source %q", ErrInvalidConfig, r.Name, s)
+			}
+		}
+	}
+	for i, s := range c.Sinks {
+		if _, err := s.build(); err != nil {
+			return fmt.Errorf("sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// dedupe remembers alert keys for a fixed window.
+type dedupe struct {
+	window time.Duration
+	seen   map[string]time.Time
+}
+
+func newDedupe(window time.Duration) *dedupe {
+	return &dedupe{window: window, seen: make(map[string]time.Time)}
+}
+
+// first reports whether key was not seen within the window before now and
+// records it.
+func (d *dedupe) first(key string, now time.Time) bool {
+	for k, at := range d.seen {
+		if now.Sub(at) >= d.window {
+			delete(d.seen, k)
+		}
+	}
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// has reports whether key was recorded within the window before now.
+func (d *dedupe) has(key string, now time.Time) bool {
+	at, ok := d.seen[key]
+	return ok && now.Sub(at) < d.window
+}
@@ -0,0 +1,118 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Sink delivers alerts.
+type Sink interface {
+	Send(ctx context.Context, a *Alert) error
+}
+
+// SinkConfig describes a sink in the rules file. Type is "webhook", "slack"
+// or "stdout"; the HTTP sinks need URL.
+type SinkConfig struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+}
+
+func (s SinkConfig) build() (Sink, error) {
+	switch s.Type {
+	case "webhook":
+		if s.URL == "" {
+			return nil, fmt.Errorf("%w: webhook sink needs url", ErrInvalidConfig)
+		}
+		return &Webhook{URL: s.URL}, nil
+	case "slack":
+		if s.URL == "" {
+			return nil, fmt.Errorf("%w: slack sink needs url", ErrInvalidConfig)
+		}
+		return &Slack{URL: s.URL}, nil
+	case "stdout":
+		return &Writer{W: os.Stdout}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown sink type %q", ErrInvalidConfig, s.Type)
+}
+
+// BuildSinks builds the configured sinks, defaulting to stdout.
+func (c *Config) BuildSinks() ([]Sink, error) {
+	if len(c.Sinks) == 0 {
+		return []Sink{&Writer{W: os.Stdout}}, nil
+	}
+	out := make([]Sink, 0, len(c.Sinks))
+	for _, s := range c.Sinks {
+		sink, err := s.build()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sink)
+	}
+	return out, nil
+}
+
+// Webhook posts each alert as JSON.
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+// Send implements Sink.
+func (w *Webhook) Send(ctx context.Context, a *Alert) error {
+	return postJSON(ctx, w.Client, w.URL, a)
+}
+
+// Slack posts each alert as a Slack-compatible {"text": ...} message.
+type Slack struct {
+	URL    string
+	Client *http.Client // nil means http.DefaultClient
+}
+
+// Send implements Sink.
+func (s *Slack) Send(ctx context.Context, a *Alert) error {
+	return postJSON(ctx, s.Client, s.URL, map[string]string{"text": a.Text()})
+}
+
+// Writer writes each alert as a JSON line.
+type Writer struct {
+	W  io.Writer
+	mu sync.Mutex
+}
+
+// Send implements Sink.
+func (w *Writer) Send(_ context.Context, a *Alert) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return json.NewEncoder(w.W).Encode(a)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert: post %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alert: post %s: %s", url, resp.Status)
+	}
+	return nil
+}
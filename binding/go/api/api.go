@@ -0,0 +1,323 @@
+// Package api serves protocol state and user positions over HTTP/JSON.
+//
+// Every endpoint accepts ?block= with a block number or one of the tags
+// latest (default), safe, finalized and final. final is the newest block
+// that is final under the network profile (its confirmation depth or the
+// finalized tag, see package finality). All reads of a response are pinned
+// to that block, which is echoed in the response envelope and the
+// X-Block-Number header. Amounts are decimal strings. Responses are cached
+// by block hash, so a reorged block is never served from the cache, and the
+// least recently used responses are evicted past a fixed bound. The
+// endpoints are documented in openapi.json, served at /openapi.json.
+//
+// The /leaderboards endpoints are served from a leaderboard.Board set with
+// SetLeaderboard instead. They ignore ?block= and report the last indexed
+// block.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+var errBadRequest = errors.New("bad request")
+
+type headerReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// handler produces the data of one endpoint from reads pinned by opts.
+type handler func(opts *bind.CallOpts, r *http.Request) (any, error)
+
+type route struct {
+	path string
+	h    handler
+}
+
+// Server is the HTTP API of one network.
+type Server struct {
+	reader  *query.Reader
+	headers headerReader
+	policy  finality.Policy
+	board   *leaderboard.Board
+	cache   *cache
+	mux     *http.ServeMux
+}
+
+// New returns a Server reading from net.
+func New(net *config.Network) *Server {
+	s := &Server{reader: query.New(net), headers: net.Client, policy: finality.ForProfile(net.Profile)}
+	s.init()
+	return s
+}
+
+func (s *Server) init() {
+	s.cache = newCache(cacheEntries)
+	s.mux = http.NewServeMux()
+	for _, rt := range s.routes() {
+		h := rt.h
+		s.mux.HandleFunc("GET "+rt.path, func(w http.ResponseWriter, r *http.Request) { s.serve(w, r, h) })
+	}
+	for _, rt := range s.indexRoutes() {
+		h := rt.h
+		s.mux.HandleFunc("GET "+rt.path, func(w http.ResponseWriter, r *http.Request) { s.serveIndex(w, r, h) })
+	}
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("ok\n")) })
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{"/pools", s.pools},
+		{"/pools/{id}", s.pool},
+		{"/pools/{id}/reward-tokens", s.rewardTokens},
+		{"/users/{addr}/positions", s.positions},
+		{"/users/{addr}/pending", s.pending},
+		{"/tokens/{addr}/total-deposited", s.totalDeposited},
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, h handler) {
+	tag, err := s.blockTag(r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	header, err := s.headers.HeaderByNumber(r.Context(), tag)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("resolve block: %w", err))
+		return
+	}
+	block, hash := header.Number.Uint64(), header.Hash()
+	key := cacheRequest(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Block-Number", strconv.FormatUint(block, 10))
+	if body, ok := s.cache.get(hash, key); ok {
+		w.Header().Set("X-Cache", "hit")
+		w.Write(body)
+		return
+	}
+
+	data, err := h(&bind.CallOpts{Context: r.Context(), BlockNumber: header.Number}, r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	body, err := json.Marshal(Envelope{Block: block, BlockHash: hash, Data: data})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.cache.put(hash, key, body)
+	w.Header().Set("X-Cache", "miss")
+	w.Write(body)
+}
+
+// cacheRequest identifies a request independently of its block parameter.
+func cacheRequest(r *http.Request) string {
+	q := r.URL.Query()
+	q.Del("block")
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// blockTag resolves the block parameter of r, including the final tag.
+func (s *Server) blockTag(r *http.Request) (*big.Int, error) {
+	v := r.URL.Query().Get("block")
+	if v != "final" {
+		return parseBlockTag(v)
+	}
+	n, err := s.policy.FinalBlock(r.Context(), s.headers)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(n), nil
+}
+
+// parseBlockTag returns the block number for HeaderByNumber; nil means
+// latest. Numbers are decimal or 0x hex.
+func parseBlockTag(v string) (*big.Int, error) {
+	switch v {
+	case "", "latest":
+		return nil, nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	}
+	digits, base := v, 10
+	if h, ok := strings.CutPrefix(strings.ToLower(v), "0x"); ok {
+		digits, base = h, 16
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok || n.Sign() < 0 || !n.IsUint64() {
+		return nil, fmt.Errorf("%w: invalid block %q", errBadRequest, v)
+	}
+	return n, nil
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, query.ErrPoolNotFound), errors.Is(err, leaderboard.ErrUnknownWindow):
+		return http.StatusNotFound
+	case errors.Is(err, query.ErrNoRouter):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func poolID(r *http.Request) (*big.Int, error) {
+	v := r.PathValue("id")
+	id, ok := new(big.Int).SetString(v, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid pool id %q", errBadRequest, v)
+	}
+	return id, nil
+}
+
+func address(r *http.Request) (common.Address, error) {
+	v := r.PathValue("addr")
+	if !common.IsHexAddress(v) {
+		return common.Address{}, fmt.Errorf("%w: invalid address %q", errBadRequest, v)
+	}
+	return common.HexToAddress(v), nil
+}
+
+func (s *Server) pools(opts *bind.CallOpts, r *http.Request) (any, error) {
+	active, err := boolParam(r, "active")
+	if err != nil {
+		return nil, err
+	}
+	pools, err := s.reader.Pools(opts, active)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Pool, 0, len(pools))
+	for _, p := range pools {
+		out = append(out, newPool(p))
+	}
+	return out, nil
+}
+
+func (s *Server) pool(opts *bind.CallOpts, r *http.Request) (any, error) {
+	id, err := poolID(r)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.reader.Pool(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	return newPool(p), nil
+}
+
+func (s *Server) rewardTokens(opts *bind.CallOpts, r *http.Request) (any, error) {
+	id, err := poolID(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.reader.RewardTokens(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RewardToken, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, newRewardToken(t))
+	}
+	return out, nil
+}
+
+func (s *Server) positions(opts *bind.CallOpts, r *http.Request) (any, error) {
+	user, err := address(r)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := s.reader.Positions(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Position, 0, len(positions))
+	for _, p := range positions {
+		out = append(out, newPosition(p))
+	}
+	return out, nil
+}
+
+func (s *Server) pending(opts *bind.CallOpts, r *http.Request) (any, error) {
+	user, err := address(r)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := s.reader.Positions(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	out := []PendingReward{}
+	for _, p := range positions {
+		for _, rw := range p.Pending {
+			if rw.Amount.Sign() > 0 {
+				out = append(out, PendingReward{PoolID: amount(p.PoolID), Reward: newReward(rw)})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) totalDeposited(opts *bind.CallOpts, r *http.Request) (any, error) {
+	token, err := address(r)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.reader.TotalDeposited(opts, token)
+	if err != nil {
+		return nil, err
+	}
+	return TotalDeposited{Token: token, TotalDeposited: amount(total)}, nil
+}
+
+func boolParam(r *http.Request, name string) (bool, error) {
+	v := strings.TrimSpace(r.URL.Query().Get(name))
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid %s %q", errBadRequest, name, v)
+	}
+	return b, nil
+}
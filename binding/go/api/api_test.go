@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+type fakeHeaders struct {
+	head uint64
+	fork byte // changes every block hash
+}
+
+func (f *fakeHeaders) HeaderByNumber(_ context.Context, n *big.Int) (*types.Header, error) {
+	switch {
+	case n == nil:
+		n = new(big.Int).SetUint64(f.head)
+	case n.Sign() < 0:
+		n = new(big.Int).SetUint64(f.head - 2)
+	}
+	return &types.Header{Number: n, Extra: []byte{f.fork}}, nil
+}
+
+func testServer(t *testing.T, h handler) (*Server, *fakeHeaders, *int) {
+	t.Helper()
+	headers := &fakeHeaders{head: 100}
+	calls := new(int)
+	s := &Server{headers: headers}
+	s.init()
+	s.mux.HandleFunc("GET /test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.serve(w, r, func(opts *bind.CallOpts, r *http.Request) (any, error) {
+			*calls++
+			return h(opts, r)
+		})
+	})
+	return s, headers, calls
+}
+
+func get(t *testing.T, s *Server, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestServeEnvelopeAndCache(t *testing.T) {
+	s, headers, calls := testServer(t, func(opts *bind.CallOpts, r *http.Request) (any, error) {
+		return map[string]*Amount{"at": amount(opts.BlockNumber)}, nil
+	})
+
+	w := get(t, s, "/test/1")
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "miss" || w.Header().Get("X-Block-Number") != "100" {
+		t.Fatalf("first request: %d %v", w.Code, w.Header())
+	}
+	var env struct {
+		Block uint64
+		Data  map[string]string
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Block != 100 || env.Data["at"] != "100" {
+		t.Fatalf("envelope: %s", w.Body)
+	}
+
+	if w := get(t, s, "/test/1?block=latest"); w.Header().Get("X-Cache") != "hit" {
+		t.Fatal("same block was not served from cache")
+	}
+	if w := get(t, s, "/test/1?block=50"); w.Header().Get("X-Block-Number") != "50" || w.Header().Get("X-Cache") != "miss" {
+		t.Fatalf("historical block: %v", w.Header())
+	}
+	if *calls != 2 {
+		t.Fatalf("handler calls = %d, want 2", *calls)
+	}
+	s.policy = finality.Policy{Confirmations: 12}
+	if w := get(t, s, "/test/1?block=final"); w.Header().Get("X-Block-Number") != "88" {
+		t.Fatalf("final block: %v", w.Header())
+	}
+
+	headers.head = 101
+	if w := get(t, s, "/test/1"); w.Header().Get("X-Block-Number") != "101" || w.Header().Get("X-Cache") != "miss" {
+		t.Fatalf("latest after new head: %v", w.Header())
+	}
+	if w := get(t, s, "/test/1?block=50"); w.Header().Get("X-Cache") != "hit" {
+		t.Fatal("unchanged historical block was not served from cache")
+	}
+
+	// A reorg replaces blocks at the same heights.
+	headers.fork = 1
+	for _, path := range []string{"/test/1", "/test/1?block=50"} {
+		if w := get(t, s, path); w.Header().Get("X-Cache") != "miss" {
+			t.Errorf("%s served from cache after a reorg", path)
+		}
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCache(2)
+	a, b, d := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	c.put(a, "/x", []byte("a"))
+	c.put(b, "/x", []byte("b"))
+	c.get(a, "/x")
+	c.put(d, "/x", []byte("d"))
+	if _, ok := c.get(b, "/x"); ok {
+		t.Error("least recently used entry kept")
+	}
+	for _, h := range []common.Hash{a, d} {
+		if _, ok := c.get(h, "/x"); !ok {
+			t.Errorf("entry %s evicted", h)
+		}
+	}
+	if c.order.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("%d entries, want 2", len(c.entries))
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: x", errBadRequest), http.StatusBadRequest},
+		{fmt.Errorf("%w: 9", query.ErrPoolNotFound), http.StatusNotFound},
+		{query.ErrNoRouter, http.StatusServiceUnavailable},
+		{errors.New("execution reverted"), http.StatusBadGateway},
+	} {
+		s, _, _ := testServer(t, func(*bind.CallOpts, *http.Request) (any, error) { return nil, tc.err })
+		if w := get(t, s, "/test/1"); w.Code != tc.want {
+			t.Errorf("%v: status %d, want %d", tc.err, w.Code, tc.want)
+		}
+	}
+	s, _, calls := testServer(t, nil)
+	if w := get(t, s, "/test/1?block=nope"); w.Code != http.StatusBadRequest || *calls != 0 {
+		t.Fatalf("bad block tag: %d", w.Code)
+	}
+}
+
+func TestParseBlockTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"": "<nil>", "latest": "<nil>", "finalized": "-3", "safe": "-4", "17": "17", "0x11": "17",
+		"010": "10", "0X11": "17",
+	} {
+		got, err := parseBlockTag(in)
+		if err != nil || fmt.Sprint(got) != want {
+			t.Errorf("parseBlockTag(%q) = %v, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"-1", "pending", "1.5", "0b1", "0o7", "1_000", "0x", "0x-1"} {
+		if _, err := parseBlockTag(in); !errors.Is(err, errBadRequest) {
+			t.Errorf("parseBlockTag(%q) accepted", in)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	x, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	raw, err := json.Marshal(struct{ A *Amount }{amount(x)})
+	if err != nil || string(raw) != `{"A":"123456789012345678901234567890"}` {
+		t.Fatalf("marshal: %s %v", raw, err)
+	}
+	var back struct{ A *Amount }
+	if err := json.Unmarshal(raw, &back); err != nil || (*big.Int)(back.A).Cmp(x) != 0 {
+		t.Fatalf("unmarshal: %v %v", back.A, err)
+	}
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	var doc struct {
+		Paths map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(openAPI, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, rt := range (&Server{}).routes() {
+		paths = append(paths, rt.path)
+	}
+	for _, rt := range (&Server{}).indexRoutes() {
+		paths = append(paths, rt.path)
+	}
+	if len(doc.Paths) != len(paths) {
+		t.Errorf("openapi documents %d paths, server has %d routes", len(doc.Paths), len(paths))
+	}
+	for _, p := range paths {
+		if _, ok := doc.Paths[p]; !ok {
+			t.Errorf("route %s is not documented", p)
+		}
+	}
+}
+
+func TestLeaderboards(t *testing.T) {
+	s := &Server{}
+	s.init()
+	if w := get(t, s, "/leaderboards"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("without board: %d", w.Code)
+	}
+
+	acct := func(b byte) common.Address { return common.Address{19: b} }
+	store := indexer.NewStore()
+	ev := func(who byte, amount int64, block uint64) indexer.Event {
+		return indexer.Event{Kind: indexer.KindDeposited, PoolID: 1, Account: acct(who), Amount: big.NewInt(amount), Block: block}
+	}
+	if err := store.Append(42, ev(1, 10, 1), ev(2, 30, 2), ev(3, 30, 3)); err != nil {
+		t.Fatal(err)
+	}
+	s.SetLeaderboard(leaderboard.Build(store, leaderboard.Config{}))
+
+	w := get(t, s, "/leaderboards/all/pools/1/depositors?limit=2&account="+acct(1).Hex())
+	if w.Code != http.StatusOK || w.Header().Get("X-Block-Number") != "42" {
+		t.Fatalf("status %d, block %q: %s", w.Code, w.Header().Get("X-Block-Number"), w.Body)
+	}
+	var env struct {
+		Block uint64
+		Data  Leaderboard
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	got := env.Data
+	if env.Block != 42 || got.Total != 3 || got.Limit != 2 || len(got.Entries) != 2 {
+		t.Fatalf("page: %s", w.Body)
+	}
+	if got.Entries[0].Rank != 1 || got.Entries[1].Rank != 1 || got.Entries[0].Account != acct(2) {
+		t.Errorf("tied entries: %+v", got.Entries)
+	}
+	if got.Account == nil || got.Account.Rank != 3 {
+		t.Errorf("account entry: %+v", got.Account)
+	}
+
+	for path, want := range map[string]int{
+		"/leaderboards/s9/pools/1/depositors":                    http.StatusNotFound,
+		"/leaderboards/all/pools/1/depositors?limit=0":           http.StatusBadRequest,
+		"/leaderboards/all/pools/x/depositors":                   http.StatusBadRequest,
+		"/leaderboards/all/tokens/0x01/earners":                  http.StatusBadRequest,
+		"/leaderboards/all/tokens/" + acct(9).Hex() + "/earners": http.StatusOK,
+	} {
+		if w := get(t, s, path); w.Code != want {
+			t.Errorf("%s: status %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// cacheEntries bounds the number of responses kept.
+const cacheEntries = 4096
+
+// cache holds encoded responses keyed by block hash and request, so a
+// response is never served for a block that was reorged out, even at the
+// same height. It keeps at most size entries and evicts the least recently
+// used one first.
+type cache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey struct {
+	block   common.Hash
+	request string
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	body []byte
+}
+
+func newCache(size int) *cache {
+	return &cache{size: size, order: list.New(), entries: make(map[cacheKey]*list.Element)}
+}
+
+func (c *cache) get(block common.Hash, request string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[cacheKey{block, request}]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).body, true
+}
+
+func (c *cache) put(block common.Hash, request string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cacheKey{block, request}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).body = body
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key, body})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+)
+
+// Leaderboard page sizes.
+const (
+	DefaultLeaderboardLimit = 50
+	MaxLeaderboardLimit     = 500
+)
+
+var errNoLeaderboard = errors.New("leaderboards are not enabled")
+
+// indexHandler produces the data of an endpoint served from the index.
+type indexHandler func(r *http.Request) (any, error)
+
+type indexRoute struct {
+	path string
+	h    indexHandler
+}
+
+// SetLeaderboard serves b under /leaderboards. It must be called before the
+// server handles requests; without it those endpoints answer 503.
+func (s *Server) SetLeaderboard(b *leaderboard.Board) {
+	s.board = b
+}
+
+func (s *Server) indexRoutes() []indexRoute {
+	return []indexRoute{
+		{"/leaderboards", s.leaderboards},
+		{"/leaderboards/{window}/pools/{id}/depositors", s.depositors},
+		{"/leaderboards/{window}/tokens/{addr}/earners", s.earners},
+	}
+}
+
+// serveIndex answers from the leaderboard without block pinning; the
+// envelope carries the last indexed block instead.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request, h indexHandler) {
+	if s.board == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoLeaderboard)
+		return
+	}
+	head := s.board.Head()
+	data, err := h(r)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	body, err := json.Marshal(IndexEnvelope{Block: head, Data: data})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Block-Number", strconv.FormatUint(head, 10))
+	w.Write(body)
+}
+
+func (s *Server) leaderboards(*http.Request) (any, error) {
+	return LeaderboardWindows{Windows: s.board.Windows()}, nil
+}
+
+func (s *Server) depositors(r *http.Request) (any, error) {
+	id, err := poolID(r)
+	if err != nil {
+		return nil, err
+	}
+	if !id.IsUint64() {
+		return nil, fmt.Errorf("%w: invalid pool id %s", errBadRequest, id)
+	}
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		return nil, err
+	}
+	window := r.PathValue("window")
+	p, err := s.board.TopDepositors(window, id.Uint64(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := newLeaderboard(p, limit)
+	out.PoolID = amount(id)
+	out.Account, err = accountEntry(r, func(acct common.Address) (leaderboard.Entry, bool, error) {
+		return s.board.Depositor(window, id.Uint64(), acct)
+	})
+	return out, err
+}
+
+func (s *Server) earners(r *http.Request) (any, error) {
+	token, err := address(r)
+	if err != nil {
+		return nil, err
+	}
+	offset, limit, err := pageParams(r)
+	if err != nil {
+		return nil, err
+	}
+	window := r.PathValue("window")
+	p, err := s.board.TopEarners(window, token, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := newLeaderboard(p, limit)
+	out.Token = &token
+	out.Account, err = accountEntry(r, func(acct common.Address) (leaderboard.Entry, bool, error) {
+		return s.board.Earner(window, token, acct)
+	})
+	return out, err
+}
+
+// accountEntry looks up the ?account= parameter, if any.
+func accountEntry(r *http.Request, lookup func(common.Address) (leaderboard.Entry, bool, error)) (*LeaderboardEntry, error) {
+	v := strings.TrimSpace(r.URL.Query().Get("account"))
+	if v == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(v) {
+		return nil, fmt.Errorf("%w: invalid account %q", errBadRequest, v)
+	}
+	e, ok, err := lookup(common.HexToAddress(v))
+	if err != nil || !ok {
+		return nil, err
+	}
+	out := newLeaderboardEntry(e)
+	return &out, nil
+}
+
+func pageParams(r *http.Request) (offset, limit int, err error) {
+	if offset, err = intParam(r, "offset", 0); err != nil {
+		return 0, 0, err
+	}
+	if limit, err = intParam(r, "limit", DefaultLeaderboardLimit); err != nil {
+		return 0, 0, err
+	}
+	if limit < 1 || limit > MaxLeaderboardLimit {
+		return 0, 0, fmt.Errorf("%w: limit must be 1-%d", errBadRequest, MaxLeaderboardLimit)
+	}
+	return offset, limit, nil
+}
+
+func intParam(r *http.Request, name string, def int) (int, error) {
+	v := strings.TrimSpace(r.URL.Query().Get(name))
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: invalid %s %q", errBadRequest, name, v)
+	}
+	return n, nil
+}
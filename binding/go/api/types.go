@@ -0,0 +1,202 @@
+package api
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+// Amount is a big integer serialised as a decimal string, so clients with
+// float64 numbers do not lose precision.
+type Amount big.Int
+
+func amount(x *big.Int) *Amount {
+	return (*Amount)(x)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a *Amount) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, (*big.Int)(a).String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Amount) UnmarshalJSON(raw []byte) error {
+	s, err := strconv.Unquote(string(raw))
+	if err != nil {
+		return err
+	}
+	if _, ok := (*big.Int)(a).SetString(s, 10); !ok {
+		return strconv.ErrSyntax
+	}
+	return nil
+}
+
+// Envelope wraps every response with the block it was read at.
+type Envelope struct {
+	Block     uint64      `json:"block"`
+	BlockHash common.Hash `json:"blockHash"`
+	Data      any         `json:"data"`
+}
+
+// Pool is the JSON form of query.Pool.
+type Pool struct {
+	ID               *Amount          `json:"id"`
+	Address          common.Address   `json:"address"`
+	Name             string           `json:"name"`
+	DepositToken     common.Address   `json:"depositToken"`
+	CreatedAt        *Amount          `json:"createdAt"`
+	Status           string           `json:"status"`
+	Paused           bool             `json:"paused"`
+	TotalDeposited   *Amount          `json:"totalDeposited"`
+	MinDepositAmount *Amount          `json:"minDepositAmount"`
+	RewardTokens     []common.Address `json:"rewardTokens"`
+	RemovedTokens    []common.Address `json:"removedRewardTokens"`
+}
+
+// RewardToken is the JSON form of query.RewardToken.
+type RewardToken struct {
+	Token                common.Address `json:"token"`
+	Removed              bool           `json:"removed"`
+	RewardPerTokenStored *Amount        `json:"rewardPerTokenStored,omitempty"`
+	LastBalance          *Amount        `json:"lastBalance,omitempty"`
+	DistributedAmount    *Amount        `json:"distributedAmount,omitempty"`
+	ReclaimableAmount    *Amount        `json:"reclaimableAmount"`
+}
+
+// Reward is the JSON form of query.Reward.
+type Reward struct {
+	Token   common.Address `json:"token"`
+	Amount  *Amount        `json:"amount"`
+	Removed bool           `json:"removed"`
+}
+
+// Position is the JSON form of query.Position.
+type Position struct {
+	PoolID       *Amount        `json:"poolId"`
+	Pool         common.Address `json:"pool"`
+	DepositToken common.Address `json:"depositToken"`
+	Deposited    *Amount        `json:"deposited"`
+	Pending      []Reward       `json:"pending"`
+}
+
+// PendingReward is one claimable amount in the /users/{addr}/pending list.
+type PendingReward struct {
+	PoolID *Amount `json:"poolId"`
+	Reward
+}
+
+// TotalDeposited is the /tokens/{addr}/total-deposited response.
+type TotalDeposited struct {
+	Token          common.Address `json:"token"`
+	TotalDeposited *Amount        `json:"totalDeposited"`
+}
+
+// IndexEnvelope wraps responses served from the local index with the last
+// indexed block they reflect.
+type IndexEnvelope struct {
+	Block uint64 `json:"block"`
+	Data  any    `json:"data"`
+}
+
+// LeaderboardWindows is the /leaderboards response.
+type LeaderboardWindows struct {
+	Windows []leaderboard.Window `json:"windows"`
+}
+
+// LeaderboardEntry is the JSON form of leaderboard.Entry.
+type LeaderboardEntry struct {
+	Rank    int            `json:"rank"`
+	Account common.Address `json:"account"`
+	Score   *Amount        `json:"score"`
+}
+
+// Leaderboard is one page of a depositors or earners leaderboard. Account
+// is the entry of the ?account= parameter, if it is ranked.
+type Leaderboard struct {
+	Window  leaderboard.Window `json:"window"`
+	Kind    leaderboard.Kind   `json:"kind"`
+	PoolID  *Amount            `json:"poolId,omitempty"`
+	Token   *common.Address    `json:"token,omitempty"`
+	Total   int                `json:"total"`
+	Offset  int                `json:"offset"`
+	Limit   int                `json:"limit"`
+	Entries []LeaderboardEntry `json:"entries"`
+	Account *LeaderboardEntry  `json:"account,omitempty"`
+}
+
+func newPool(p *query.Pool) Pool {
+	return Pool{
+		ID:               amount(p.ID),
+		Address:          p.Address,
+		Name:             p.Name,
+		DepositToken:     p.DepositToken,
+		CreatedAt:        amount(p.CreatedAt),
+		Status:           config.PoolStatus(p.Status).String(),
+		Paused:           p.Paused,
+		TotalDeposited:   amount(p.TotalDeposited),
+		MinDepositAmount: amount(p.MinDepositAmount),
+		RewardTokens:     nonNil(p.RewardTokens),
+		RemovedTokens:    nonNil(p.RemovedTokens),
+	}
+}
+
+func newRewardToken(t query.RewardToken) RewardToken {
+	return RewardToken{
+		Token:                t.Token,
+		Removed:              t.Removed,
+		RewardPerTokenStored: amount(t.RewardPerTokenStored),
+		LastBalance:          amount(t.LastBalance),
+		DistributedAmount:    amount(t.DistributedAmount),
+		ReclaimableAmount:    amount(t.ReclaimableAmount),
+	}
+}
+
+func newReward(r query.Reward) Reward {
+	return Reward{Token: r.Token, Amount: amount(r.Amount), Removed: r.Removed}
+}
+
+func newPosition(p *query.Position) Position {
+	out := Position{
+		PoolID:       amount(p.PoolID),
+		Pool:         p.Pool,
+		DepositToken: p.DepositToken,
+		Deposited:    amount(p.Deposited),
+		Pending:      make([]Reward, 0, len(p.Pending)),
+	}
+	for _, r := range p.Pending {
+		out.Pending = append(out.Pending, newReward(r))
+	}
+	return out
+}
+
+func newLeaderboardEntry(e leaderboard.Entry) LeaderboardEntry {
+	return LeaderboardEntry{Rank: e.Rank, Account: e.Account, Score: amount(e.Score)}
+}
+
+func newLeaderboard(p leaderboard.Page, limit int) Leaderboard {
+	out := Leaderboard{
+		Window:  p.Window,
+		Kind:    p.Kind,
+		Total:   p.Total,
+		Offset:  p.Offset,
+		Limit:   limit,
+		Entries: make([]LeaderboardEntry, 0, len(p.Entries)),
+	}
+	for _, e := range p.Entries {
+		out.Entries = append(out.Entries, newLeaderboardEntry(e))
+	}
+	return out
+}
+
+// nonNil keeps empty lists as [] rather than null in responses.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
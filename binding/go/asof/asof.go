@@ -0,0 +1,224 @@
+// Package asof runs read queries as of a past block or timestamp.
+//
+// A Point names a moment either by block number or by time; times are
+// resolved to the block whose state was current then (see Resolver). Every
+// query returns the block it ran at along with that block's time, so answers
+// such as "rewards at the end of season 3" can be reproduced.
+//
+// Contract reads at old blocks need an archive node. Where the node has
+// pruned the state, deposits can still be replayed from a local index
+// built by package indexer.
+package asof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+var (
+	// ErrStateUnavailable is returned when the node no longer holds the
+	// state of the requested block.
+	ErrStateUnavailable = errors.New("asof: historical state unavailable (archive node or index replay required)")
+	// ErrIndexBehind is returned for index replays past the index head.
+	ErrIndexBehind = errors.New("asof: index does not reach block")
+)
+
+// Point is a moment in chain history: a block, a time, or the head when
+// both are zero.
+type Point struct {
+	Block uint64
+	Time  time.Time
+}
+
+// ParsePoint parses "latest" or "" (the head), a block number from 1 in
+// decimal or 0x hex, an RFC 3339 time, a date (midnight UTC), or "@"
+// followed by Unix seconds.
+func ParsePoint(s string) (Point, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "" || s == "latest":
+		return Point{}, nil
+	case strings.HasPrefix(s, "@"):
+		sec, err := strconv.ParseInt(s[1:], 10, 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("asof: invalid unix time %q", s)
+		}
+		return Point{Time: time.Unix(sec, 0).UTC()}, nil
+	}
+	digits, base := s, 10
+	if h, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		digits, base = h, 16
+	}
+	if n, ok := new(big.Int).SetString(digits, base); ok {
+		if n.Sign() < 0 || !n.IsUint64() {
+			return Point{}, fmt.Errorf("asof: invalid block %q", s)
+		}
+		if n.Sign() == 0 {
+			// A zero Block means the head, so genesis cannot be named.
+			return Point{}, errors.New("asof: block 0 is not supported")
+		}
+		return Point{Block: n.Uint64()}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Point{Time: t}, nil
+		}
+	}
+	return Point{}, fmt.Errorf("asof: %q is neither a block nor a time", s)
+}
+
+func (p Point) String() string {
+	switch {
+	case !p.Time.IsZero():
+		return p.Time.UTC().Format(time.RFC3339)
+	case p.Block != 0:
+		return strconv.FormatUint(p.Block, 10)
+	}
+	return "latest"
+}
+
+// Result is the answer of a query together with the block it was read at.
+type Result[T any] struct {
+	Block uint64    `json:"block"`
+	Time  time.Time `json:"time"`
+	Data  T         `json:"data"`
+}
+
+// Reader runs queries as of a Point.
+type Reader struct {
+	net      *config.Network
+	query    *query.Reader
+	resolver *Resolver
+}
+
+// New returns a Reader for net.
+func New(net *config.Network) *Reader {
+	return &Reader{net: net, query: query.New(net), resolver: NewResolver(net.Client)}
+}
+
+// Resolver returns the Reader's timestamp resolver.
+func (r *Reader) Resolver() *Resolver {
+	return r.resolver
+}
+
+// Block resolves p to a block and returns it with its time.
+func (r *Reader) Block(ctx context.Context, p Point) (uint64, time.Time, error) {
+	var (
+		block uint64
+		err   error
+	)
+	switch {
+	case !p.Time.IsZero():
+		block, err = r.resolver.BlockAt(ctx, p.Time)
+	case p.Block != 0:
+		block = p.Block
+	default:
+		block, _, err = r.resolver.Head(ctx)
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	t, err := r.resolver.Time(ctx, block)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return block, time.Unix(int64(t), 0).UTC(), nil
+}
+
+// Do runs f with reads pinned to the block of p.
+func Do[T any](ctx context.Context, r *Reader, p Point, f func(opts *bind.CallOpts) (T, error)) (*Result[T], error) {
+	block, t, err := r.Block(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	data, err := f(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)})
+	if err != nil {
+		if missingState(err) {
+			return nil, fmt.Errorf("%w: block %d: %v", ErrStateUnavailable, block, err)
+		}
+		return nil, err
+	}
+	return &Result[T]{Block: block, Time: t, Data: data}, nil
+}
+
+// missingState recognises the errors nodes return for pruned state.
+func missingState(err error) bool {
+	msg := err.Error()
+	for _, s := range []string{"missing trie node", "historical state", "state is not available", "state not available"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Positions returns the user's non-empty positions, including pending
+// rewards, across all pools as of p.
+func (r *Reader) Positions(ctx context.Context, p Point, user common.Address) (*Result[[]*query.Position], error) {
+	return Do(ctx, r, p, func(opts *bind.CallOpts) ([]*query.Position, error) {
+		return r.query.Positions(opts, user)
+	})
+}
+
+// Position returns the user's position in one pool as of p.
+func (r *Reader) Position(ctx context.Context, p Point, id *big.Int, user common.Address) (*Result[*query.Position], error) {
+	return Do(ctx, r, p, func(opts *bind.CallOpts) (*query.Position, error) {
+		return r.query.Position(opts, id, user)
+	})
+}
+
+// Pending returns the user's claimable rewards in one pool as of p.
+func (r *Reader) Pending(ctx context.Context, p Point, id *big.Int, user common.Address) (*Result[[]query.Reward], error) {
+	return Do(ctx, r, p, func(opts *bind.CallOpts) ([]query.Reward, error) {
+		pos, err := r.query.Position(opts, id, user)
+		if err != nil {
+			return nil, err
+		}
+		return pos.Pending, nil
+	})
+}
+
+// Pool returns a snapshot of one pool as of p.
+func (r *Reader) Pool(ctx context.Context, p Point, id *big.Int) (*Result[*query.Pool], error) {
+	return Do(ctx, r, p, func(opts *bind.CallOpts) (*query.Pool, error) {
+		return r.query.Pool(opts, id)
+	})
+}
+
+// Pools returns a snapshot of every pool, or only the active ones, as of p.
+func (r *Reader) Pools(ctx context.Context, p Point, activeOnly bool) (*Result[[]*query.Pool], error) {
+	return Do(ctx, r, p, func(opts *bind.CallOpts) ([]*query.Pool, error) {
+		return r.query.Pools(opts, activeOnly)
+	})
+}
+
+// IndexedDeposits replays the user's deposited balance per pool ID as of p
+// from store, without reading historical state from the node.
+func (r *Reader) IndexedDeposits(ctx context.Context, p Point, store *indexer.Store, user common.Address) (*Result[map[uint64]*big.Int], error) {
+	block, t, err := r.Block(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if block > store.Head() {
+		return nil, fmt.Errorf("%w %d (index head %d)", ErrIndexBehind, block, store.Head())
+	}
+	out := make(map[uint64]*big.Int)
+	for addr, id := range store.Pools() {
+		if bal, ok := store.Balances(addr, block)[user]; ok {
+			out[id] = bal
+		}
+	}
+	return &Result[map[uint64]*big.Int]{Block: block, Time: t, Data: out}, nil
+}
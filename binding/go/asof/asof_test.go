@@ -0,0 +1,112 @@
+package asof
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// chain has a block every 12 seconds from time 1000, except that blocks 50
+// and 51 share a timestamp.
+type chain struct {
+	head  uint64
+	calls int
+}
+
+func blockTime(n uint64) uint64 {
+	if n >= 51 {
+		n--
+	}
+	return 1000 + 12*n
+}
+
+func (c *chain) HeaderByNumber(_ context.Context, n *big.Int) (*types.Header, error) {
+	c.calls++
+	b := c.head
+	if n != nil {
+		b = n.Uint64()
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(b), Time: blockTime(b)}, nil
+}
+
+func unix(sec uint64) time.Time { return time.Unix(int64(sec), 0) }
+
+func TestBlockAt(t *testing.T) {
+	c := &chain{head: 1000}
+	r := NewResolver(c)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		ts   uint64
+		want uint64
+	}{
+		{1000, 0},
+		{1011, 0},
+		{1012, 1},
+		{blockTime(50), 51}, // the last of two blocks with the same time
+		{blockTime(700) + 5, 700},
+		{blockTime(1000), 1000},
+		{blockTime(1000) + 3600, 1000},
+	} {
+		got, err := r.BlockAt(ctx, unix(tc.ts))
+		if err != nil || got != tc.want {
+			t.Errorf("BlockAt(%d) = %d, %v; want %d", tc.ts, got, err, tc.want)
+		}
+	}
+	if _, err := r.BlockAt(ctx, unix(999)); !errors.Is(err, ErrBeforeGenesis) {
+		t.Errorf("before genesis: %v", err)
+	}
+
+	// Cached times narrow the next search to a handful of headers.
+	c.calls = 0
+	if got, _ := r.BlockAt(ctx, unix(blockTime(701)+1)); got != 701 {
+		t.Fatalf("BlockAt = %d, want 701", got)
+	}
+	if c.calls > 4 {
+		t.Errorf("cached search read %d headers", c.calls)
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	for in, want := range map[string]Point{
+		"":                     {},
+		"latest":               {},
+		"123":                  {Block: 123},
+		"0x10":                 {Block: 16},
+		"010":                  {Block: 10},
+		"@1700000000":          {Time: time.Unix(1700000000, 0).UTC()},
+		"2024-03-31":           {Time: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		"2024-03-31T23:59:59Z": {Time: time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)},
+	} {
+		got, err := ParsePoint(in)
+		if err != nil || got.Block != want.Block || !got.Time.Equal(want.Time) {
+			t.Errorf("ParsePoint(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"-1", "yesterday", "@x", "0b101", "0o17", "0x", "0", "0x0"} {
+		if _, err := ParsePoint(in); err == nil {
+			t.Errorf("ParsePoint(%q) accepted", in)
+		}
+	}
+}
+
+func TestDoReportsBlockAndPrunedState(t *testing.T) {
+	r := &Reader{resolver: NewResolver(&chain{head: 100})}
+	ctx := context.Background()
+	res, err := Do(ctx, r, Point{Time: unix(blockTime(40) + 1)}, func(opts *bind.CallOpts) (uint64, error) {
+		return opts.BlockNumber.Uint64(), nil
+	})
+	if err != nil || res.Block != 40 || res.Data != 40 || res.Time.Unix() != int64(blockTime(40)) {
+		t.Fatalf("Do = %+v, %v", res, err)
+	}
+	_, err = Do(ctx, r, Point{Block: 3}, func(*bind.CallOpts) (int, error) {
+		return 0, errors.New("missing trie node 4f3e (path )")
+	})
+	if !errors.Is(err, ErrStateUnavailable) {
+		t.Fatalf("pruned state: %v", err)
+	}
+}
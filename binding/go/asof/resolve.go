@@ -0,0 +1,133 @@
+package asof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrBeforeGenesis is returned for times earlier than the genesis block.
+var ErrBeforeGenesis = errors.New("asof: time is before genesis")
+
+// timeCacheSize bounds the number of block times a Resolver keeps.
+const timeCacheSize = 1 << 16
+
+// HeaderReader is the node API a Resolver needs; *ethclient.Client
+// satisfies it.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Resolver maps timestamps to blocks by binary search over block headers.
+// Block times it has read are cached and narrow later searches, so
+// resolving nearby times costs few requests. It is safe for concurrent use.
+type Resolver struct {
+	headers HeaderReader
+
+	mu    sync.Mutex
+	times map[uint64]uint64
+}
+
+// NewResolver returns a Resolver reading headers from h.
+func NewResolver(h HeaderReader) *Resolver {
+	return &Resolver{headers: h, times: make(map[uint64]uint64)}
+}
+
+// Head returns the current head block and its time.
+func (r *Resolver) Head(ctx context.Context) (uint64, uint64, error) {
+	h, err := r.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("asof: head: %w", err)
+	}
+	n := h.Number.Uint64()
+	r.remember(n, h.Time)
+	return n, h.Time, nil
+}
+
+// Time returns the timestamp of block.
+func (r *Resolver) Time(ctx context.Context, block uint64) (uint64, error) {
+	r.mu.Lock()
+	t, ok := r.times[block]
+	r.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+	h, err := r.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, fmt.Errorf("asof: header %d: %w", block, err)
+	}
+	r.remember(block, h.Time)
+	return h.Time, nil
+}
+
+func (r *Resolver) remember(block, t uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.times) >= timeCacheSize {
+		clear(r.times)
+	}
+	r.times[block] = t
+}
+
+// BlockAt returns the last block whose timestamp is not after t: the block
+// whose state was current at t. Times after the head resolve to the head.
+func (r *Resolver) BlockAt(ctx context.Context, t time.Time) (uint64, error) {
+	if t.Unix() < 0 {
+		return 0, ErrBeforeGenesis
+	}
+	ts := uint64(t.Unix())
+	head, headTime, err := r.Head(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ts >= headTime {
+		return head, nil
+	}
+	genesis, err := r.Time(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	if ts < genesis {
+		return 0, fmt.Errorf("%w: %s", ErrBeforeGenesis, t.UTC().Format(time.RFC3339))
+	}
+
+	// Invariant: time(lo) <= ts < time(hi).
+	lo, hi := r.bounds(ts, head)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		mt, err := r.Time(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if mt <= ts {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// bounds narrows [0, head] with the cached block times around ts.
+func (r *Resolver) bounds(ts, head uint64) (lo, hi uint64) {
+	hi = head
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for b, t := range r.times {
+		if t <= ts {
+			lo = max(lo, b)
+		} else {
+			hi = min(hi, b)
+		}
+	}
+	if lo >= hi {
+		// Times cached before a reorg disagree; search everything.
+		return 0, head
+	}
+	return lo, hi
+}
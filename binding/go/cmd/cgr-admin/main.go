@@ -0,0 +1,154 @@
+// Command cgr-admin creates pools and adds reward tokens after probing the
+// token for plain ERC-20 behaviour.
+//
+//	cgr-admin probe       -token 0x.. [-holder 0x..] [-amount <units>] [-block N]
+//	cgr-admin create-pool -name "Pool" -token 0x.. -min <units> [-allow-warnings]
+//	cgr-admin add-reward  -pool 1 -token 0x.. [-allow-warnings]
+//
+// probe prints the report as JSON and exits 1 unless the token is
+// compatible. create-pool and add-reward refuse tokens that fail a check,
+// and tokens with warnings unless -allow-warnings is given; they sign with
+// CGR_PRIVATE_KEY. The probe needs eth_simulateV1; against a node without it,
+// point -rpc at a local fork. Tokens whose balance slot cannot be found are
+// funded from -holder instead.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/probe"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-admin probe|create-pool|add-reward [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		token         = fs.String("token", "", "deposit or reward token address")
+		holder        = fs.String("holder", "", "account funding the probe instead of a storage override")
+		amount        = fs.String("amount", "", "base units moved by the probe (default 1000 tokens)")
+		block         = fs.Int64("block", -1, "probe block (default head)")
+		allowWarnings = fs.Bool("allow-warnings", false, "accept tokens whose probe has only warnings")
+		name          = fs.String("name", "", "create-pool: pool name")
+		minDeposit    = fs.String("min", "", "create-pool: minimum deposit in base units (required, positive)")
+		poolID        = fs.Uint64("pool", 0, "add-reward: pool ID")
+	)
+	fs.Parse(os.Args[2:])
+
+	if !common.IsHexAddress(*token) {
+		log.Fatalf("invalid -token %q", *token)
+	}
+	opts := probe.Options{}
+	if *holder != "" {
+		if !common.IsHexAddress(*holder) {
+			log.Fatalf("invalid -holder %q", *holder)
+		}
+		opts.Holder = common.HexToAddress(*holder)
+	}
+	if *amount != "" {
+		v, ok := new(big.Int).SetString(*amount, 10)
+		if !ok || v.Sign() <= 0 {
+			log.Fatalf("invalid -amount %q", *amount)
+		}
+		opts.Amount = v
+	}
+	if *block >= 0 {
+		opts.Block = big.NewInt(*block)
+	}
+	var send func(o *bind.TransactOpts, net *config.Network) (*types.Transaction, error)
+	switch cmd {
+	case "probe":
+	case "create-pool":
+		if *name == "" {
+			log.Fatal("-name is required")
+		}
+		minAmount, ok := new(big.Int).SetString(*minDeposit, 10)
+		if !ok || minAmount.Sign() <= 0 {
+			log.Fatalf("-min must be a positive amount, got %q", *minDeposit)
+		}
+		send = func(o *bind.TransactOpts, net *config.Network) (*types.Transaction, error) {
+			return net.Factory.CreatePool(o, *name, common.HexToAddress(*token), minAmount)
+		}
+	case "add-reward":
+		if *poolID == 0 {
+			log.Fatal("-pool is required")
+		}
+		send = func(o *bind.TransactOpts, net *config.Network) (*types.Transaction, error) {
+			return net.Factory.AddRewardToken(o, new(big.Int).SetUint64(*poolID), common.HexToAddress(*token))
+		}
+	default:
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	r, err := probe.New(net).Probe(ctx, common.HexToAddress(*token), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if send == nil {
+		printJSON(r)
+		if r.Verdict != probe.Compatible {
+			os.Exit(1)
+		}
+		return
+	}
+	for _, is := range r.Issues {
+		log.Printf("probe %s: %s: %s", is.Severity, is.Check, is.Message)
+	}
+	if err := probe.Require(r, *allowWarnings); err != nil {
+		log.Fatal(err)
+	}
+
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return send(o, net)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s: mined in block %d", receipt.TxHash.Hex(), receipt.BlockNumber)
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
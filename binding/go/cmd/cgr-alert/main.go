@@ -0,0 +1,45 @@
+// Command cgr-alert watches governance events and delivers alerts matching
+// the rules in -rules. The network's RPC endpoint must support subscriptions.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+func main() {
+	cfg := config.RegisterFlags(flag.CommandLine)
+	rules := flag.String("rules", "alerts.json", "alert rules and sinks (see alert/rules.example.json)")
+	flag.Parse()
+
+	ac, err := alert.LoadConfig(*rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	d, err := alert.New(net, ac)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d.OnError = func(err error) { log.Print(err) }
+	log.Printf("watching %s with %d rule(s)", net.Profile.Name, len(ac.Rules))
+	if err := d.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
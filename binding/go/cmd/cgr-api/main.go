@@ -0,0 +1,97 @@
+// Command cgr-api serves protocol state and user positions over HTTP/JSON.
+// The endpoints are described at /openapi.json.
+//
+// With -index the command also keeps the indexer store at that path synced
+// and serves leaderboards built from it under /leaderboards; -seasons names
+// a JSON file of season windows ({"seasons": [{"name", "start", "end"}]})
+// and -weekly adds a board per ISO week.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/api"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+)
+
+func main() {
+	cfg := config.RegisterFlags(flag.CommandLine)
+	var (
+		listen   = flag.String("listen", ":8080", "HTTP listen address")
+		index    = flag.String("index", "", "indexer store to serve leaderboards from")
+		seasons  = flag.String("seasons", "", "leaderboard seasons JSON file")
+		weekly   = flag.Bool("weekly", false, "keep a leaderboard per ISO week")
+		interval = flag.Duration("sync", 15*time.Second, "index sync interval")
+	)
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	handler := api.New(net)
+	if *index != "" {
+		board, err := runLeaderboard(ctx, net, *index, *seasons, *weekly, *interval)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler.SetLeaderboard(board)
+	}
+
+	srv := &http.Server{Addr: *listen, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	log.Printf("serving %s API on %s", net.Profile.Name, *listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// runLeaderboard builds a board from the store at path and keeps both
+// synced in the background until ctx is done.
+func runLeaderboard(ctx context.Context, net *config.Network, path, seasons string, weekly bool, interval time.Duration) (*leaderboard.Board, error) {
+	cfg := leaderboard.Config{Weekly: weekly}
+	if seasons != "" {
+		raw, err := os.ReadFile(seasons)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", seasons, err)
+		}
+		cfg.Weekly = cfg.Weekly || weekly
+	}
+	store, err := indexer.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	board := leaderboard.Build(store, cfg)
+	go indexer.New(net, store).Run(ctx, interval, func(uint64) error {
+		board.Sync(store)
+		if err := store.Save(path); err != nil {
+			log.Printf("save index: %v", err)
+		}
+		return nil
+	}, func(err error) { log.Printf("leaderboard index sync: %v", err) })
+	return board, nil
+}
@@ -0,0 +1,114 @@
+// Command cgr-asof answers queries as of a past block or time and prints the
+// result, with the block it was read at, as JSON.
+//
+//	cgr-asof block     -at 2024-03-31T23:59:59Z
+//	cgr-asof positions -at 2024-03-31T23:59:59Z -user 0x..
+//	cgr-asof pending   -at @1711929599 -pool 1 -user 0x..
+//	cgr-asof pool      -at 18000000 -pool 1
+//	cgr-asof pools     -at 2024-04-01 [-active]
+//	cgr-asof deposits  -at 2024-04-01 -user 0x.. -index index.json
+//
+// -at takes a block number, an RFC 3339 time, a date (midnight UTC) or
+// @unix seconds; times resolve to the last block mined at or before them.
+// Contract reads need an archive node; deposits are replayed from the local
+// index instead.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/asof"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-asof block|positions|pending|pool|pools|deposits [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		at     = fs.String("at", "latest", "block number, RFC 3339 time, date or @unix")
+		user   = fs.String("user", "", "user address")
+		poolID = fs.Uint64("pool", 0, "pool ID")
+		active = fs.Bool("active", false, "only active pools")
+		index  = fs.String("index", "index.json", "indexer store for deposits")
+	)
+	fs.Parse(os.Args[2:])
+
+	point, err := asof.ParsePoint(*at)
+	if err != nil {
+		log.Fatal(err)
+	}
+	userAddr := func() common.Address {
+		if !common.IsHexAddress(*user) {
+			log.Fatal("-user is required")
+		}
+		return common.HexToAddress(*user)
+	}
+	pool := func() *big.Int {
+		if *poolID == 0 {
+			log.Fatal("-pool is required")
+		}
+		return new(big.Int).SetUint64(*poolID)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	r := asof.New(net)
+
+	var out any
+	switch cmd {
+	case "block":
+		block, t, err := r.Block(ctx, point)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out = asof.Result[any]{Block: block, Time: t}
+	case "positions":
+		out, err = r.Positions(ctx, point, userAddr())
+	case "pending":
+		out, err = r.Pending(ctx, point, pool(), userAddr())
+	case "pool":
+		out, err = r.Pool(ctx, point, pool())
+	case "pools":
+		out, err = r.Pools(ctx, point, *active)
+	case "deposits":
+		var store *indexer.Store
+		if store, err = indexer.SyncFile(ctx, net, *index); err == nil {
+			out, err = r.IndexedDeposits(ctx, point, store, userAddr())
+		}
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,107 @@
+// Command cgr-desired reconciles the protocol with a desired-state file.
+//
+//	cgr-desired plan  -file protocol.json
+//	cgr-desired apply -file protocol.json [-allow-destructive] [-allow-warnings] [-auto-approve]
+//
+// plan prints the factory calls that would make the chain match the file,
+// marking destructive ones, and exits 2 when there are changes, 1 on
+// conflicts and 0 when the chain already matches. apply prints the same
+// plan, asks for confirmation unless -auto-approve is given, and sends the
+// calls signed with CGR_PRIVATE_KEY. It refuses plans with destructive
+// changes unless -allow-destructive is given, and new tokens whose probe
+// has warnings unless -allow-warnings is given.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/desired"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-desired plan|apply -file protocol.json [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		file             = fs.String("file", "", "desired-state file")
+		allowDestructive = fs.Bool("allow-destructive", false, "apply: send destructive changes")
+		allowWarnings    = fs.Bool("allow-warnings", false, "apply: accept new tokens whose probe has only warnings")
+		autoApprove      = fs.Bool("auto-approve", false, "apply: skip the confirmation prompt")
+	)
+	fs.Parse(os.Args[2:])
+	if (cmd != "plan" && cmd != "apply") || *file == "" {
+		usage()
+	}
+	want, err := desired.Load(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	r := desired.New(net)
+	plan, err := r.Plan(ctx, want)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(plan)
+	switch {
+	case len(plan.Conflicts) > 0:
+		os.Exit(1)
+	case len(plan.Changes) == 0:
+		return
+	case cmd == "plan":
+		os.Exit(2)
+	}
+
+	if plan.Destructive() && !*allowDestructive {
+		log.Fatal("the plan has destructive changes; rerun with -allow-destructive to apply them")
+	}
+	if !*autoApprove && !confirm(len(plan.Changes)) {
+		log.Fatal("apply cancelled")
+	}
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := desired.Options{AllowDestructive: *allowDestructive, AllowWarnings: *allowWarnings}
+	_, err = r.Apply(ctx, tm, want, plan, opts, func(done, total int, a desired.Applied) {
+		log.Printf("[%d/%d] %s: %s mined in block %d", done, total, a.Call(), a.TxHash.Hex(), a.Block)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func confirm(n int) bool {
+	fmt.Fprintf(os.Stderr, "Send %d transaction(s)? Only 'yes' is accepted: ", n)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line) == "yes"
+}
@@ -0,0 +1,123 @@
+// Command cgr-emergency pauses every pool at once and later restores them.
+//
+//	cgr-emergency pause  [-record pause.json] [-fee-multiplier 3]
+//	cgr-emergency resume [-record pause.json]
+//	cgr-emergency status [-record pause.json]
+//
+// pause saves each pool's current status to -record, then sends
+// setPoolStatus(Paused) for every pool not yet paused: signed up front with
+// consecutive nonces, fees at -fee-multiplier times the suggestion, and
+// broadcast together. resume restores the saved statuses. Both print one
+// JSON result per pool, confirm each pool's status on chain, exit 1 unless
+// every pool is confirmed, and can simply be run again. status prints the
+// record. Transactions are signed with CGR_PRIVATE_KEY, which must hold
+// MANAGER_ROLE.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/emergency"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// result is emergency.Result with its error as text.
+type result struct {
+	emergency.Result
+	Err string `json:",omitempty"`
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-emergency pause|resume|status [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		record = fs.String("record", "pause.json", "file holding the statuses from before the pause")
+		mult   = fs.Int64("fee-multiplier", emergency.DefaultFeeMultiplier, "scale of the suggested tip and base fee")
+	)
+	fs.Parse(os.Args[2:])
+	switch cmd {
+	case "pause", "resume":
+	case "status":
+		rec, err := emergency.LoadRecord(*record)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if rec == nil {
+			log.Fatalf("no record at %s", *record)
+		}
+		printJSON(rec)
+		return
+	default:
+		usage()
+	}
+	if *mult < 1 {
+		log.Fatalf("invalid -fee-multiplier %d", *mult)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	e := emergency.New(net, tm, *record)
+	e.FeeMultiplier = *mult
+
+	var results []emergency.Result
+	if cmd == "pause" {
+		results, err = e.PauseAll(ctx)
+	} else {
+		results, err = e.ResumeAll(ctx)
+	}
+	out := make([]result, len(results))
+	failed := false
+	for i, r := range results {
+		out[i].Result = r
+		if r.Err != nil {
+			out[i].Err = r.Err.Error()
+		}
+		failed = failed || !r.Confirmed
+	}
+	printJSON(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		log.Printf("not every pool is confirmed; run %s again", cmd)
+		os.Exit(1)
+	}
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,54 @@
+// Command cgr-grpc serves the cgr.v1.RewardService gRPC API. Server
+// reflection is enabled so tools like grpcurl can discover the service.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	stdnet "net"
+	"os"
+	"os/signal"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/grpcapi"
+	"github.com/to-nexus/cross-game-reward/binding/go/grpcapi/cgrv1"
+)
+
+func main() {
+	cfg := config.RegisterFlags(flag.CommandLine)
+	listen := flag.String("listen", ":9090", "gRPC listen address")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	svc, err := grpcapi.New(net)
+	if err != nil {
+		log.Fatal(err)
+	}
+	lis, err := stdnet.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	cgrv1.RegisterRewardServiceServer(srv, svc)
+	reflection.Register(srv)
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	log.Printf("serving %s gRPC API on %s", net.Profile.Name, *listen)
+	if err := srv.Serve(lis); err != nil {
+		log.Fatal(err)
+	}
+}
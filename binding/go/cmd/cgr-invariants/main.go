@@ -0,0 +1,82 @@
+// Command cgr-invariants checks protocol accounting invariants at a block and
+// prints violations as JSON lines.
+//
+// Exit codes: 0 no violations, 1 violations found, 2 the check could not run.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/invariant"
+)
+
+const (
+	exitOK        = 0
+	exitViolation = 1
+	exitError     = 2
+)
+
+func main() {
+	log.SetFlags(0)
+	cfg := config.RegisterFlags(flag.CommandLine)
+	var (
+		index = flag.String("index", "index.json", "indexer store used to find depositors")
+		state = flag.String("state", "invariants.state.json", "accumulator state carried between runs")
+		block = flag.Uint64("block", 0, "block to check, at most the index head (default the index head)")
+	)
+	flag.Parse()
+	os.Exit(run(cfg, *index, *state, *block))
+}
+
+func run(cfg *config.Flags, indexPath, statePath string, block uint64) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	defer net.Close()
+
+	store, err := indexer.SyncFile(ctx, net, indexPath)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	if block == 0 {
+		block = store.Head()
+	}
+	prev, err := invariant.LoadState(statePath)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	findings, next, err := invariant.New(net, store).Run(ctx, block, prev)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	if err := next.Save(statePath); err != nil {
+		log.Print(err)
+		return exitError
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, f := range findings {
+		enc.Encode(f)
+	}
+	if len(findings) > 0 {
+		log.Printf("block %d: %d violation(s)", block, len(findings))
+		return exitViolation
+	}
+	log.Printf("block %d: all invariants hold", block)
+	return exitOK
+}
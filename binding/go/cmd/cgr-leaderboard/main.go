@@ -0,0 +1,113 @@
+// Command cgr-leaderboard prints leaderboards built from the local index and
+// checks them against the pools.
+//
+//	cgr-leaderboard top   -window all -pool 1 -limit 10
+//	cgr-leaderboard top   -window 2024-W13 -token 0x.. -weekly
+//	cgr-leaderboard top   -window season-3 -pool 1 -seasons seasons.json
+//	cgr-leaderboard check
+//
+// The index is synced to the final block first. check rebuilds the boards
+// from scratch and compares every all-time depositor score with
+// balances(account) at the index head; it exits 1 on a mismatch.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/leaderboard"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-leaderboard top|check [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		index   = fs.String("index", "index.json", "indexer store")
+		seasons = fs.String("seasons", "", "seasons JSON file")
+		weekly  = fs.Bool("weekly", false, "keep a board per ISO week")
+		window  = fs.String("window", leaderboard.AllTime, "window name")
+		poolID  = fs.Uint64("pool", 0, "depositors of this pool")
+		token   = fs.String("token", "", "earners of this reward token")
+		offset  = fs.Int("offset", 0, "entries to skip")
+		limit   = fs.Int("limit", 20, "entries to print")
+	)
+	fs.Parse(os.Args[2:])
+
+	bcfg := leaderboard.Config{Weekly: *weekly}
+	if *seasons != "" {
+		raw, err := os.ReadFile(*seasons)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, &bcfg); err != nil {
+			log.Fatalf("parse %s: %v", *seasons, err)
+		}
+		bcfg.Weekly = bcfg.Weekly || *weekly
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	store, err := indexer.SyncFile(ctx, net, *index)
+	if err != nil {
+		log.Fatal(err)
+	}
+	board := leaderboard.Build(store, bcfg)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	switch cmd {
+	case "top":
+		var page leaderboard.Page
+		switch {
+		case *poolID != 0:
+			page, err = board.TopDepositors(*window, *poolID, *offset, *limit)
+		case common.IsHexAddress(*token):
+			page, err = board.TopEarners(*window, common.HexToAddress(*token), *offset, *limit)
+		default:
+			log.Fatal("-pool or -token is required")
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc.Encode(page)
+	case "check":
+		mismatches, err := board.Check(ctx, net)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, m := range mismatches {
+			enc.Encode(m)
+		}
+		if len(mismatches) > 0 {
+			log.Printf("block %d: %d score(s) differ from balances", board.Head(), len(mismatches))
+			os.Exit(1)
+		}
+		log.Printf("block %d: depositor scores match balances", board.Head())
+	default:
+		usage()
+	}
+}
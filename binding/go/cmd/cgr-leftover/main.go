@@ -0,0 +1,111 @@
+// Command cgr-leftover checks that the router holds no funds between
+// transactions.
+//
+//	cgr-leftover scan    [-block N] [-lookback 10000]
+//	cgr-leftover monitor [-interval 15s] [-webhook URL] [-slack URL]
+//
+// scan lists every non-zero router balance of native CROSS, WCROSS, pool
+// deposit tokens and reward tokens at a block (default the final block),
+// with the transaction that left it, and exits 1 if there is any. monitor
+// scans each new final block and alerts when leftovers appear, change or
+// are cleared.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	"github.com/to-nexus/cross-game-reward/binding/go/leftover"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-leftover scan|monitor [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		block    = fs.Uint64("block", 0, "scan block (default the final block)")
+		lookback = fs.Uint64("lookback", leftover.DefaultLookback, "blocks searched for the transaction behind a leftover")
+		interval = fs.Duration("interval", leftover.DefaultInterval, "monitor poll interval")
+		webhook  = fs.String("webhook", "", "monitor webhook URL")
+		slack    = fs.String("slack", "", "monitor Slack webhook URL")
+	)
+	fs.Parse(os.Args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	scanner, err := leftover.NewScanner(net)
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy := finality.ForProfile(net.Profile)
+
+	switch cmd {
+	case "scan":
+		at := *block
+		if at == 0 {
+			if at, err = policy.FinalBlock(ctx, net.Client); err != nil {
+				log.Fatal(err)
+			}
+		}
+		tokens, err := scanner.Tokens(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(at)})
+		if err != nil {
+			log.Fatal(err)
+		}
+		found, err := scanner.Scan(ctx, at-min(at, *lookback), at, tokens)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, l := range found {
+			enc.Encode(l)
+		}
+		if len(found) > 0 {
+			log.Printf("block %d: router %s holds %d leftover balance(s)", at, scanner.Router().Hex(), len(found))
+			os.Exit(1)
+		}
+		log.Printf("block %d: router %s holds nothing across %d token(s)", at, scanner.Router().Hex(), len(tokens))
+	case "monitor":
+		sinks := []alert.Sink{&alert.Writer{W: os.Stdout}}
+		if *webhook != "" {
+			sinks = append(sinks, &alert.Webhook{URL: *webhook})
+		}
+		if *slack != "" {
+			sinks = append(sinks, &alert.Slack{URL: *slack})
+		}
+		m := leftover.NewMonitor(scanner, policy, sinks...)
+		m.Interval, m.Lookback = *interval, *lookback
+		m.OnError = func(err error) { log.Print(err) }
+		log.Printf("monitoring router %s every %s", scanner.Router().Hex(), *interval)
+		if err := m.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,224 @@
+// Command cgr-merkle builds Merkle claim distributions compatible with
+// OpenZeppelin's StandardMerkleTree and MerkleProof, and verifies them.
+//
+//	cgr-merkle build  -csv allocations.csv -out dist.json
+//	cgr-merkle build  -twab season3.json -out dist.json -dump tree.json
+//	cgr-merkle build  -balances -at 2024-04-01 -pools 1,2 -out dist.json
+//	cgr-merkle verify -dist dist.json
+//	cgr-merkle verify -root 0x.. -account 0x.. -amount 100 -proof 0x..,0x..
+//
+// build reads allocations from an account,amount CSV, a cgr-twab JSON
+// snapshot (each account's averages summed across pools) or indexed deposit
+// balances at a block, and writes the root plus every account's proof. -dump
+// also writes the tree in StandardMerkleTree.dump format. verify checks every
+// proof in a distribution file, or a single claim, and exits 1 on failure.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/asof"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/merkle"
+	"github.com/to-nexus/cross-game-reward/binding/go/twab"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-merkle build|verify [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "build":
+		build(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func build(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		csvPath  = fs.String("csv", "", "account,amount allocations")
+		twabPath = fs.String("twab", "", "cgr-twab JSON snapshot")
+		balances = fs.Bool("balances", false, "allocate indexed deposit balances")
+		at       = fs.String("at", "latest", "balances block: block, time, date or @unix")
+		pools    = fs.String("pools", "", "comma-separated pool IDs for -balances (default all)")
+		index    = fs.String("index", "index.json", "indexer store for -balances")
+		out      = fs.String("out", "dist.json", "distribution file")
+		dump     = fs.String("dump", "", "also write a StandardMerkleTree dump")
+	)
+	fs.Parse(args)
+
+	var (
+		allocs merkle.Allocations
+		err    error
+	)
+	switch {
+	case *csvPath != "":
+		var f *os.File
+		if f, err = os.Open(*csvPath); err == nil {
+			allocs, err = merkle.ReadCSV(f)
+			f.Close()
+		}
+	case *twabPath != "":
+		var raw []byte
+		if raw, err = os.ReadFile(*twabPath); err == nil {
+			snap := new(twab.Snapshot)
+			if err = json.Unmarshal(raw, snap); err == nil {
+				allocs = merkle.FromTWAB(snap)
+			}
+		}
+	case *balances:
+		allocs, err = indexedBalances(cfg, *at, *pools, *index)
+	default:
+		log.Fatal("one of -csv, -twab or -balances is required")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tree, err := merkle.Build(allocs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := tree.Distribution().WriteFile(*out); err != nil {
+		log.Fatal(err)
+	}
+	if *dump != "" {
+		if err := tree.WriteDump(*dump); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Println(tree.Root().Hex())
+	log.Printf("%d account(s), total %s", len(tree.Values), allocs.Total())
+}
+
+func indexedBalances(cfg *config.Flags, at, pools, index string) (merkle.Allocations, error) {
+	point, err := asof.ParsePoint(at)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[uint64]bool)
+	for _, s := range strings.Split(pools, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer net.Close()
+	block, _, err := asof.New(net).Block(ctx, point)
+	if err != nil {
+		return nil, err
+	}
+	store, err := indexer.SyncFile(ctx, net, index)
+	if err != nil {
+		return nil, err
+	}
+	if block > store.Head() {
+		return nil, fmt.Errorf("index at block %d is behind block %d", store.Head(), block)
+	}
+	var bals []map[common.Address]*big.Int
+	for addr, id := range store.Pools() {
+		if len(ids) == 0 || ids[id] {
+			bals = append(bals, store.Balances(addr, block))
+		}
+	}
+	log.Printf("balances at block %d", block)
+	return merkle.FromBalances(bals...), nil
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	var (
+		dist    = fs.String("dist", "", "distribution file")
+		root    = fs.String("root", "", "root for a single claim (default the -dist root)")
+		account = fs.String("account", "", "claim account")
+		amount  = fs.String("amount", "", "claim amount (default the -dist amount)")
+		proof   = fs.String("proof", "", "comma-separated proof (default the -dist proof)")
+	)
+	fs.Parse(args)
+
+	var d *merkle.Distribution
+	if *dist != "" {
+		var err error
+		if d, err = merkle.ReadDistribution(*dist); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *account == "" {
+		if d == nil {
+			log.Fatal("-dist or -account is required")
+		}
+		if err := d.Verify(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%s: %d claim(s) verify, total %s", d.Root.Hex(), len(d.Claims), d.Total)
+		return
+	}
+
+	if !common.IsHexAddress(*account) {
+		log.Fatalf("invalid -account %q", *account)
+	}
+	acct := common.HexToAddress(*account)
+	var c merkle.Claim
+	var r common.Hash
+	if d != nil {
+		c, r = d.Claims[acct], d.Root
+	}
+	if *root != "" {
+		r = common.HexToHash(*root)
+	}
+	if *amount != "" {
+		v, ok := new(big.Int).SetString(*amount, 10)
+		if !ok {
+			log.Fatalf("invalid -amount %q", *amount)
+		}
+		c.Amount = v
+	}
+	if *proof != "" {
+		c.Proof = nil
+		for _, s := range strings.Split(*proof, ",") {
+			c.Proof = append(c.Proof, common.HexToHash(strings.TrimSpace(s)))
+		}
+	}
+	if c.Amount == nil {
+		log.Fatalf("%s: no claim", acct.Hex())
+	}
+	if !merkle.Verify(r, acct, c.Amount, c.Proof) {
+		log.Printf("%s: claim of %s does not verify against %s", acct.Hex(), c.Amount, r.Hex())
+		os.Exit(1)
+	}
+	log.Printf("%s: claim of %s verifies against %s", acct.Hex(), c.Amount, r.Hex())
+}
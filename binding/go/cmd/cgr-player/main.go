@@ -0,0 +1,226 @@
+// Command cgr-player runs multi-pool router actions for one account.
+//
+//	cgr-player withdraw-all [-user 0x..] [-limit 8000000] [-execute]
+//	cgr-player claim-all    [-user 0x..] [-price 0x..=1.5 ...] [-execute]
+//	cgr-player migrate      [-user 0x..] [-execute]
+//
+// withdraw-all prints which pools the account has deposits in, what it
+// would receive and the estimated gas of the router's withdrawAll. With
+// -execute it sends withdrawAll, or one withdrawal per pool when the
+// estimate exceeds -limit or a pool would make withdrawAll revert, logging
+// progress as each transaction is mined.
+//
+// claim-all lists the pools with pending rewards, most valuable first by
+// -price (per whole token; without prices every whole token counts as
+// one), skipping paused pools. With -execute it claims each pool and prints
+// the claimed, failed and skipped amounts per reward token.
+//
+// migrate is a dry run of moving deposits out of Inactive pools into the
+// Active pool for the same deposit token, with each target's minimum
+// deposit checked. With -execute it withdraws, collecting rewards, and
+// redeposits through the native or ERC-20 router path, using a permit
+// where the token supports EIP-2612.
+//
+// Without -user the account is the one in CGR_PRIVATE_KEY, which -execute
+// signs with.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/player"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// prices collects repeated -price token=value flags.
+type prices map[common.Address]float64
+
+func (p prices) String() string { return fmt.Sprint(map[common.Address]float64(p)) }
+
+func (p prices) Set(v string) error {
+	token, value, ok := strings.Cut(v, "=")
+	if !ok || !common.IsHexAddress(token) {
+		return fmt.Errorf("want token=value, got %q", v)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		return fmt.Errorf("invalid value %q", value)
+	}
+	p[common.HexToAddress(token)] = f
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-player withdraw-all|claim-all|migrate [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		user    = fs.String("user", "", "account to plan for (default the CGR_PRIVATE_KEY account)")
+		limit   = fs.Uint64("limit", player.DefaultGasLimit, "withdrawAll gas above which withdrawals are sent per pool")
+		execute = fs.Bool("execute", false, "send the transactions (default: preview only)")
+		price   = prices{}
+	)
+	fs.Var(price, "price", "claim-all: value of one whole reward token as token=value (repeatable)")
+	fs.Parse(os.Args[2:])
+	switch cmd {
+	case "withdraw-all", "claim-all", "migrate":
+	default:
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	p, err := player.New(net)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var tm *txmgr.Manager
+	if *execute || *user == "" {
+		key, err := txmgr.KeyFromEnv()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if tm, err = txmgr.New(ctx, net.Client, key, net.Profile.Confirmations); err != nil {
+			log.Fatal(err)
+		}
+	}
+	account := func() common.Address {
+		if *user == "" {
+			return tm.From()
+		}
+		if !common.IsHexAddress(*user) {
+			log.Fatalf("invalid -user %q", *user)
+		}
+		return common.HexToAddress(*user)
+	}()
+
+	switch cmd {
+	case "claim-all":
+		if len(price) > 0 {
+			p.Prices = price
+		}
+		claimAll(ctx, p, tm, account, *execute)
+		return
+	case "migrate":
+		migrate(ctx, p, tm, account, *execute)
+		return
+	}
+
+	plan, err := p.PlanWithdrawAll(ctx, account, *limit)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printJSON(plan)
+	if !*execute || len(plan.Pools) == 0 {
+		return
+	}
+	if plan.Chunked {
+		log.Printf("withdrawing pool by pool: %s", plan.Reason)
+	}
+	failed := false
+	_, err = p.WithdrawAll(ctx, tm, plan, func(done, total int, s player.Step) {
+		what := "withdrawAll"
+		if s.PoolID != nil {
+			what = "pool " + s.PoolID.String()
+		}
+		switch {
+		case s.Err != nil:
+			failed = true
+			log.Printf("[%d/%d] %s: %v", done, total, what, s.Err)
+		case s.Skipped != "":
+			log.Printf("[%d/%d] %s: skipped, %s", done, total, what, s.Skipped)
+		default:
+			log.Printf("[%d/%d] %s: %s mined in block %d", done, total, what, s.TxHash.Hex(), s.Block)
+		}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func claimAll(ctx context.Context, p *player.Player, tm *txmgr.Manager, account common.Address, execute bool) {
+	plan, err := p.PlanClaims(ctx, account)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printJSON(plan)
+	if !execute || len(plan.Pools) == 0 {
+		return
+	}
+	results, err := p.Claim(ctx, tm, plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printJSON(results)
+	for _, r := range results {
+		if len(r.Errors) > 0 || r.Failed.Sign() > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+func migrate(ctx context.Context, p *player.Player, tm *txmgr.Manager, account common.Address, execute bool) {
+	plan, err := p.PlanMigrations(ctx, account)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printJSON(plan)
+	if !execute || len(plan.Migrations) == 0 {
+		return
+	}
+	failed := false
+	_, err = p.Migrate(ctx, tm, plan, func(done, total int, r player.MigrationResult) {
+		switch {
+		case r.Err != nil:
+			failed = true
+			log.Printf("[%d/%d] pool %s: %v", done, total, r.From, r.Err)
+		case r.Skipped != "":
+			log.Printf("[%d/%d] pool %s: skipped, %s", done, total, r.From, r.Skipped)
+		default:
+			log.Printf("[%d/%d] pool %s -> %s: moved %s (withdraw %s, deposit %s)", done, total, r.From, r.To, r.Withdrawn, r.WithdrawTx.Hex(), r.DepositTx.Hex())
+		}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
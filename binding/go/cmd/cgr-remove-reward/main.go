@@ -0,0 +1,164 @@
+// Command cgr-remove-reward removes a reward token from a pool with a
+// before/after report of every depositor's claimable amount.
+//
+//	cgr-remove-reward snapshot -pool 1 -token 0x.. -out snap.json
+//	cgr-remove-reward verify   -snapshot snap.json [-block N]
+//	cgr-remove-reward run      -pool 1 -token 0x..
+//
+// run snapshots, sends removeRewardToken as MANAGER_ROLE and verifies in one
+// go. Reports are written as Markdown (default), CSV or JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/removal"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-remove-reward snapshot|verify|run [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		index     = fs.String("index", "index.json", "indexer store used to find depositors")
+		poolID    = fs.Uint64("pool", 0, "pool ID")
+		token     = fs.String("token", "", "reward token address")
+		out       = fs.String("out", "", "output file (default stdout)")
+		snapPath  = fs.String("snapshot", "", "snapshot file to verify against")
+		block     = fs.Uint64("block", 0, "block to snapshot or verify at (default latest)")
+		format    = fs.String("format", "md", "report format: md, csv or json")
+		tolerance = fs.String("tolerance", "0", "per-account difference (wei) reported as dust")
+	)
+	fs.Parse(os.Args[2:])
+
+	tol, ok := new(big.Int).SetString(*tolerance, 10)
+	if !ok {
+		log.Fatalf("invalid -tolerance %q", *tolerance)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	at := func() uint64 {
+		if *block != 0 {
+			return *block
+		}
+		head, err := net.Client.BlockNumber(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return head
+	}
+	target := func() (*big.Int, common.Address) {
+		if *poolID == 0 || !common.IsHexAddress(*token) {
+			log.Fatal("-pool and -token are required")
+		}
+		return new(big.Int).SetUint64(*poolID), common.HexToAddress(*token)
+	}
+
+	var report *removal.Report
+	switch cmd {
+	case "snapshot":
+		id, tok := target()
+		store, err := indexer.SyncFile(ctx, net, *index)
+		if err != nil {
+			log.Fatal(err)
+		}
+		snap, err := removal.TakeSnapshot(ctx, net, store, id, tok, at())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *out == "" {
+			json.NewEncoder(os.Stdout).Encode(snap)
+			return
+		}
+		if err := removal.WriteSnapshot(*out, snap); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("snapshot of %d holders at block %d written to %s", len(snap.Holdings), snap.Block, *out)
+		return
+	case "verify":
+		if *snapPath == "" {
+			log.Fatal("-snapshot is required")
+		}
+		snap, err := removal.ReadSnapshot(*snapPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if report, err = removal.Verify(ctx, net, snap, at(), tol); err != nil {
+			log.Fatal(err)
+		}
+	case "run":
+		id, tok := target()
+		store, err := indexer.SyncFile(ctx, net, *index)
+		if err != nil {
+			log.Fatal(err)
+		}
+		key, err := txmgr.KeyFromEnv()
+		if err != nil {
+			log.Fatal(err)
+		}
+		tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if report, err = removal.Run(ctx, net, store, tm, id, tok, tol); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "csv":
+		err = report.WriteCSV(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	default:
+		err = report.WriteMarkdown(w)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println(report.Summary())
+	if report.Flagged > 0 || !report.UnattributedOK {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,146 @@
+// Command cgr-schedule queues pool status changes and sends them on time.
+//
+//	cgr-schedule add    -pool 3 -status Inactive (-at 2026-12-01T00:00:00Z | -block N) [-id close-s3]
+//	cgr-schedule cancel -id close-s3
+//	cgr-schedule list
+//	cgr-schedule run    [-interval 1s]
+//
+// The queue is kept in -queue (default schedule.json), which add and
+// cancel may edit while run is going. run sends each change as it falls
+// due, signed with CGR_PRIVATE_KEY (which must hold MANAGER_ROLE), verifies
+// it by the pool's PoolStatusChanged event and logs how far from the plan
+// it took effect. list prints the queue with every finished entry's drift.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/schedule"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-schedule add|cancel|list|run [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		queue    = fs.String("queue", "schedule.json", "queue file")
+		id       = fs.String("id", "", "add, cancel: entry ID (add default: one past the highest number)")
+		poolID   = fs.Uint64("pool", 0, "add: pool ID")
+		status   = fs.String("status", "", "add: Active, Inactive or Paused")
+		at       = fs.String("at", "", "add: target time, RFC 3339")
+		block    = fs.Uint64("block", 0, "add: target block")
+		interval = fs.Duration("interval", schedule.DefaultInterval, "run: time between checks")
+	)
+	fs.Parse(os.Args[2:])
+
+	switch cmd {
+	case "add", "cancel":
+		q, err := schedule.LoadQueue(*queue)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cmd == "cancel" {
+			err = q.Remove(*id)
+		} else {
+			err = q.Add(entry(*id, *poolID, *status, *at, *block))
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := q.Save(*queue); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "list":
+		q, err := schedule.LoadQueue(*queue)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(q)
+		return
+	case "run":
+	default:
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := schedule.New(ctx, net, tm, *queue)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.Interval = *interval
+	s.OnDone = func(e *schedule.Entry) {
+		drift := e.Done.Drift.String()
+		if e.Block != 0 {
+			drift = fmt.Sprintf("%d block(s)", e.Done.DriftBlocks)
+		}
+		if e.Done.TxHash == (common.Hash{}) {
+			log.Printf("%s: pool %d already %s at block %d", e.ID, e.PoolID, e.Status, e.Done.Block)
+			return
+		}
+		log.Printf("%s: pool %d %s -> %s in block %d (%s), drift %s",
+			e.ID, e.PoolID, e.Done.From, e.Status, e.Done.Block, e.Done.TxHash.Hex(), drift)
+	}
+	s.OnError = func(err error) { log.Print(err) }
+	if err := s.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
+
+// entry builds an add entry from the flags.
+func entry(id string, poolID uint64, status, at string, block uint64) *schedule.Entry {
+	e := &schedule.Entry{ID: id, PoolID: poolID, Block: block}
+	if err := e.Status.UnmarshalText([]byte(status)); err != nil {
+		log.Fatalf("invalid -status: %v", err)
+	}
+	if at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			log.Fatalf("invalid -at: %v", err)
+		}
+		e.At = t.UTC()
+	}
+	return e
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
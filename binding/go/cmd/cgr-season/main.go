@@ -0,0 +1,156 @@
+// Command cgr-season runs a season rollover from a spec file.
+//
+//	cgr-season run     -spec season.json [-state season.state.json] [-interval 1m] [-once]
+//	cgr-season status  -spec season.json [-state season.state.json]
+//	cgr-season summary -spec season.json [-state season.state.json] [-index index.json]
+//
+// run executes the spec's steps as they fall due, signing with
+// CGR_PRIVATE_KEY (which must hold MANAGER_ROLE), and saves progress to the
+// state file after each step. It exits when every step is done, or after
+// one pass with -once. Stopping and running it again resumes where it left
+// off.
+//
+// status prints the state file. summary syncs the index file and prints
+// participants, deposits and rewards distributed and reclaimed for the
+// spec's previous pool.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/season"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-season run|status|summary -spec season.json [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		specPath  = fs.String("spec", "", "season spec file")
+		statePath = fs.String("state", "", "progress file (default: the spec path with .state.json)")
+		interval  = fs.Duration("interval", season.DefaultInterval, "run: time between checks for due steps")
+		once      = fs.Bool("once", false, "run: run due steps once and exit")
+		indexPath = fs.String("index", "index.json", "summary: index file, synced before summarizing")
+	)
+	fs.Parse(os.Args[2:])
+	switch cmd {
+	case "run", "status", "summary":
+	default:
+		usage()
+	}
+	if *specPath == "" {
+		usage()
+	}
+	spec, err := season.LoadSpec(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *statePath == "" {
+		*statePath = *specPath + ".state.json"
+	}
+	if cmd == "status" {
+		state, err := season.LoadState(*statePath, spec.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(state)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	if cmd == "summary" {
+		if spec.Previous == 0 {
+			log.Fatal("the spec has no previous pool to summarize")
+		}
+		state, err := season.LoadState(*statePath, spec.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store, err := indexer.SyncFile(ctx, net, *indexPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s := season.Summarize(store, state, spec.Previous)
+		if s == nil {
+			log.Fatalf("pool %d is not in %s", spec.Previous, *indexPath)
+		}
+		printJSON(s)
+		return
+	}
+
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r, err := season.New(ctx, net, tm, spec, *statePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	r.Interval = *interval
+	r.OnStep = func(id string, rec *season.StepRecord) {
+		switch {
+		case rec.TxHash != (common.Hash{}):
+			log.Printf("%s: done in %s", id, rec.TxHash.Hex())
+		case rec.Note != "":
+			log.Printf("%s: %s", id, rec.Note)
+		default:
+			log.Printf("%s: done", id)
+		}
+	}
+	r.OnError = func(err error) { log.Printf("%v (retrying in %s)", err, *interval) }
+
+	if *once {
+		done, err := r.Step(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if done {
+			log.Printf("season %s complete", spec.Name)
+		}
+		return
+	}
+	if err := r.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("season %s complete at %s", spec.Name, time.Now().UTC().Format(time.RFC3339))
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
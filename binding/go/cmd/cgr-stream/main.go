@@ -0,0 +1,110 @@
+// Command cgr-stream prints every event of the factory, all pools, the
+// router and WCROSS as JSON lines. With -delivery final an event is printed
+// once its block is final under the network profile; in the default fast
+// mode it is printed at once and followed by a "retracted" line if a reorg
+// removes it. The network's RPC endpoint must support subscriptions.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	"github.com/to-nexus/cross-game-reward/binding/go/stream"
+)
+
+type line struct {
+	Block         uint64         `json:"block"`
+	Time          uint64         `json:"time"`
+	Confirmations uint64         `json:"confirmations"`
+	Contract      string         `json:"contract"`
+	Address       common.Address `json:"address"`
+	Event         string         `json:"event"`
+	PoolID        *big.Int       `json:"poolId,omitempty"`
+	Args          map[string]any `json:"args,omitempty"`
+	TxHash        common.Hash    `json:"txHash"`
+	LogIndex      uint           `json:"logIndex"`
+	Retracted     bool           `json:"retracted,omitempty"`
+}
+
+func main() {
+	cfg := config.RegisterFlags(flag.CommandLine)
+	from := flag.Uint64("from", 0, "first block to print (0 starts at the head)")
+	delivery := flag.String("delivery", "fast", "fast or final")
+	flag.Parse()
+
+	mode, err := finality.ParseMode(*delivery)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	onError := func(err error) { log.Print(err) }
+	s := stream.New(net, stream.Config{From: *from, OnError: onError})
+	fin := finality.New(net.Client, func(ev *stream.Event) types.Log { return ev.Log }, finality.Config{
+		Mode:    mode,
+		Policy:  finality.ForProfile(net.Profile),
+		OnError: onError,
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- s.Run(ctx) }()
+	go fin.Run(ctx, s.C())
+
+	enc := json.NewEncoder(os.Stdout)
+	for u := range fin.C() {
+		ev := u.Event
+		err := enc.Encode(line{
+			Block:         ev.Log.BlockNumber,
+			Time:          ev.Time,
+			Confirmations: ev.Confirmations,
+			Contract:      ev.Contract.String(),
+			Address:       ev.Log.Address,
+			Event:         ev.Name,
+			PoolID:        ev.PoolID,
+			Args:          args(ev.Data),
+			TxHash:        ev.Log.TxHash,
+			LogIndex:      ev.Log.Index,
+			Retracted:     u.Retracted,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := <-errc; err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
+
+// args returns the fields of a generated event struct except Raw.
+func args(data any) map[string]any {
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data).Elem()
+	out := make(map[string]any, v.NumField())
+	for i := range v.NumField() {
+		if f := v.Type().Field(i); f.Name != "Raw" {
+			out[f.Name] = v.Field(i).Interface()
+		}
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+// Command cgr-sweep reports reclaimable reward balances across all pools and,
+// with -execute, reclaims them to a treasury address as MANAGER_ROLE.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/sweeper"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// tokenThresholds collects repeated -min token=amount flags.
+type tokenThresholds map[common.Address]*big.Int
+
+func (t tokenThresholds) String() string { return fmt.Sprint(map[common.Address]*big.Int(t)) }
+
+func (t tokenThresholds) Set(v string) error {
+	token, amount, ok := strings.Cut(v, "=")
+	if !ok || !common.IsHexAddress(token) {
+		return fmt.Errorf("want token=amount, got %q", v)
+	}
+	n, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", amount)
+	}
+	t[common.HexToAddress(token)] = n
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	cfg := config.RegisterFlags(flag.CommandLine)
+	var (
+		treasury  = flag.String("treasury", "", "address receiving reclaimed tokens")
+		threshold = flag.String("threshold", "1", "minimum amount (wei) worth sweeping")
+		execute   = flag.Bool("execute", false, "send reclaimFromPool transactions (default: report only)")
+		asJSON    = flag.Bool("json", false, "print results as JSON")
+		perToken  = tokenThresholds{}
+	)
+	flag.Var(perToken, "min", "per-token threshold as token=amount (repeatable)")
+	flag.Parse()
+
+	def, ok := new(big.Int).SetString(*threshold, 10)
+	if !ok {
+		log.Fatalf("invalid -threshold %q", *threshold)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	sw := sweeper.New(net, sweeper.Thresholds{Default: def, ByToken: perToken})
+	cands, err := sw.Scan(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !*execute {
+		printCandidates(cands, *asJSON)
+		return
+	}
+
+	if !common.IsHexAddress(*treasury) {
+		log.Fatalf("-treasury is required with -execute")
+	}
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := sw.Sweep(ctx, tm, common.HexToAddress(*treasury), cands)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := printResults(results, *asJSON)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printCandidates(cands []sweeper.Candidate, asJSON bool) {
+	if asJSON {
+		writeJSON(cands)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POOL\tADDRESS\tTOKEN\tREMOVED\tRECLAIMABLE")
+	for _, c := range cands {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n", c.PoolID, c.Pool, c.Token, c.Removed, c.Amount)
+	}
+	w.Flush()
+}
+
+func printResults(results []sweeper.Result, asJSON bool) (failed int) {
+	for _, r := range results {
+		if !r.OK() {
+			failed++
+		}
+	}
+	if asJSON {
+		type row struct {
+			sweeper.Result
+			Err string `json:"Err,omitempty"`
+		}
+		rows := make([]row, len(results))
+		for i, r := range results {
+			rows[i] = row{Result: r}
+			if r.Err != nil {
+				rows[i].Err = r.Err.Error()
+			}
+		}
+		writeJSON(rows)
+		return failed
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POOL\tTOKEN\tSCANNED\tRECLAIMED\tTX\tSTATUS")
+	for _, r := range results {
+		status := "ok"
+		switch {
+		case r.Err != nil:
+			status = r.Err.Error()
+		case len(r.Discrepancies) > 0:
+			status = strings.Join(r.Discrepancies, "; ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%s\n", r.PoolID, r.Token, r.Amount, r.Transferred, r.TxHash, status)
+	}
+	w.Flush()
+	return failed
+}
+
+func writeJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,132 @@
+// Command cgr-twab computes each depositor's time- or block-weighted average
+// balance over a window from the local index and exports it as CSV or JSON.
+//
+//	cgr-twab -from 2024-03-01 -to 2024-04-01 -pools 1,2 -format csv -out season3.csv
+//	cgr-twab -weight block -from 18000000 -to 18200000 -reconcile
+//
+// -from and -to take a block number, an RFC 3339 time, a date (midnight
+// UTC) or @unix seconds. With -reconcile every end balance is checked
+// against balances(account) at the window's end block and the command exits
+// 1 on a mismatch.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/asof"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/twab"
+)
+
+func main() {
+	log.SetFlags(0)
+	cfg := config.RegisterFlags(flag.CommandLine)
+	var (
+		index     = flag.String("index", "index.json", "indexer store")
+		from      = flag.String("from", "", "window start: block, time, date or @unix")
+		to        = flag.String("to", "latest", "window end: block, time, date or @unix")
+		weight    = flag.String("weight", "time", "weighting: time or block")
+		pools     = flag.String("pools", "", "comma-separated pool IDs (default all)")
+		format    = flag.String("format", "csv", "output format: csv or json")
+		out       = flag.String("out", "", "output file (default stdout)")
+		reconcile = flag.Bool("reconcile", false, "check end balances against the pools")
+	)
+	flag.Parse()
+
+	start, err := asof.ParsePoint(*from)
+	if err != nil || *from == "" {
+		log.Fatalf("invalid -from %q", *from)
+	}
+	end, err := asof.ParsePoint(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ids, err := parseIDs(*pools)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	win, err := twab.NewWindow(ctx, asof.New(net), twab.Weighting(*weight), start, end)
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := indexer.SyncFile(ctx, net, *index)
+	if err != nil {
+		log.Fatal(err)
+	}
+	snap, err := twab.Compute(store, win, ids)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "csv":
+		err = snap.WriteCSV(w)
+	case "json":
+		err = snap.WriteJSON(w)
+	default:
+		log.Fatalf("unknown -format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d account(s) over %s window %d-%d", len(snap.Entries), win.Weighting, win.Start, win.End)
+
+	if !*reconcile {
+		return
+	}
+	mismatches, err := twab.Reconcile(ctx, net, snap)
+	if err != nil {
+		log.Fatal(err)
+	}
+	enc := json.NewEncoder(os.Stderr)
+	for _, m := range mismatches {
+		enc.Encode(m)
+	}
+	if len(mismatches) > 0 {
+		log.Printf("block %d: %d end balance(s) differ from the pools", win.EndBlock, len(mismatches))
+		os.Exit(1)
+	}
+	log.Printf("block %d: end balances reconcile", win.EndBlock)
+}
+
+func parseIDs(v string) ([]uint64, error) {
+	var ids []uint64
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,182 @@
+// Command cgr-wcross wraps and unwraps native CROSS, manages WCROSS
+// allowances and checks that WCROSS is fully backed.
+//
+//	cgr-wcross balance
+//	cgr-wcross wrap      -amount 1000000000000000000 [-reserve <wei>]
+//	cgr-wcross unwrap    -amount 1000000000000000000 [-to 0x..]
+//	cgr-wcross allowance -spender 0x..
+//	cgr-wcross approve   -spender 0x.. -amount <wei> [-ensure]
+//	cgr-wcross backing   [-block N]
+//	cgr-wcross monitor   [-interval 30s] [-webhook URL] [-slack URL]
+//
+// Amounts are in wei. Commands that send sign with CGR_PRIVATE_KEY. backing
+// exits 1 when totalSupply differs from the contract's native balance;
+// monitor keeps checking and alerts when they diverge and when they match
+// again.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+	"github.com/to-nexus/cross-game-reward/binding/go/wcross"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgr-wcross balance|wrap|unwrap|allowance|approve|backing|monitor [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	cfg := config.RegisterFlags(fs)
+	var (
+		amountFlag = fs.String("amount", "", "amount in wei")
+		reserve    = fs.String("reserve", "", "native wei wrap leaves for gas (default: the deposit's estimated gas cost)")
+		to         = fs.String("to", "", "unwrap recipient (default the sender)")
+		spender    = fs.String("spender", "", "allowance spender")
+		ensure     = fs.Bool("ensure", false, "approve only if the allowance is lower")
+		block      = fs.Int64("block", -1, "backing block (default head)")
+		interval   = fs.Duration("interval", wcross.DefaultInterval, "monitor interval")
+		webhook    = fs.String("webhook", "", "monitor webhook URL")
+		slack      = fs.String("slack", "", "monitor Slack webhook URL")
+	)
+	fs.Parse(os.Args[2:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	net, err := cfg.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+
+	switch cmd {
+	case "backing":
+		var at *big.Int
+		if *block >= 0 {
+			at = big.NewInt(*block)
+		}
+		b, err := wcross.CheckBacking(ctx, net, at)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(b)
+		if !b.OK() {
+			os.Exit(1)
+		}
+		return
+	case "monitor":
+		sinks := []alert.Sink{&alert.Writer{W: os.Stdout}}
+		if *webhook != "" {
+			sinks = append(sinks, &alert.Webhook{URL: *webhook})
+		}
+		if *slack != "" {
+			sinks = append(sinks, &alert.Slack{URL: *slack})
+		}
+		m := wcross.NewMonitor(net, sinks...)
+		m.Interval = *interval
+		m.OnError = func(err error) { log.Print(err) }
+		log.Printf("monitoring WCROSS %s backing every %s", net.WCROSSAddress.Hex(), *interval)
+		if err := m.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	key, err := txmgr.KeyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, net.Client, key, net.Profile.Confirmations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := wcross.NewWallet(net, tm)
+	amount := func() *big.Int {
+		v, ok := new(big.Int).SetString(*amountFlag, 10)
+		if !ok {
+			log.Fatalf("invalid -amount %q", *amountFlag)
+		}
+		return v
+	}
+	address := func(name, v string) common.Address {
+		if !common.IsHexAddress(v) {
+			log.Fatalf("invalid -%s %q", name, v)
+		}
+		return common.HexToAddress(v)
+	}
+
+	var receipt *types.Receipt
+	switch cmd {
+	case "balance":
+		bal, err := w.Balances(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printJSON(bal)
+		return
+	case "allowance":
+		a, err := w.Allowance(ctx, address("spender", *spender))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(a)
+		return
+	case "wrap":
+		if *reserve != "" {
+			if w.GasReserve, _ = new(big.Int).SetString(*reserve, 10); w.GasReserve == nil || w.GasReserve.Sign() < 0 {
+				log.Fatalf("invalid -reserve %q", *reserve)
+			}
+		}
+		receipt, err = w.Wrap(ctx, amount())
+	case "unwrap":
+		if *to == "" {
+			receipt, err = w.Unwrap(ctx, amount())
+		} else {
+			receipt, err = w.UnwrapTo(ctx, address("to", *to), amount())
+		}
+	case "approve":
+		if *ensure {
+			receipt, err = w.EnsureAllowance(ctx, address("spender", *spender), amount())
+		} else {
+			receipt, err = w.Approve(ctx, address("spender", *spender), amount())
+		}
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if receipt == nil {
+		log.Print("allowance already sufficient")
+		return
+	}
+	log.Printf("%s: mined in block %d", receipt.TxHash.Hex(), receipt.BlockNumber)
+}
+
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
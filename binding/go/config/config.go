@@ -0,0 +1,209 @@
+// Package config loads CROSS network profiles shared by the Go tooling.
+//
+// A profile names the chain, its RPC endpoints and the CrossGameReward
+// factory address. Router and WCROSS addresses are never stored: they are
+// resolved from the factory when a profile is connected, so a redeployed
+// router is picked up without touching the configuration.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Environment variables consulted by Load. Values set in the environment
+// override the ones read from the profile file.
+const (
+	EnvConfig        = "CGR_CONFIG"
+	EnvNetwork       = "CGR_NETWORK"
+	EnvChainID       = "CGR_CHAIN_ID"
+	EnvRPC           = "CGR_RPC"
+	EnvFactory       = "CGR_FACTORY"
+	EnvStartBlock    = "CGR_START_BLOCK"
+	EnvConfirmations = "CGR_CONFIRMATIONS"
+	EnvFinalized     = "CGR_FINALIZED"
+)
+
+var (
+	// ErrUnknownNetwork is returned when the requested profile is not in the file.
+	ErrUnknownNetwork = errors.New("config: unknown network")
+	// ErrInvalidProfile is returned when a profile misses a required field.
+	ErrInvalidProfile = errors.New("config: invalid profile")
+)
+
+// Profile describes one CROSS network deployment.
+type Profile struct {
+	// Name is the key of the profile in the network file.
+	Name string `json:"-"`
+	// ChainID is the chain ID every RPC endpoint must report.
+	ChainID uint64 `json:"chainId"`
+	// RPC lists endpoints in order of preference.
+	RPC []string `json:"rpc"`
+	// Factory is the CrossGameReward proxy address.
+	Factory common.Address `json:"factory"`
+	// StartBlock is the block the factory was deployed at; log scans begin here.
+	StartBlock uint64 `json:"startBlock"`
+	// Confirmations is the depth at which a block is treated as final.
+	Confirmations uint64 `json:"confirmations"`
+	// Finalized treats the node's finalized block as final instead of
+	// counting Confirmations.
+	Finalized bool `json:"finalized"`
+}
+
+// File is the on-disk network address book.
+type File struct {
+	// Default names the profile used when none is requested.
+	Default  string              `json:"default"`
+	Networks map[string]*Profile `json:"networks"`
+}
+
+// Validate reports whether the profile has everything needed to connect.
+func (p *Profile) Validate() error {
+	switch {
+	case p.ChainID == 0:
+		return fmt.Errorf("%w: %s: chainId is required", ErrInvalidProfile, p.label())
+	case len(p.RPC) == 0:
+		return fmt.Errorf("%w: %s: at least one rpc endpoint is required", ErrInvalidProfile, p.label())
+	case p.Factory == (common.Address{}):
+		return fmt.Errorf("%w: %s: factory address is required", ErrInvalidProfile, p.label())
+	}
+	return nil
+}
+
+func (p *Profile) label() string {
+	if p.Name == "" {
+		return "<env>"
+	}
+	return p.Name
+}
+
+// ReadFile parses a network address book.
+func ReadFile(path string) (*File, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: read %s: %w", path, err)
+	}
+	var f File
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	for name, p := range f.Networks {
+		if p == nil {
+			return nil, fmt.Errorf("%w: %s: empty profile", ErrInvalidProfile, name)
+		}
+		p.Name = name
+	}
+	return &f, nil
+}
+
+// Profile returns the named profile, or the default one when name is empty.
+func (f *File) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = f.Default
+	}
+	p, ok := f.Networks[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q (known: %s)", ErrUnknownNetwork, name, strings.Join(f.Names(), ", "))
+	}
+	cp := *p
+	cp.RPC = append([]string(nil), p.RPC...)
+	return &cp, nil
+}
+
+// Names returns the profile names in sorted order.
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Networks))
+	for name := range f.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load resolves a profile from the address book at path and the environment.
+//
+// An empty path falls back to $CGR_CONFIG and an empty name to $CGR_NETWORK.
+// Without any file the profile is built from the environment alone. The
+// result is validated before it is returned.
+func Load(path, name string) (*Profile, error) {
+	return load(path, name, os.Getenv)
+}
+
+func load(path, name string, getenv func(string) string) (*Profile, error) {
+	if path == "" {
+		path = getenv(EnvConfig)
+	}
+	if name == "" {
+		name = getenv(EnvNetwork)
+	}
+	p := &Profile{Name: name}
+	if path != "" {
+		f, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if p, err = f.Profile(name); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(p, getenv); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func applyEnv(p *Profile, getenv func(string) string) error {
+	if v := getenv(EnvChainID); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", EnvChainID, err)
+		}
+		p.ChainID = id
+	}
+	if v := getenv(EnvRPC); v != "" {
+		p.RPC = p.RPC[:0]
+		for _, url := range strings.Split(v, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				p.RPC = append(p.RPC, url)
+			}
+		}
+	}
+	if v := getenv(EnvFactory); v != "" {
+		if !common.IsHexAddress(v) {
+			return fmt.Errorf("config: %s: invalid address %q", EnvFactory, v)
+		}
+		p.Factory = common.HexToAddress(v)
+	}
+	if v := getenv(EnvStartBlock); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", EnvStartBlock, err)
+		}
+		p.StartBlock = n
+	}
+	if v := getenv(EnvConfirmations); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", EnvConfirmations, err)
+		}
+		p.Confirmations = n
+	}
+	if v := getenv(EnvFinalized); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", EnvFinalized, err)
+		}
+		p.Finalized = b
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testFile = `{
+  "default": "testnet",
+  "networks": {
+    "testnet": {
+      "chainId": 1001,
+      "rpc": ["http://a", "http://b"],
+      "factory": "0x00000000000000000000000000000000000000aa",
+      "startBlock": 100,
+      "confirmations": 3
+    },
+    "mainnet": {
+      "chainId": 1002,
+      "rpc": ["http://c"],
+      "factory": "0x00000000000000000000000000000000000000bb"
+    }
+  }
+}`
+
+func writeFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "networks.json")
+	if err := os.WriteFile(path, []byte(testFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestLoadDefaultProfile(t *testing.T) {
+	p, err := load(writeFile(t), "", env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "testnet" || p.ChainID != 1001 || p.StartBlock != 100 || p.Confirmations != 3 {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+	if len(p.RPC) != 2 || p.Factory != common.HexToAddress("0xaa") {
+		t.Fatalf("unexpected endpoints or factory: %+v", p)
+	}
+}
+
+func TestLoadNamedProfileFromEnv(t *testing.T) {
+	path := writeFile(t)
+	p, err := load("", "", env(map[string]string{EnvConfig: path, EnvNetwork: "mainnet"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "mainnet" || p.ChainID != 1002 {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	p, err := load(writeFile(t), "testnet", env(map[string]string{
+		EnvRPC:           " http://x , http://y ,",
+		EnvFactory:       "0x00000000000000000000000000000000000000cc",
+		EnvConfirmations: "7",
+		EnvFinalized:     "true",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.RPC) != 2 || p.RPC[0] != "http://x" || p.RPC[1] != "http://y" {
+		t.Fatalf("rpc not overridden: %v", p.RPC)
+	}
+	if p.Factory != common.HexToAddress("0xcc") || p.Confirmations != 7 || !p.Finalized || p.ChainID != 1001 {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+}
+
+func TestEnvOnlyProfile(t *testing.T) {
+	p, err := load("", "", env(map[string]string{
+		EnvChainID: "31337",
+		EnvRPC:     "http://127.0.0.1:8545",
+		EnvFactory: "0x00000000000000000000000000000000000000dd",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ChainID != 31337 || len(p.RPC) != 1 {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	path := writeFile(t)
+	if _, err := load(path, "devnet", env(nil)); !errors.Is(err, ErrUnknownNetwork) {
+		t.Fatalf("want ErrUnknownNetwork, got %v", err)
+	}
+	if _, err := load("", "", env(map[string]string{EnvChainID: "1"})); !errors.Is(err, ErrInvalidProfile) {
+		t.Fatalf("want ErrInvalidProfile, got %v", err)
+	}
+	if _, err := load(path, "", env(map[string]string{EnvFactory: "nope"})); err == nil {
+		t.Fatal("want error for malformed factory address")
+	}
+}
+
+func TestProfileIsCopied(t *testing.T) {
+	f, err := ReadFile(writeFile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := f.Profile("testnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.RPC[0] = "mutated"
+	if f.Networks["testnet"].RPC[0] != "http://a" {
+		t.Fatal("profile shares rpc slice with the file")
+	}
+}
+
+func TestExampleFileValidates(t *testing.T) {
+	p, err := load("networks.example.json", "", env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolStatusText(t *testing.T) {
+	for s := PoolActive; s <= PoolPaused; s++ {
+		text, _ := s.MarshalText()
+		var back PoolStatus
+		if err := back.UnmarshalText(text); err != nil || back != s {
+			t.Errorf("%s round-tripped to %s, %v", s, back, err)
+		}
+	}
+	if s := PoolStatus(7); s.Valid() || s.String() != "7" {
+		t.Errorf("status 7: valid %v, %q", s.Valid(), s)
+	}
+	var s PoolStatus
+	if err := s.UnmarshalText([]byte("paused")); err == nil {
+		t.Error("lowercase name accepted")
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"context"
+	"flag"
+)
+
+// Flags holds the command-line flags every tool uses to pick a profile.
+type Flags struct {
+	Path    string
+	Network string
+}
+
+// RegisterFlags adds -config and -network to fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := new(Flags)
+	fs.StringVar(&f.Path, "config", "", "network address book (default $"+EnvConfig+")")
+	fs.StringVar(&f.Network, "network", "", "network profile name (default $"+EnvNetwork+" or the file default)")
+	return f
+}
+
+// Load resolves the selected profile.
+func (f *Flags) Load() (*Profile, error) {
+	return Load(f.Path, f.Network)
+}
+
+// Connect resolves the selected profile and connects to it.
+func (f *Flags) Connect(ctx context.Context) (*Network, error) {
+	p, err := f.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Connect(ctx, p)
+}
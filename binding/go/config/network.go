@@ -0,0 +1,232 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	// ErrChainIDMismatch is returned when an endpoint serves a different
+	// chain than the profile expects.
+	ErrChainIDMismatch = errors.New("config: chain id mismatch")
+	// ErrMissingRole is returned by RequireRole when an account lacks a
+	// factory role.
+	ErrMissingRole = errors.New("config: account lacks a factory role")
+)
+
+// Network is a connected profile with the protocol contracts bound.
+type Network struct {
+	Profile *Profile
+	Client  *ethclient.Client
+	// Endpoint is the RPC URL the client is connected to.
+	Endpoint string
+
+	FactoryAddress common.Address
+	Factory        *binding.CrossGameReward
+
+	// RouterAddress is the zero address while the factory has no router set;
+	// Router is nil in that case.
+	RouterAddress common.Address
+	Router        *binding.CrossGameRewardRouter
+
+	WCROSSAddress common.Address
+	WCROSS        *binding.WCROSS
+}
+
+// Connect dials the profile's endpoints in order and binds the contracts.
+//
+// Endpoints that cannot be reached are skipped. An endpoint reporting a chain
+// ID other than the profile's aborts the connection with ErrChainIDMismatch
+// rather than falling through, since it means the profile is wrong.
+func Connect(ctx context.Context, p *Profile) (*Network, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, url := range p.RPC {
+		client, err := ethclient.DialContext(ctx, url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		chainID, err := client.ChainID(ctx)
+		if err != nil {
+			client.Close()
+			errs = append(errs, fmt.Errorf("%s: chain id: %w", url, err))
+			continue
+		}
+		if !chainID.IsUint64() || chainID.Uint64() != p.ChainID {
+			client.Close()
+			return nil, fmt.Errorf("%w: %s expects %d, %s serves %s", ErrChainIDMismatch, p.label(), p.ChainID, url, chainID)
+		}
+		n, err := bindNetwork(ctx, p, client)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		n.Endpoint = url
+		return n, nil
+	}
+	return nil, fmt.Errorf("config: %s: no reachable endpoint: %w", p.label(), errors.Join(errs...))
+}
+
+func bindNetwork(ctx context.Context, p *Profile, client *ethclient.Client) (*Network, error) {
+	factory, err := binding.NewCrossGameReward(p.Factory, client)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	wcross, err := factory.Wcross(opts)
+	if err != nil {
+		return nil, fmt.Errorf("config: resolve wcross from factory %s: %w", p.Factory, err)
+	}
+	router, err := factory.Router(opts)
+	if err != nil {
+		return nil, fmt.Errorf("config: resolve router from factory %s: %w", p.Factory, err)
+	}
+	n := &Network{
+		Profile:        p,
+		Client:         client,
+		FactoryAddress: p.Factory,
+		Factory:        factory,
+		RouterAddress:  router,
+		WCROSSAddress:  wcross,
+	}
+	if n.WCROSS, err = binding.NewWCROSS(wcross, client); err != nil {
+		return nil, err
+	}
+	if router != (common.Address{}) {
+		if n.Router, err = binding.NewCrossGameRewardRouter(router, client); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Close releases the RPC connection.
+func (n *Network) Close() {
+	n.Client.Close()
+}
+
+// Pool binds the pool at addr.
+func (n *Network) Pool(addr common.Address) (*binding.CrossGameRewardPool, error) {
+	return binding.NewCrossGameRewardPool(addr, n.Client)
+}
+
+// PoolByID resolves a pool ID through the factory and binds it.
+func (n *Network) PoolByID(opts *bind.CallOpts, poolID *big.Int) (common.Address, *binding.CrossGameRewardPool, error) {
+	addr, err := n.Factory.GetPoolAddress(opts, poolID)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("config: pool %s: %w", poolID, err)
+	}
+	pool, err := n.Pool(addr)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return addr, pool, nil
+}
+
+// PoolRef is a pool discovered through the factory.
+type PoolRef struct {
+	ID      *big.Int
+	Address common.Address
+	Pool    *binding.CrossGameRewardPool
+}
+
+// Pools lists every pool registered in the factory, in factory order.
+func (n *Network) Pools(opts *bind.CallOpts) ([]PoolRef, error) {
+	ids, err := n.Factory.GetAllPoolIds(opts)
+	if err != nil {
+		return nil, fmt.Errorf("config: list pools: %w", err)
+	}
+	refs := make([]PoolRef, 0, len(ids))
+	for _, id := range ids {
+		addr, pool, err := n.PoolByID(opts, id)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, PoolRef{ID: id, Address: addr, Pool: pool})
+	}
+	return refs, nil
+}
+
+// Factory roles, by their Solidity names.
+const (
+	RoleAdmin   = "DEFAULT_ADMIN_ROLE"
+	RoleManager = "MANAGER_ROLE"
+)
+
+// RequireRole returns an ErrMissingRole error unless account holds the
+// factory role named role, one of RoleAdmin and RoleManager.
+func (n *Network) RequireRole(opts *bind.CallOpts, role string, account common.Address) error {
+	var id func(*bind.CallOpts) ([32]byte, error)
+	switch role {
+	case RoleAdmin:
+		id = n.Factory.DEFAULTADMINROLE
+	case RoleManager:
+		id = n.Factory.MANAGERROLE
+	default:
+		return fmt.Errorf("config: unknown role %q", role)
+	}
+	hash, err := id(opts)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", role, err)
+	}
+	ok, err := n.Factory.HasRole(opts, hash, account)
+	if err != nil {
+		return fmt.Errorf("config: has role: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s does not hold %s", ErrMissingRole, account, role)
+	}
+	return nil
+}
+
+// PoolStatus is a pool's status. Text forms spell it by name.
+type PoolStatus uint8
+
+// Pool statuses, as numbered by the pool contract.
+const (
+	PoolActive PoolStatus = iota
+	PoolInactive
+	PoolPaused
+)
+
+var poolStatusNames = [...]string{"Active", "Inactive", "Paused"}
+
+// Valid reports whether s is a status the pool contract defines.
+func (s PoolStatus) Valid() bool {
+	return s <= PoolPaused
+}
+
+func (s PoolStatus) String() string {
+	if s.Valid() {
+		return poolStatusNames[s]
+	}
+	return strconv.Itoa(int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s PoolStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *PoolStatus) UnmarshalText(text []byte) error {
+	for i, name := range poolStatusNames {
+		if string(text) == name {
+			*s = PoolStatus(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("config: unknown pool status %q", text)
+}
@@ -0,0 +1,208 @@
+// Package desired reconciles the protocol's configuration with a
+// desired-state file kept under version control.
+//
+// A file lists the router, the pool implementation and every managed pool
+// with its minimum deposit, status and reward tokens. Plan reads the chain
+// and lists the exact factory calls that would make it match; Apply sends
+// them. Apply refuses plans with conflicts, refuses destructive changes
+// unless allowed, and refuses to run if the chain no longer yields the same
+// plan.
+package desired
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/probe"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+var (
+	// ErrConflict is returned by Apply for plans with conflicts.
+	ErrConflict = errors.New("desired: plan has conflicts")
+	// ErrDestructive is returned by Apply for plans with destructive
+	// changes unless they are allowed.
+	ErrDestructive = errors.New("desired: plan has destructive changes")
+	// ErrStale is returned by Apply when the chain has changed since the
+	// plan was made.
+	ErrStale = errors.New("desired: plan is stale")
+	// ErrMissingRole is returned by Apply when the sender lacks a role the
+	// plan's calls require. It is the error config.Network.RequireRole
+	// wraps.
+	ErrMissingRole = config.ErrMissingRole
+)
+
+// Reconciler plans and applies desired-state files on one network.
+type Reconciler struct {
+	net    *config.Network
+	reader *query.Reader
+}
+
+// New returns a Reconciler for net.
+func New(net *config.Network) *Reconciler {
+	return &Reconciler{net: net, reader: query.New(net)}
+}
+
+// Plan reads the chain at the latest block and diffs it against want.
+func (r *Reconciler) Plan(ctx context.Context, want *File) (*Plan, error) {
+	head, err := r.net.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("desired: head: %w", err)
+	}
+	have, err := r.Read(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)})
+	if err != nil {
+		return nil, err
+	}
+	return Diff(want, have), nil
+}
+
+// Options control Apply.
+type Options struct {
+	// AllowDestructive lets Apply send destructive changes.
+	AllowDestructive bool
+	// AllowWarnings accepts new deposit and reward tokens whose
+	// compatibility probe has only warnings.
+	AllowWarnings bool
+}
+
+// Applied is a change that was sent.
+type Applied struct {
+	Change
+	TxHash common.Hash
+	Block  uint64
+}
+
+// Apply sends plan's changes in order as tm's account, stopping at the
+// first failure. Before sending anything it re-plans want against the
+// latest block and returns ErrStale unless the result matches plan, checks
+// the sender's roles, and probes every deposit and reward token the plan
+// adds. progress, if set, is called after each change is mined.
+func (r *Reconciler) Apply(ctx context.Context, tm *txmgr.Manager, want *File, plan *Plan, opts Options, progress func(done, total int, a Applied)) ([]Applied, error) {
+	if len(plan.Conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrConflict, plan.Conflicts[0])
+	}
+	if plan.Destructive() && !opts.AllowDestructive {
+		return nil, ErrDestructive
+	}
+	fresh, err := r.Plan(ctx, want)
+	if err != nil {
+		return nil, err
+	}
+	if !sameCalls(plan, fresh) {
+		return nil, fmt.Errorf("%w: planned at block %d, block %d plans differently", ErrStale, plan.Block, fresh.Block)
+	}
+	if err := r.checkRoles(ctx, tm, plan); err != nil {
+		return nil, err
+	}
+	if err := r.probe(ctx, plan, opts.AllowWarnings); err != nil {
+		return nil, err
+	}
+
+	raw := &binding.CrossGameRewardRaw{Contract: r.net.Factory}
+	out := make([]Applied, 0, len(plan.Changes))
+	for _, c := range plan.Changes {
+		receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return raw.Transact(o, c.Method, c.Args...)
+		})
+		if err != nil {
+			return out, fmt.Errorf("desired: %s: %w", c.Call(), err)
+		}
+		if c.Method == "createPool" {
+			if err := r.checkCreated(receipt, c.PoolID); err != nil {
+				return out, err
+			}
+		}
+		a := Applied{Change: c, TxHash: receipt.TxHash, Block: receipt.BlockNumber.Uint64()}
+		out = append(out, a)
+		if progress != nil {
+			progress(len(out), len(plan.Changes), a)
+		}
+	}
+	return out, nil
+}
+
+func sameCalls(a, b *Plan) bool {
+	return slices.EqualFunc(a.Changes, b.Changes, func(x, y Change) bool { return x.Call() == y.Call() }) &&
+		slices.Equal(a.Conflicts, b.Conflicts)
+}
+
+// checkRoles requires DEFAULT_ADMIN_ROLE for factory settings and
+// MANAGER_ROLE for pool changes.
+func (r *Reconciler) checkRoles(ctx context.Context, tm *txmgr.Manager, plan *Plan) error {
+	var admin, manager bool
+	for _, c := range plan.Changes {
+		switch c.Method {
+		case "setRouter", "setPoolImplementation":
+			admin = true
+		default:
+			manager = true
+		}
+	}
+	for _, need := range []struct {
+		role string
+		on   bool
+	}{{config.RoleAdmin, admin}, {config.RoleManager, manager}} {
+		if !need.on {
+			continue
+		}
+		if err := r.net.RequireRole(tm.CallOpts(ctx), need.role, tm.From()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probe requires every token the plan adds to pass the compatibility
+// probe.
+func (r *Reconciler) probe(ctx context.Context, plan *Plan, allowWarnings bool) error {
+	seen := make(map[common.Address]bool)
+	p := probe.New(r.net)
+	for _, c := range plan.Changes {
+		var token common.Address
+		switch c.Method {
+		case "createPool", "addRewardToken":
+			token = c.Args[1].(common.Address)
+		default:
+			continue
+		}
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		rep, err := p.Probe(ctx, token, probe.Options{})
+		if err != nil {
+			return err
+		}
+		if err := probe.Require(rep, allowWarnings); err != nil {
+			return fmt.Errorf("desired: %s: %w", c.Call(), err)
+		}
+	}
+	return nil
+}
+
+// checkCreated confirms the pool got the ID later changes were planned
+// for.
+func (r *Reconciler) checkCreated(receipt *types.Receipt, want uint64) error {
+	for _, l := range receipt.Logs {
+		if l.Address != r.net.FactoryAddress {
+			continue
+		}
+		if ev, err := r.net.Factory.ParsePoolCreated(*l); err == nil {
+			if ev.PoolId.Uint64() != want {
+				return fmt.Errorf("%w: pool created as %s, planned as %d", ErrStale, ev.PoolId, want)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("desired: no PoolCreated event in %s", receipt.TxHash)
+}
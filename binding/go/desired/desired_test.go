@@ -0,0 +1,147 @@
+package desired
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+var (
+	depositToken = common.HexToAddress("0x1001")
+	rewardA      = common.HexToAddress("0x2001")
+	rewardB      = common.HexToAddress("0x2002")
+	router       = common.HexToAddress("0x0a01")
+)
+
+func TestExampleFile(t *testing.T) {
+	f, err := Load("protocol.example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Pools) != 2 || f.Pools[0].Status != config.PoolInactive || f.Pools[1].Status != config.PoolActive {
+		t.Errorf("file %+v", f)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *File {
+		return &File{Pools: []Pool{{Name: "S", DepositToken: depositToken, MinDeposit: big.NewInt(1), RewardTokens: []common.Address{rewardA}}}}
+	}
+	if err := valid().validate(); err != nil {
+		t.Fatal(err)
+	}
+	for name, mutate := range map[string]func(*File){
+		"zero min deposit":       func(f *File) { f.Pools[0].MinDeposit = new(big.Int) },
+		"deposit token rewarded": func(f *File) { f.Pools[0].RewardTokens = []common.Address{depositToken} },
+		"repeated pool":          func(f *File) { f.Pools = append(f.Pools, f.Pools[0]) },
+		"unknown status":         func(f *File) { f.Pools[0].Status = 7 },
+	} {
+		f := valid()
+		mutate(f)
+		if err := f.validate(); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%s: err %v", name, err)
+		}
+	}
+}
+
+func chainPool(id int64, name string, min int64, status config.PoolStatus, rewards, removed []common.Address) *query.Pool {
+	return &query.Pool{
+		ID:               big.NewInt(id),
+		Name:             name,
+		DepositToken:     depositToken,
+		Status:           uint8(status),
+		MinDepositAmount: big.NewInt(min),
+		RewardTokens:     rewards,
+		RemovedTokens:    removed,
+	}
+}
+
+func calls(p *Plan) []string {
+	out := make([]string, len(p.Changes))
+	for i, c := range p.Changes {
+		out[i] = c.Call()
+	}
+	return out
+}
+
+func TestDiffInSync(t *testing.T) {
+	want := &File{Router: router, Pools: []Pool{
+		{Name: "S3", DepositToken: depositToken, MinDeposit: big.NewInt(5), Status: config.PoolPaused, RewardTokens: []common.Address{rewardA}},
+	}}
+	have := &State{Router: router, NextPoolID: 2, Pools: []*query.Pool{chainPool(1, "S3", 5, config.PoolPaused, []common.Address{rewardA}, nil)}}
+	if p := Diff(want, have); len(p.Changes) != 0 || len(p.Conflicts) != 0 || len(p.Unmanaged) != 0 {
+		t.Errorf("plan %v", p)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	want := &File{Router: router, Pools: []Pool{
+		{ID: 1, Name: "S3", DepositToken: depositToken, MinDeposit: big.NewInt(10), Status: config.PoolInactive, RewardTokens: []common.Address{rewardB}},
+		{Name: "S4", DepositToken: depositToken, MinDeposit: big.NewInt(7), Status: config.PoolPaused, RewardTokens: []common.Address{rewardA, rewardB}},
+	}}
+	have := &State{
+		Block:      90,
+		Router:     common.HexToAddress("0x0bad"),
+		NextPoolID: 3,
+		Pools: []*query.Pool{
+			chainPool(1, "S3", 5, config.PoolActive, []common.Address{rewardA}, nil),
+			chainPool(2, "S2", 5, config.PoolInactive, nil, nil),
+		},
+	}
+	p := Diff(want, have)
+	got := calls(p)
+	wantCalls := []string{
+		"setRouter(" + router.Hex() + ")",
+		"updateMinDepositAmount(1, 10)",
+		"addRewardToken(1, " + rewardB.Hex() + ")",
+		"removeRewardToken(1, " + rewardA.Hex() + ")",
+		"setPoolStatus(1, 1 /* Inactive */)",
+		`createPool("S4", ` + depositToken.Hex() + ", 7)",
+		"addRewardToken(3, " + rewardA.Hex() + ")",
+		"addRewardToken(3, " + rewardB.Hex() + ")",
+		"setPoolStatus(3, 2 /* Paused */)",
+	}
+	if strings.Join(got, "\n") != strings.Join(wantCalls, "\n") {
+		t.Fatalf("calls\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantCalls, "\n"))
+	}
+	var destructive []string
+	for _, c := range p.Changes {
+		if c.Destructive {
+			destructive = append(destructive, c.Method)
+		}
+	}
+	if strings.Join(destructive, ",") != "setRouter,removeRewardToken,setPoolStatus" {
+		t.Errorf("destructive %v", destructive)
+	}
+	if len(p.Unmanaged) != 1 || p.Unmanaged[0] != 2 || len(p.Conflicts) != 0 {
+		t.Errorf("unmanaged %v conflicts %v", p.Unmanaged, p.Conflicts)
+	}
+	if !strings.Contains(p.String(), "  # pool 3") {
+		t.Errorf("plan text:\n%s", p)
+	}
+}
+
+func TestDiffConflicts(t *testing.T) {
+	want := &File{Pools: []Pool{
+		{ID: 1, Name: "renamed", DepositToken: depositToken, MinDeposit: big.NewInt(1)},
+		{ID: 9, Name: "missing", DepositToken: depositToken, MinDeposit: big.NewInt(1)},
+		{Name: "twin", DepositToken: depositToken, MinDeposit: big.NewInt(1)},
+		{ID: 4, Name: "S4", DepositToken: depositToken, MinDeposit: big.NewInt(1), RewardTokens: []common.Address{rewardA}},
+	}}
+	have := &State{NextPoolID: 5, Pools: []*query.Pool{
+		chainPool(1, "S1", 1, config.PoolActive, nil, nil),
+		chainPool(2, "twin", 1, config.PoolActive, nil, nil),
+		chainPool(3, "twin", 1, config.PoolActive, nil, nil),
+		chainPool(4, "S4", 1, config.PoolActive, nil, []common.Address{rewardA}),
+	}}
+	p := Diff(want, have)
+	if len(p.Changes) != 0 || len(p.Conflicts) != 4 || len(p.Unmanaged) != 0 {
+		t.Errorf("changes %v conflicts %q unmanaged %v", calls(p), p.Conflicts, p.Unmanaged)
+	}
+}
@@ -0,0 +1,97 @@
+package desired
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// ErrInvalidFile is returned for desired-state files that cannot be
+// reconciled whatever the chain holds.
+var ErrInvalidFile = errors.New("desired: invalid file")
+
+// File is the protocol configuration kept under version control.
+type File struct {
+	// Router and PoolImplementation are left alone when zero.
+	Router             common.Address `json:"router,omitzero"`
+	PoolImplementation common.Address `json:"poolImplementation,omitzero"`
+	// Pools lists the managed pools. Pools on chain that no entry matches
+	// are reported as unmanaged and never changed.
+	Pools []Pool `json:"pools"`
+}
+
+// Pool is the desired state of one pool. Name and deposit token are fixed
+// when a pool is created, so they identify it: an entry without ID matches
+// the one pool with the same name and deposit token, or creates it.
+type Pool struct {
+	// ID pins the entry to an existing pool.
+	ID           uint64         `json:"id,omitempty"`
+	Name         string         `json:"name"`
+	DepositToken common.Address `json:"depositToken"`
+	MinDeposit   *big.Int       `json:"minDeposit"`
+	// Status defaults to Active.
+	Status config.PoolStatus `json:"status"`
+	// RewardTokens is the complete set of active reward tokens; tokens the
+	// pool has that are not listed are removed.
+	RewardTokens []common.Address `json:"rewardTokens"`
+}
+
+type poolKey struct {
+	name  string
+	token common.Address
+}
+
+func (p *Pool) key() poolKey { return poolKey{p.Name, p.DepositToken} }
+
+// Load reads and validates the desired-state file at path.
+func Load(path string) (*File, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := new(File)
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, fmt.Errorf("desired: parse %s: %w", path, err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func (f *File) validate() error {
+	keys := make(map[poolKey]bool, len(f.Pools))
+	ids := make(map[uint64]bool, len(f.Pools))
+	for i := range f.Pools {
+		p := &f.Pools[i]
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("%w: pool %d has no name", ErrInvalidFile, i)
+		case p.DepositToken == (common.Address{}):
+			return fmt.Errorf("%w: pool %q has no deposit token", ErrInvalidFile, p.Name)
+		case p.MinDeposit == nil || p.MinDeposit.Sign() <= 0:
+			return fmt.Errorf("%w: pool %q needs a positive minDeposit", ErrInvalidFile, p.Name)
+		case !p.Status.Valid():
+			return fmt.Errorf("%w: pool %q has status %s", ErrInvalidFile, p.Name, p.Status)
+		case keys[p.key()]:
+			return fmt.Errorf("%w: pool %q with deposit token %s is listed twice", ErrInvalidFile, p.Name, p.DepositToken.Hex())
+		case p.ID != 0 && ids[p.ID]:
+			return fmt.Errorf("%w: pool ID %d is listed twice", ErrInvalidFile, p.ID)
+		}
+		keys[p.key()], ids[p.ID] = true, true
+		seen := make(map[common.Address]bool, len(p.RewardTokens))
+		for _, t := range p.RewardTokens {
+			if t == (common.Address{}) || t == p.DepositToken || seen[t] {
+				return fmt.Errorf("%w: pool %q reward token %s is zero, the deposit token or repeated", ErrInvalidFile, p.Name, t.Hex())
+			}
+			seen[t] = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package desired
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+// State is the on-chain configuration the plan is computed against.
+type State struct {
+	Block              uint64
+	Router             common.Address
+	PoolImplementation common.Address
+	// NextPoolID is the ID the factory gives the next created pool.
+	NextPoolID uint64
+	Pools      []*query.Pool
+}
+
+// Read reads the factory configuration and every pool at opts.BlockNumber.
+func (r *Reconciler) Read(opts *bind.CallOpts) (*State, error) {
+	s := &State{}
+	if opts.BlockNumber != nil {
+		s.Block = opts.BlockNumber.Uint64()
+	}
+	var err error
+	if s.Router, err = r.net.Factory.Router(opts); err != nil {
+		return nil, fmt.Errorf("desired: router: %w", err)
+	}
+	if s.PoolImplementation, err = r.net.Factory.PoolImplementation(opts); err != nil {
+		return nil, fmt.Errorf("desired: pool implementation: %w", err)
+	}
+	next, err := r.net.Factory.NextPoolId(opts)
+	if err != nil {
+		return nil, fmt.Errorf("desired: next pool ID: %w", err)
+	}
+	s.NextPoolID = next.Uint64()
+	if s.Pools, err = r.reader.Pools(opts, false); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Change is one factory call of a plan.
+type Change struct {
+	// Method and Args are the factory call, as named in its ABI.
+	Method string
+	Args   []any
+	// PoolID is the pool the call changes; for createPool it is the ID the
+	// pool is expected to get. Zero for factory settings.
+	PoolID uint64
+	// Destructive marks changes that cannot be undone or that stop players
+	// from using a pool: removing a reward token, setting a pool Inactive
+	// or Paused, and replacing the router.
+	Destructive bool
+	// Was describes the value being replaced, if any.
+	Was string
+}
+
+// Call formats the change as a Solidity call.
+func (c *Change) Call() string {
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		switch v := a.(type) {
+		case string:
+			args[i] = fmt.Sprintf("%q", v)
+		case common.Address:
+			args[i] = v.Hex()
+		case uint8: // setPoolStatus
+			args[i] = fmt.Sprintf("%d /* %s */", v, config.PoolStatus(v))
+		default:
+			args[i] = fmt.Sprint(v)
+		}
+	}
+	return c.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Plan is the set of factory calls that reconciles the chain with a file.
+type Plan struct {
+	Block   uint64
+	Changes []Change
+	// Conflicts are differences no factory call can reconcile. A plan
+	// with conflicts cannot be applied.
+	Conflicts []string
+	// Unmanaged lists pools on chain that no file entry matches.
+	Unmanaged []uint64
+}
+
+// Destructive reports whether any change is destructive.
+func (p *Plan) Destructive() bool {
+	return slices.ContainsFunc(p.Changes, func(c Change) bool { return c.Destructive })
+}
+
+// String formats the plan for review, one call per line.
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# state at block %d\n", p.Block)
+	for _, c := range p.Changes {
+		line := "  " + c.Call()
+		if c.Method == "createPool" {
+			line += fmt.Sprintf("  # pool %d", c.PoolID)
+		}
+		if c.Was != "" {
+			line += "  # was " + c.Was
+		}
+		if c.Destructive {
+			line = "!" + line[1:] + "  [destructive]"
+		}
+		b.WriteString(line + "\n")
+	}
+	for _, c := range p.Conflicts {
+		fmt.Fprintf(&b, "conflict: %s\n", c)
+	}
+	for _, id := range p.Unmanaged {
+		fmt.Fprintf(&b, "# pool %d is not in the file and is left unchanged\n", id)
+	}
+	fmt.Fprintf(&b, "%d change(s), %d conflict(s)\n", len(p.Changes), len(p.Conflicts))
+	return b.String()
+}
+
+// Diff computes the plan that turns have into want. Factory settings come
+// first, then each file entry in order: createPool or
+// updateMinDepositAmount, addRewardToken, removeRewardToken and finally
+// setPoolStatus. Pools created by the plan are given the IDs the factory
+// will assign, in order from have.NextPoolID.
+func Diff(want *File, have *State) *Plan {
+	p := &Plan{Block: have.Block}
+	if want.Router != (common.Address{}) && want.Router != have.Router {
+		c := Change{Method: "setRouter", Args: []any{want.Router}}
+		if have.Router != (common.Address{}) {
+			c.Destructive, c.Was = true, have.Router.Hex()
+		}
+		p.Changes = append(p.Changes, c)
+	}
+	if want.PoolImplementation != (common.Address{}) && want.PoolImplementation != have.PoolImplementation {
+		p.Changes = append(p.Changes, Change{Method: "setPoolImplementation", Args: []any{want.PoolImplementation}, Was: have.PoolImplementation.Hex()})
+	}
+
+	m := match(want.Pools, have.Pools)
+	p.Conflicts = m.conflicts
+	next := have.NextPoolID
+	for i := range want.Pools {
+		w, h := &want.Pools[i], m.pools[i]
+		switch {
+		case m.blocked[i]:
+		case h == nil:
+			p.Changes = append(p.Changes, create(w, next)...)
+			next++
+		default:
+			changes, conflicts := update(w, h)
+			p.Changes = append(p.Changes, changes...)
+			p.Conflicts = append(p.Conflicts, conflicts...)
+		}
+	}
+	for _, h := range have.Pools {
+		if !m.claimed[h] {
+			p.Unmanaged = append(p.Unmanaged, h.ID.Uint64())
+		}
+	}
+	return p
+}
+
+// matching pairs file entries with pools on chain.
+type matching struct {
+	// pools holds each entry's pool, nil for entries the plan creates.
+	pools []*query.Pool
+	// blocked marks entries with a conflict, which get no changes.
+	blocked   []bool
+	claimed   map[*query.Pool]bool
+	conflicts []string
+}
+
+// match pairs each file entry with its pool on chain. Pinned entries are
+// matched first so an unpinned entry cannot take their pool.
+func match(want []Pool, have []*query.Pool) *matching {
+	m := &matching{
+		pools:   make([]*query.Pool, len(want)),
+		blocked: make([]bool, len(want)),
+		claimed: make(map[*query.Pool]bool),
+	}
+	for i := range want {
+		w := &want[i]
+		if w.ID == 0 {
+			continue
+		}
+		j := slices.IndexFunc(have, func(h *query.Pool) bool { return h.ID.Uint64() == w.ID })
+		switch {
+		case j < 0:
+			m.conflicts = append(m.conflicts, fmt.Sprintf("pool %d (%q) does not exist; drop the ID to create it", w.ID, w.Name))
+			m.blocked[i] = true
+		case have[j].Name != w.Name || have[j].DepositToken != w.DepositToken:
+			m.conflicts = append(m.conflicts, fmt.Sprintf("pool %d is %q with deposit token %s; name and deposit token cannot be changed",
+				w.ID, have[j].Name, have[j].DepositToken.Hex()))
+			m.blocked[i], m.claimed[have[j]] = true, true
+		default:
+			m.pools[i], m.claimed[have[j]] = have[j], true
+		}
+	}
+	for i := range want {
+		w := &want[i]
+		if w.ID != 0 {
+			continue
+		}
+		var found []*query.Pool
+		for _, h := range have {
+			if !m.claimed[h] && h.Name == w.Name && h.DepositToken == w.DepositToken {
+				found = append(found, h)
+			}
+		}
+		switch len(found) {
+		case 0:
+		case 1:
+			m.pools[i], m.claimed[found[0]] = found[0], true
+		default:
+			ids := make([]string, len(found))
+			for k, h := range found {
+				ids[k] = h.ID.String()
+				m.claimed[h] = true
+			}
+			m.conflicts = append(m.conflicts, fmt.Sprintf("pools %s are all %q with deposit token %s; set the entry's ID",
+				strings.Join(ids, ", "), w.Name, w.DepositToken.Hex()))
+			m.blocked[i] = true
+		}
+	}
+	return m
+}
+
+// create plans a new pool that gets ID id.
+func create(w *Pool, id uint64) []Change {
+	pid := new(big.Int).SetUint64(id)
+	out := []Change{{Method: "createPool", Args: []any{w.Name, w.DepositToken, w.MinDeposit}, PoolID: id}}
+	for _, t := range w.RewardTokens {
+		out = append(out, Change{Method: "addRewardToken", Args: []any{pid, t}, PoolID: id})
+	}
+	if w.Status != config.PoolActive {
+		// Nobody can have deposited yet, so this is not destructive.
+		out = append(out, Change{Method: "setPoolStatus", Args: []any{pid, uint8(w.Status)}, PoolID: id})
+	}
+	return out
+}
+
+// update plans the changes to an existing pool.
+func update(w *Pool, h *query.Pool) ([]Change, []string) {
+	var out []Change
+	var conflicts []string
+	id := h.ID.Uint64()
+	if w.MinDeposit.Cmp(h.MinDepositAmount) != 0 {
+		out = append(out, Change{Method: "updateMinDepositAmount", Args: []any{h.ID, w.MinDeposit}, PoolID: id, Was: h.MinDepositAmount.String()})
+	}
+	for _, t := range w.RewardTokens {
+		switch {
+		case slices.Contains(h.RewardTokens, t):
+		case slices.Contains(h.RemovedTokens, t):
+			conflicts = append(conflicts, fmt.Sprintf("pool %d: reward token %s was removed and cannot be added again", id, t.Hex()))
+		default:
+			out = append(out, Change{Method: "addRewardToken", Args: []any{h.ID, t}, PoolID: id})
+		}
+	}
+	for _, t := range h.RewardTokens {
+		if !slices.Contains(w.RewardTokens, t) {
+			out = append(out, Change{Method: "removeRewardToken", Args: []any{h.ID, t}, PoolID: id, Destructive: true})
+		}
+	}
+	if config.PoolStatus(h.Status) != w.Status {
+		out = append(out, Change{Method: "setPoolStatus", Args: []any{h.ID, uint8(w.Status)}, PoolID: id,
+			Destructive: w.Status != config.PoolActive, Was: config.PoolStatus(h.Status).String()})
+	}
+	return out, conflicts
+}
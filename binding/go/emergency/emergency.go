@@ -0,0 +1,257 @@
+// Package emergency freezes every pool at once and restores them
+// afterwards.
+//
+// PauseAll records each pool's status in a file, then sets every pool that
+// is not yet Paused to Paused. The transactions are signed up front with
+// consecutive nonces and fees well above the node's suggestion, broadcast
+// together and awaited together, and each pool's Paused() flag is read
+// back. ResumeAll sets each recorded pool back to its saved status. Pools
+// already in the target status are skipped, so both can be run again after
+// a partial failure; a second PauseAll keeps the statuses recorded before
+// the first.
+package emergency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+var (
+	// ErrNotManager is returned when the sending account lacks MANAGER_ROLE.
+	// It is the error config.Network.RequireRole wraps.
+	ErrNotManager = config.ErrMissingRole
+	// ErrNoRecord is returned by ResumeAll when there is no pause to undo.
+	ErrNoRecord = errors.New("emergency: no recorded pause")
+)
+
+// DefaultFeeMultiplier scales the suggested fees of emergency transactions.
+const DefaultFeeMultiplier = 3
+
+// Result is the outcome for one pool.
+type Result struct {
+	PoolID uint64
+	Pool   common.Address
+	From   config.PoolStatus
+	To     config.PoolStatus
+	// Skipped is set when the pool was already in status To.
+	Skipped bool
+	TxHash  common.Hash
+	Err     error
+	// Confirmed reports that the pool read back status To and the matching
+	// Paused() flag.
+	Confirmed bool
+}
+
+// Emergency pauses and resumes every pool from one account.
+type Emergency struct {
+	net  *config.Network
+	tm   *txmgr.Manager
+	path string
+	now  func() time.Time
+
+	// FeeMultiplier scales the node's suggested tip and the base fee. Zero
+	// means DefaultFeeMultiplier.
+	FeeMultiplier int64
+}
+
+// New returns an Emergency sending as tm's account, which must hold
+// MANAGER_ROLE, and keeping prior statuses in the file at path.
+func New(net *config.Network, tm *txmgr.Manager, path string) *Emergency {
+	return &Emergency{net: net, tm: tm, path: path, now: time.Now}
+}
+
+// PauseAll records every pool's status and pauses every pool.
+func (e *Emergency) PauseAll(ctx context.Context) ([]Result, error) {
+	if err := e.net.RequireRole(e.tm.CallOpts(ctx), config.RoleManager, e.tm.From()); err != nil {
+		return nil, err
+	}
+	rec, err := LoadRecord(e.path)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := e.net.Pools(e.tm.CallOpts(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil || !rec.ResumedAt.IsZero() {
+		rec = &Record{PausedAt: e.now().UTC()}
+	}
+	targets := make([]target, 0, len(pools))
+	for _, p := range pools {
+		status, err := p.Pool.PoolStatus(e.tm.CallOpts(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("emergency: pool %s status: %w", p.ID, err)
+		}
+		rec.add(p.ID.Uint64(), p.Address, config.PoolStatus(status))
+		targets = append(targets, target{ref: p, from: config.PoolStatus(status), to: config.PoolPaused})
+	}
+	// Save before sending, so the prior statuses survive whatever happens
+	// next.
+	if err := rec.Save(e.path); err != nil {
+		return nil, err
+	}
+	return e.setAll(ctx, targets)
+}
+
+// ResumeAll sets every recorded pool back to its saved status and marks
+// the record resumed once every pool is confirmed.
+func (e *Emergency) ResumeAll(ctx context.Context) ([]Result, error) {
+	if err := e.net.RequireRole(e.tm.CallOpts(ctx), config.RoleManager, e.tm.From()); err != nil {
+		return nil, err
+	}
+	rec, err := LoadRecord(e.path)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, ErrNoRecord
+	}
+	targets := make([]target, 0, len(rec.Pools))
+	for _, p := range rec.Pools {
+		pool, err := e.net.Pool(p.Pool)
+		if err != nil {
+			return nil, err
+		}
+		status, err := pool.PoolStatus(e.tm.CallOpts(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("emergency: pool %d status: %w", p.ID, err)
+		}
+		ref := config.PoolRef{ID: new(big.Int).SetUint64(p.ID), Address: p.Pool, Pool: pool}
+		targets = append(targets, target{ref: ref, from: config.PoolStatus(status), to: p.Prior})
+	}
+	results, err := e.setAll(ctx, targets)
+	if err != nil {
+		return results, err
+	}
+	for _, r := range results {
+		if !r.Confirmed {
+			return results, nil
+		}
+	}
+	if rec.ResumedAt.IsZero() {
+		rec.ResumedAt = e.now().UTC()
+	}
+	return results, rec.Save(e.path)
+}
+
+type target struct {
+	ref      config.PoolRef
+	from, to config.PoolStatus
+}
+
+// setAll moves every target to its status. All transactions are signed
+// before any is broadcast, so a signing or estimation failure sends
+// nothing and leaves no nonce gap. The returned error is set only when
+// nothing was sent.
+func (e *Emergency) setAll(ctx context.Context, targets []target) ([]Result, error) {
+	results := make([]Result, len(targets))
+	txs := make([]*types.Transaction, len(targets))
+	var fee *fees
+	for i, t := range targets {
+		results[i] = Result{PoolID: t.ref.ID.Uint64(), Pool: t.ref.Address, From: t.from, To: t.to, Skipped: t.from == t.to}
+		if results[i].Skipped {
+			continue
+		}
+		if fee == nil {
+			f, err := e.fees(ctx)
+			if err != nil {
+				return nil, err
+			}
+			fee = f
+		}
+		opts, err := e.tm.Opts(ctx)
+		if err != nil {
+			e.tm.Reset()
+			return nil, err
+		}
+		opts.NoSend = true
+		fee.apply(opts)
+		if txs[i], err = e.net.Factory.SetPoolStatus(opts, t.ref.ID, uint8(t.to)); err != nil {
+			e.tm.Reset()
+			return nil, fmt.Errorf("emergency: sign pool %d: %w", results[i].PoolID, err)
+		}
+		results[i].TxHash = txs[i].Hash()
+	}
+
+	// Transactions queued behind one that failed to broadcast get
+	// txmgr.ErrNonceGap; a rerun skips the pools that did change.
+	_, errs := e.tm.SendAll(ctx, txs)
+	for i, err := range errs {
+		results[i].Err = err
+	}
+	for i, t := range targets {
+		e.confirm(ctx, &results[i], t.ref)
+	}
+	return results, nil
+}
+
+// confirm reads the pool's status and Paused() flag back.
+func (e *Emergency) confirm(ctx context.Context, r *Result, ref config.PoolRef) {
+	opts := e.tm.CallOpts(ctx)
+	status, err := ref.Pool.PoolStatus(opts)
+	if err != nil {
+		r.Err = errors.Join(r.Err, fmt.Errorf("emergency: pool %d status: %w", r.PoolID, err))
+		return
+	}
+	paused, err := ref.Pool.Paused(opts)
+	if err != nil {
+		r.Err = errors.Join(r.Err, fmt.Errorf("emergency: pool %d paused: %w", r.PoolID, err))
+		return
+	}
+	r.Confirmed = config.PoolStatus(status) == r.To && paused == (r.To == config.PoolPaused)
+}
+
+// fees are the fee fields of emergency transactions.
+type fees struct {
+	tipCap, feeCap *big.Int
+	gasPrice       *big.Int // chains without a base fee
+}
+
+func (f *fees) apply(opts *bind.TransactOpts) {
+	opts.GasTipCap, opts.GasFeeCap, opts.GasPrice = f.tipCap, f.feeCap, f.gasPrice
+}
+
+// fees scales the suggested tip and the head's base fee by the multiplier,
+// so the transactions outbid ordinary traffic and survive base fee rises
+// for several blocks.
+func (e *Emergency) fees(ctx context.Context) (*fees, error) {
+	mult := big.NewInt(e.FeeMultiplier)
+	if e.FeeMultiplier == 0 {
+		mult.SetInt64(DefaultFeeMultiplier)
+	}
+	head, err := e.net.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("emergency: head: %w", err)
+	}
+	if head.BaseFee == nil {
+		price, err := e.net.Client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("emergency: gas price: %w", err)
+		}
+		return &fees{gasPrice: price.Mul(price, mult)}, nil
+	}
+	tip, err := e.net.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("emergency: gas tip: %w", err)
+	}
+	return urgentFees(head.BaseFee, tip, mult), nil
+}
+
+func urgentFees(baseFee, tip, mult *big.Int) *fees {
+	tip = new(big.Int).Mul(tip, mult)
+	if tip.Sign() == 0 {
+		tip.SetInt64(1)
+	}
+	feeCap := new(big.Int).Mul(baseFee, mult)
+	return &fees{tipCap: tip, feeCap: feeCap.Add(feeCap, tip)}
+}
@@ -0,0 +1,257 @@
+package emergency
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+func TestUrgentFees(t *testing.T) {
+	f := urgentFees(big.NewInt(100), big.NewInt(2), big.NewInt(3))
+	if f.tipCap.Int64() != 6 || f.feeCap.Int64() != 306 || f.gasPrice != nil {
+		t.Errorf("fees tip %s cap %s", f.tipCap, f.feeCap)
+	}
+	// A node suggesting no tip still gets one.
+	if f := urgentFees(big.NewInt(100), new(big.Int), big.NewInt(3)); f.tipCap.Int64() != 1 {
+		t.Errorf("zero suggestion gave tip %s", f.tipCap)
+	}
+}
+
+func TestRecordKeepsFirstStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pause.json")
+	if r, err := LoadRecord(path); r != nil || err != nil {
+		t.Fatalf("missing record: %v, %v", r, err)
+	}
+	pool := common.HexToAddress("0xb1")
+	r := &Record{PausedAt: time.Unix(1, 0).UTC()}
+	r.add(1, pool, config.PoolInactive)
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	// A rerun sees the pool already paused; the saved status must not
+	// change.
+	back, err := LoadRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back.add(1, pool, config.PoolPaused)
+	back.add(2, common.HexToAddress("0xb2"), config.PoolActive)
+	if len(back.Pools) != 2 || back.Pools[0].Prior != config.PoolInactive || back.Pools[1].Prior != config.PoolActive {
+		t.Errorf("pools %+v", back.Pools)
+	}
+}
+
+// fakeNode serves the JSON-RPC calls setAll makes. A transaction is mined
+// as soon as every lower nonce is, and mining setPoolStatus sets the pool's
+// status.
+type fakeNode struct {
+	mu      sync.Mutex
+	factory *abi.ABI
+	pool    *abi.ABI
+	pools   map[uint64]common.Address
+	status  map[common.Address]uint8
+	nonce   uint64
+	reject  map[uint64]bool
+	queued  map[uint64]*types.Transaction
+	mined   map[common.Hash]bool
+	sent    []uint64
+}
+
+func (n *fakeNode) ChainId() *hexutil.Big { return (*hexutil.Big)(big.NewInt(1)) }
+
+func (n *fakeNode) GetBlockByNumber(string, bool) *types.Header {
+	return &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int), BaseFee: big.NewInt(100)}
+}
+
+func (n *fakeNode) MaxPriorityFeePerGas() *hexutil.Big { return (*hexutil.Big)(big.NewInt(2)) }
+
+func (n *fakeNode) GetTransactionCount(common.Address, string) hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Uint64(n.nonce)
+}
+
+func (n *fakeNode) GetCode(common.Address, string) hexutil.Bytes { return hexutil.Bytes{1} }
+
+func (n *fakeNode) EstimateGas(map[string]any) hexutil.Uint64 { return 50000 }
+
+func (n *fakeNode) Call(args struct {
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+}, _ string) (hexutil.Bytes, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	m, err := n.pool.MethodById(args.Input)
+	if err != nil {
+		return nil, err
+	}
+	status := n.status[args.To]
+	if m.Name == "paused" {
+		return m.Outputs.Pack(status == uint8(config.PoolPaused))
+	}
+	return m.Outputs.Pack(status)
+}
+
+func (n *fakeNode) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, tx.Nonce())
+	if n.reject[tx.Nonce()] {
+		return common.Hash{}, errors.New("txpool is full")
+	}
+	n.queued[tx.Nonce()] = tx
+	for next := n.queued[n.nonce]; next != nil; next = n.queued[n.nonce] {
+		args, err := n.factory.Methods["setPoolStatus"].Inputs.Unpack(next.Data()[4:])
+		if err != nil {
+			return common.Hash{}, err
+		}
+		n.status[n.pools[args[0].(*big.Int).Uint64()]] = args[1].(uint8)
+		n.mined[next.Hash()] = true
+		delete(n.queued, n.nonce)
+		n.nonce++
+	}
+	return tx.Hash(), nil
+}
+
+func (n *fakeNode) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.mined[hash] {
+		return nil, nil
+	}
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: hash, BlockNumber: big.NewInt(1), Logs: []*types.Log{}}, nil
+}
+
+func TestSetAllBroadcastFailure(t *testing.T) {
+	ctx := context.Background()
+	factoryABI, err := binding.CrossGameRewardMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	poolABI, err := binding.CrossGameRewardPoolMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &fakeNode{
+		factory: factoryABI,
+		pool:    poolABI,
+		pools:   make(map[uint64]common.Address),
+		status:  make(map[common.Address]uint8),
+		nonce:   5,
+		reject:  map[uint64]bool{6: true},
+		queued:  make(map[uint64]*types.Transaction),
+		mined:   make(map[common.Hash]bool),
+	}
+	initial := []config.PoolStatus{config.PoolActive, config.PoolPaused, config.PoolActive, config.PoolInactive}
+	for i, s := range initial {
+		addr := common.BigToAddress(big.NewInt(int64(0xb1 + i)))
+		node.pools[uint64(i+1)] = addr
+		node.status[addr] = uint8(s)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", node); err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	defer client.Close()
+	factory, err := binding.NewCrossGameReward(common.HexToAddress("0xfa"), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, err := txmgr.New(ctx, client, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New(&config.Network{Client: client, Factory: factory}, tm, "")
+
+	pause := func() []Result {
+		t.Helper()
+		targets := make([]target, len(initial))
+		for i := range initial {
+			id := uint64(i + 1)
+			addr := node.pools[id]
+			pool, err := binding.NewCrossGameRewardPool(addr, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			node.mu.Lock()
+			from := config.PoolStatus(node.status[addr])
+			node.mu.Unlock()
+			targets[i] = target{ref: config.PoolRef{ID: new(big.Int).SetUint64(id), Address: addr, Pool: pool}, from: from, to: config.PoolPaused}
+		}
+		results, err := e.setAll(ctx, targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	// Pool 2 is already paused. Pools 1, 3 and 4 get nonces 5, 6 and 7;
+	// 6 fails to broadcast, so 7 is stuck behind it.
+	results := pause()
+	if !sameNonces(node.sent, 5, 6, 7) {
+		t.Fatalf("sent nonces %v, want 5, 6 and 7", node.sent)
+	}
+	want := []struct {
+		skipped, confirmed bool
+		err                error
+	}{{false, true, nil}, {true, true, nil}, {false, false, nil}, {false, false, txmgr.ErrNonceGap}}
+	for i, r := range results {
+		w := want[i]
+		if r.Skipped != w.skipped || r.Confirmed != w.confirmed {
+			t.Errorf("pool %d: skipped %v confirmed %v, want %v %v", r.PoolID, r.Skipped, r.Confirmed, w.skipped, w.confirmed)
+		}
+		if w.err != nil && !errors.Is(r.Err, w.err) {
+			t.Errorf("pool %d: err %v, want %v", r.PoolID, r.Err, w.err)
+		}
+	}
+	if results[0].Err != nil || results[2].Err == nil {
+		t.Errorf("errors %v, %v", results[0].Err, results[2].Err)
+	}
+
+	// A rerun skips the paused pools and resends the other two from the
+	// node's nonce.
+	node.reject, node.sent = nil, nil
+	results = pause()
+	if !sameNonces(node.sent, 6, 7) {
+		t.Fatalf("rerun sent nonces %v, want 6 and 7", node.sent)
+	}
+	for i, r := range results {
+		if r.Skipped != (i < 2) || !r.Confirmed || r.Err != nil {
+			t.Errorf("rerun pool %d: %+v", r.PoolID, r)
+		}
+	}
+}
+
+// sameNonces reports whether sent holds exactly want, in any order; the
+// broadcasts run in parallel.
+func sameNonces(sent []uint64, want ...uint64) bool {
+	got := slices.Clone(sent)
+	slices.Sort(got)
+	return slices.Equal(got, want)
+}
@@ -0,0 +1,66 @@
+package emergency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// Record holds the pool statuses saved by PauseAll.
+type Record struct {
+	PausedAt time.Time `json:"pausedAt"`
+	// ResumedAt is set once ResumeAll has confirmed every pool; the next
+	// PauseAll starts a new record.
+	ResumedAt time.Time    `json:"resumedAt,omitzero"`
+	Pools     []PoolStatus `json:"pools"`
+}
+
+// PoolStatus is a pool's status before the pause.
+type PoolStatus struct {
+	ID    uint64            `json:"id"`
+	Pool  common.Address    `json:"pool"`
+	Prior config.PoolStatus `json:"prior"`
+}
+
+// add records a pool's status unless the pool is already recorded, so a
+// repeated pause keeps the statuses from before the first.
+func (r *Record) add(id uint64, pool common.Address, status config.PoolStatus) {
+	for _, p := range r.Pools {
+		if p.ID == id {
+			return
+		}
+	}
+	r.Pools = append(r.Pools, PoolStatus{ID: id, Pool: pool, Prior: status})
+}
+
+// LoadRecord reads the record at path; nil if there is none.
+func LoadRecord(path string) (*Record, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := new(Record)
+	if err := json.Unmarshal(raw, r); err != nil {
+		return nil, fmt.Errorf("emergency: parse %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the record to path.
+func (r *Record) Save(path string) error {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
@@ -0,0 +1,32 @@
+// Package erc20 binds arbitrary ERC-20 tokens.
+//
+// WCROSS is a plain OpenZeppelin ERC20 plus wrap/unwrap, so its generated
+// binding already carries the full ERC-20 surface. The aliases here reuse it
+// for any token instead of shipping a second generated binding.
+package erc20
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+type (
+	// Token is a read/write ERC-20 binding.
+	Token = binding.WCROSS
+	// Caller is a read-only ERC-20 binding.
+	Caller = binding.WCROSSCaller
+	// Transfer is a decoded ERC-20 Transfer event.
+	Transfer = binding.WCROSSTransfer
+)
+
+// New binds the token at addr.
+func New(addr common.Address, backend bind.ContractBackend) (*Token, error) {
+	return binding.NewWCROSS(addr, backend)
+}
+
+// NewCaller binds the read-only surface of the token at addr.
+func NewCaller(addr common.Address, caller bind.ContractCaller) (*Caller, error) {
+	return binding.NewWCROSSCaller(addr, caller)
+}
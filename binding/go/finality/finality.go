@@ -0,0 +1,274 @@
+// Package finality holds back events until their block is final and turns
+// reorg removals into retractions.
+//
+// A Policy decides which blocks are final: those buried under a number of
+// confirmations, or those at or below the node's finalized block. A
+// Finalizer applies it to any stream of log-carrying events in one of two
+// modes:
+//
+//   - Fast delivers every event as soon as it is seen and delivers a
+//     retraction when a delivered log is later removed by a reorg.
+//   - Final buffers events until their block is final. Events removed while
+//     buffered are dropped without ever reaching the consumer; a retraction
+//     is only delivered for a reorg deeper than the policy.
+//
+// Events are identified by (block hash, log index), so a log re-included in
+// another block after a reorg is a new event and is delivered again.
+package finality
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// DefaultPollInterval is how often Run re-reads the final block.
+const DefaultPollInterval = 2 * time.Second
+
+// retainDepth is how many blocks below the final block delivered events are
+// remembered, so that a reorg deeper than the policy can still be retracted.
+const retainDepth = 256
+
+// HeadReader is the node API a Policy needs; *ethclient.Client satisfies it.
+type HeadReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Policy decides which blocks are final.
+type Policy struct {
+	// Confirmations is the number of blocks that must be built on a block
+	// before it is final. Zero makes the head final.
+	Confirmations uint64
+	// Finalized uses the node's finalized block tag instead of
+	// Confirmations.
+	Finalized bool
+}
+
+// ForProfile returns the policy configured in p.
+func ForProfile(p *config.Profile) Policy {
+	return Policy{Confirmations: p.Confirmations, Finalized: p.Finalized}
+}
+
+// FinalBlock returns the newest final block.
+func (p Policy) FinalBlock(ctx context.Context, c HeadReader) (uint64, error) {
+	var tag *big.Int
+	if p.Finalized {
+		tag = big.NewInt(int64(rpc.FinalizedBlockNumber))
+	}
+	h, err := c.HeaderByNumber(ctx, tag)
+	if err != nil {
+		return 0, fmt.Errorf("finality: final block: %w", err)
+	}
+	n := h.Number.Uint64()
+	if p.Finalized {
+		return n, nil
+	}
+	if n < p.Confirmations {
+		return 0, nil
+	}
+	return n - p.Confirmations, nil
+}
+
+// Mode selects when a Finalizer delivers events.
+type Mode uint8
+
+const (
+	// Fast delivers events immediately and retracts removed ones.
+	Fast Mode = iota
+	// Final delivers events once their block is final.
+	Final
+)
+
+// ParseMode parses "fast" or "final"; the empty string is Fast.
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "", "fast":
+		return Fast, nil
+	case "final":
+		return Final, nil
+	}
+	return Fast, fmt.Errorf("finality: unknown mode %q (want fast or final)", s)
+}
+
+func (m Mode) String() string {
+	if m == Final {
+		return "final"
+	}
+	return "fast"
+}
+
+// Update is one delivery of a Finalizer.
+type Update[T any] struct {
+	Event T
+	// Retracted marks an earlier delivery of Event that a reorg removed.
+	// Event is then the value originally delivered.
+	Retracted bool
+}
+
+// Config tunes a Finalizer.
+type Config struct {
+	Mode   Mode
+	Policy Policy
+	// PollInterval is how often Run re-reads the final block. Zero means
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// OnError receives failures to read the final block; Run retries them
+	// at the next poll.
+	OnError func(error)
+}
+
+type key struct {
+	block common.Hash
+	index uint
+}
+
+type entry[T any] struct {
+	v T
+	l types.Log
+}
+
+// Finalizer applies a Policy to a stream of events. Add and Advance may be
+// driven from a caller's own loop; Run drives them from a channel. A
+// Finalizer is not safe for concurrent use.
+type Finalizer[T any] struct {
+	head  HeadReader
+	logOf func(T) types.Log
+	cfg   Config
+	out   chan Update[T]
+
+	final     uint64
+	pending   []entry[T]
+	delivered map[key]entry[T]
+}
+
+// New returns a Finalizer reading the chain through head. logOf returns the
+// raw log of an event.
+func New[T any](head HeadReader, logOf func(T) types.Log, cfg Config) *Finalizer[T] {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	return &Finalizer[T]{
+		head:      head,
+		logOf:     logOf,
+		cfg:       cfg,
+		out:       make(chan Update[T]),
+		delivered: make(map[key]entry[T]),
+	}
+}
+
+// FinalBlock returns the final block as of the last Advance.
+func (f *Finalizer[T]) FinalBlock() uint64 {
+	return f.final
+}
+
+// Add takes in one event and returns the updates it makes deliverable.
+func (f *Finalizer[T]) Add(v T) []Update[T] {
+	l := f.logOf(v)
+	k := key{l.BlockHash, l.Index}
+	if l.Removed {
+		for i, e := range f.pending {
+			if e.l.BlockHash == l.BlockHash && e.l.Index == l.Index {
+				f.pending = append(f.pending[:i], f.pending[i+1:]...)
+				return nil
+			}
+		}
+		if e, ok := f.delivered[k]; ok {
+			delete(f.delivered, k)
+			return []Update[T]{{Event: e.v, Retracted: true}}
+		}
+		return nil
+	}
+	if _, ok := f.delivered[k]; ok {
+		return nil
+	}
+	e := entry[T]{v, l}
+	if f.cfg.Mode == Final && l.BlockNumber > f.final {
+		f.pending = append(f.pending, e)
+		return nil
+	}
+	f.delivered[k] = e
+	return []Update[T]{{Event: v}}
+}
+
+// Advance re-reads the final block and returns the buffered events that
+// became final, in block order.
+func (f *Finalizer[T]) Advance(ctx context.Context) ([]Update[T], error) {
+	final, err := f.cfg.Policy.FinalBlock(ctx, f.head)
+	if err != nil {
+		return nil, err
+	}
+	// The finalized tag of a lagging endpoint may step back; never undo.
+	f.final = max(f.final, final)
+
+	sort.SliceStable(f.pending, func(i, j int) bool {
+		a, b := f.pending[i].l, f.pending[j].l
+		if a.BlockNumber != b.BlockNumber {
+			return a.BlockNumber < b.BlockNumber
+		}
+		return a.Index < b.Index
+	})
+	n := sort.Search(len(f.pending), func(i int) bool { return f.pending[i].l.BlockNumber > f.final })
+	var out []Update[T]
+	for _, e := range f.pending[:n] {
+		f.delivered[key{e.l.BlockHash, e.l.Index}] = e
+		out = append(out, Update[T]{Event: e.v})
+	}
+	f.pending = append(f.pending[:0], f.pending[n:]...)
+	for k, e := range f.delivered {
+		if e.l.BlockNumber+retainDepth < f.final {
+			delete(f.delivered, k)
+		}
+	}
+	return out, nil
+}
+
+// C returns the channel Run delivers on. It is closed when Run returns.
+func (f *Finalizer[T]) C() <-chan Update[T] {
+	return f.out
+}
+
+// Run feeds the events of in through the Finalizer until in is closed or
+// ctx is done, re-reading the final block every PollInterval.
+func (f *Finalizer[T]) Run(ctx context.Context, in <-chan T) error {
+	defer close(f.out)
+	tick := time.NewTicker(f.cfg.PollInterval)
+	defer tick.Stop()
+	f.advance(ctx)
+	for {
+		var ups []Update[T]
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return nil
+			}
+			ups = f.Add(v)
+		case <-tick.C:
+			ups = f.advance(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for _, u := range ups {
+			select {
+			case f.out <- u:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (f *Finalizer[T]) advance(ctx context.Context) []Update[T] {
+	ups, err := f.Advance(ctx)
+	if err != nil && f.cfg.OnError != nil && ctx.Err() == nil {
+		f.cfg.OnError(err)
+	}
+	return ups
+}
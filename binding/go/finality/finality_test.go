@@ -0,0 +1,134 @@
+package finality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// chain reports head as the latest block and finalized for the tag.
+type chain struct {
+	head, finalized uint64
+}
+
+func (c *chain) HeaderByNumber(_ context.Context, n *big.Int) (*types.Header, error) {
+	switch {
+	case n == nil:
+		return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+	case n.Int64() == int64(rpc.FinalizedBlockNumber):
+		return &types.Header{Number: new(big.Int).SetUint64(c.finalized)}, nil
+	}
+	return &types.Header{Number: n}, nil
+}
+
+func logAt(block uint64, fork byte, index uint) types.Log {
+	return types.Log{BlockNumber: block, BlockHash: common.Hash{byte(block), fork}, Index: index}
+}
+
+func self(l types.Log) types.Log { return l }
+
+func blocks(ups []Update[types.Log]) []uint64 {
+	var out []uint64
+	for _, u := range ups {
+		out = append(out, u.Event.BlockNumber)
+	}
+	return out
+}
+
+func removed(l types.Log) types.Log {
+	l.Removed = true
+	return l
+}
+
+func TestPolicyFinalBlock(t *testing.T) {
+	c := &chain{head: 100, finalized: 64}
+	for _, tc := range []struct {
+		p    Policy
+		want uint64
+	}{
+		{Policy{}, 100},
+		{Policy{Confirmations: 12}, 88},
+		{Policy{Confirmations: 200}, 0},
+		{Policy{Confirmations: 12, Finalized: true}, 64},
+	} {
+		got, err := tc.p.FinalBlock(context.Background(), c)
+		if err != nil || got != tc.want {
+			t.Errorf("%+v: %d, %v; want %d", tc.p, got, err, tc.want)
+		}
+	}
+}
+
+func TestFinalModeBuffersUntilFinal(t *testing.T) {
+	c := &chain{head: 10}
+	f := New(c, self, Config{Mode: Final, Policy: Policy{Confirmations: 2}})
+	ctx := context.Background()
+	if _, err := f.Advance(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Block 8 is already final; 9 and 10 wait.
+	if got := blocks(f.Add(logAt(8, 0, 0))); len(got) != 1 {
+		t.Fatalf("final event not delivered: %v", got)
+	}
+	a, b := logAt(10, 0, 0), logAt(9, 0, 1)
+	if len(f.Add(a)) != 0 || len(f.Add(b)) != 0 {
+		t.Fatal("unconfirmed events delivered")
+	}
+
+	// A reorg removes block 10 before it is final; its replacement lands.
+	if len(f.Add(removed(a))) != 0 {
+		t.Fatal("removal of a buffered event was delivered")
+	}
+	f.Add(logAt(10, 1, 0))
+
+	c.head = 12
+	ups, err := f.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := blocks(ups); len(got) != 2 || got[0] != 9 || got[1] != 10 || ups[1].Event.BlockHash != (common.Hash{10, 1}) {
+		t.Fatalf("delivered %v", ups)
+	}
+
+	// A reorg deeper than the policy still retracts.
+	ups = f.Add(removed(logAt(9, 0, 1)))
+	if len(ups) != 1 || !ups[0].Retracted {
+		t.Fatalf("deep reorg: %+v", ups)
+	}
+}
+
+func TestFastModeRetracts(t *testing.T) {
+	f := New(&chain{}, self, Config{Mode: Fast})
+	l := logAt(5, 0, 3)
+	if ups := f.Add(l); len(ups) != 1 || ups[0].Retracted {
+		t.Fatalf("add: %+v", ups)
+	}
+	if ups := f.Add(l); len(ups) != 0 {
+		t.Fatal("duplicate delivered")
+	}
+	if ups := f.Add(removed(l)); len(ups) != 1 || !ups[0].Retracted {
+		t.Fatalf("remove: %+v", ups)
+	}
+	if ups := f.Add(removed(l)); len(ups) != 0 {
+		t.Fatal("retracted twice")
+	}
+	// The same log re-included in the new block is delivered again.
+	if ups := f.Add(logAt(5, 1, 3)); len(ups) != 1 {
+		t.Fatal("re-included log not delivered")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": Fast, "fast": Fast, "final": Final} {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseMode("safe"); err == nil {
+		t.Error("ParseMode accepted safe")
+	}
+}
@@ -0,0 +1,1139 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: cgr/v1/reward.proto
+
+package cgrv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PoolStatus int32
+
+const (
+	PoolStatus_POOL_STATUS_UNSPECIFIED PoolStatus = 0
+	PoolStatus_POOL_STATUS_ACTIVE      PoolStatus = 1
+	PoolStatus_POOL_STATUS_INACTIVE    PoolStatus = 2
+	PoolStatus_POOL_STATUS_PAUSED      PoolStatus = 3
+)
+
+// Enum value maps for PoolStatus.
+var (
+	PoolStatus_name = map[int32]string{
+		0: "POOL_STATUS_UNSPECIFIED",
+		1: "POOL_STATUS_ACTIVE",
+		2: "POOL_STATUS_INACTIVE",
+		3: "POOL_STATUS_PAUSED",
+	}
+	PoolStatus_value = map[string]int32{
+		"POOL_STATUS_UNSPECIFIED": 0,
+		"POOL_STATUS_ACTIVE":      1,
+		"POOL_STATUS_INACTIVE":    2,
+		"POOL_STATUS_PAUSED":      3,
+	}
+)
+
+func (x PoolStatus) Enum() *PoolStatus {
+	p := new(PoolStatus)
+	*p = x
+	return p
+}
+
+func (x PoolStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PoolStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_cgr_v1_reward_proto_enumTypes[0].Descriptor()
+}
+
+func (PoolStatus) Type() protoreflect.EnumType {
+	return &file_cgr_v1_reward_proto_enumTypes[0]
+}
+
+func (x PoolStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PoolStatus.Descriptor instead.
+func (PoolStatus) EnumDescriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{0}
+}
+
+type PlayerUpdate_Reason int32
+
+const (
+	PlayerUpdate_REASON_UNSPECIFIED      PlayerUpdate_Reason = 0
+	PlayerUpdate_REASON_DEPOSITED        PlayerUpdate_Reason = 1
+	PlayerUpdate_REASON_WITHDRAWN        PlayerUpdate_Reason = 2
+	PlayerUpdate_REASON_REWARD_CLAIMED   PlayerUpdate_Reason = 3
+	PlayerUpdate_REASON_DEPOSITED_NATIVE PlayerUpdate_Reason = 4
+	PlayerUpdate_REASON_WITHDRAWN_NATIVE PlayerUpdate_Reason = 5
+	// A reward sync changed the player's pending amount of token.
+	PlayerUpdate_REASON_REWARD_SYNCED PlayerUpdate_Reason = 6
+)
+
+// Enum value maps for PlayerUpdate_Reason.
+var (
+	PlayerUpdate_Reason_name = map[int32]string{
+		0: "REASON_UNSPECIFIED",
+		1: "REASON_DEPOSITED",
+		2: "REASON_WITHDRAWN",
+		3: "REASON_REWARD_CLAIMED",
+		4: "REASON_DEPOSITED_NATIVE",
+		5: "REASON_WITHDRAWN_NATIVE",
+		6: "REASON_REWARD_SYNCED",
+	}
+	PlayerUpdate_Reason_value = map[string]int32{
+		"REASON_UNSPECIFIED":      0,
+		"REASON_DEPOSITED":        1,
+		"REASON_WITHDRAWN":        2,
+		"REASON_REWARD_CLAIMED":   3,
+		"REASON_DEPOSITED_NATIVE": 4,
+		"REASON_WITHDRAWN_NATIVE": 5,
+		"REASON_REWARD_SYNCED":    6,
+	}
+)
+
+func (x PlayerUpdate_Reason) Enum() *PlayerUpdate_Reason {
+	p := new(PlayerUpdate_Reason)
+	*p = x
+	return p
+}
+
+func (x PlayerUpdate_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlayerUpdate_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_cgr_v1_reward_proto_enumTypes[1].Descriptor()
+}
+
+func (PlayerUpdate_Reason) Type() protoreflect.EnumType {
+	return &file_cgr_v1_reward_proto_enumTypes[1]
+}
+
+func (x PlayerUpdate_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlayerUpdate_Reason.Descriptor instead.
+func (PlayerUpdate_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type TokenAmount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Amount        string                 `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenAmount) Reset() {
+	*x = TokenAmount{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenAmount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenAmount) ProtoMessage() {}
+
+func (x *TokenAmount) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenAmount.ProtoReflect.Descriptor instead.
+func (*TokenAmount) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenAmount) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TokenAmount) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type GetUserDepositInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoolId        string                 `protobuf:"bytes,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Block         uint64                 `protobuf:"varint,3,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserDepositInfoRequest) Reset() {
+	*x = GetUserDepositInfoRequest{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserDepositInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserDepositInfoRequest) ProtoMessage() {}
+
+func (x *GetUserDepositInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserDepositInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetUserDepositInfoRequest) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetUserDepositInfoRequest) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *GetUserDepositInfoRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GetUserDepositInfoRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type GetUserDepositInfoResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Block           uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	DepositedAmount string                 `protobuf:"bytes,2,opt,name=deposited_amount,json=depositedAmount,proto3" json:"deposited_amount,omitempty"`
+	// Pending rewards of every active reward token.
+	PendingRewards []*TokenAmount `protobuf:"bytes,3,rep,name=pending_rewards,json=pendingRewards,proto3" json:"pending_rewards,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetUserDepositInfoResponse) Reset() {
+	*x = GetUserDepositInfoResponse{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserDepositInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserDepositInfoResponse) ProtoMessage() {}
+
+func (x *GetUserDepositInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserDepositInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetUserDepositInfoResponse) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetUserDepositInfoResponse) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *GetUserDepositInfoResponse) GetDepositedAmount() string {
+	if x != nil {
+		return x.DepositedAmount
+	}
+	return ""
+}
+
+func (x *GetUserDepositInfoResponse) GetPendingRewards() []*TokenAmount {
+	if x != nil {
+		return x.PendingRewards
+	}
+	return nil
+}
+
+type GetAllPendingRewardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoolId        string                 `protobuf:"bytes,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Block         uint64                 `protobuf:"varint,3,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllPendingRewardsRequest) Reset() {
+	*x = GetAllPendingRewardsRequest{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllPendingRewardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllPendingRewardsRequest) ProtoMessage() {}
+
+func (x *GetAllPendingRewardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllPendingRewardsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllPendingRewardsRequest) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAllPendingRewardsRequest) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *GetAllPendingRewardsRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GetAllPendingRewardsRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type GetAllPendingRewardsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Block          uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	PendingRewards []*TokenAmount         `protobuf:"bytes,2,rep,name=pending_rewards,json=pendingRewards,proto3" json:"pending_rewards,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetAllPendingRewardsResponse) Reset() {
+	*x = GetAllPendingRewardsResponse{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllPendingRewardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllPendingRewardsResponse) ProtoMessage() {}
+
+func (x *GetAllPendingRewardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllPendingRewardsResponse.ProtoReflect.Descriptor instead.
+func (*GetAllPendingRewardsResponse) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetAllPendingRewardsResponse) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *GetAllPendingRewardsResponse) GetPendingRewards() []*TokenAmount {
+	if x != nil {
+		return x.PendingRewards
+	}
+	return nil
+}
+
+type PoolInfo struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	PoolId              string                 `protobuf:"bytes,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	Pool                string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"`
+	Name                string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	DepositToken        string                 `protobuf:"bytes,4,opt,name=deposit_token,json=depositToken,proto3" json:"deposit_token,omitempty"`
+	CreatedAt           string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Status              PoolStatus             `protobuf:"varint,6,opt,name=status,proto3,enum=cgr.v1.PoolStatus" json:"status,omitempty"`
+	Paused              bool                   `protobuf:"varint,7,opt,name=paused,proto3" json:"paused,omitempty"`
+	TotalDeposited      string                 `protobuf:"bytes,8,opt,name=total_deposited,json=totalDeposited,proto3" json:"total_deposited,omitempty"`
+	MinDepositAmount    string                 `protobuf:"bytes,9,opt,name=min_deposit_amount,json=minDepositAmount,proto3" json:"min_deposit_amount,omitempty"`
+	RewardTokens        []string               `protobuf:"bytes,10,rep,name=reward_tokens,json=rewardTokens,proto3" json:"reward_tokens,omitempty"`
+	RemovedRewardTokens []string               `protobuf:"bytes,11,rep,name=removed_reward_tokens,json=removedRewardTokens,proto3" json:"removed_reward_tokens,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PoolInfo) Reset() {
+	*x = PoolInfo{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PoolInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoolInfo) ProtoMessage() {}
+
+func (x *PoolInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoolInfo.ProtoReflect.Descriptor instead.
+func (*PoolInfo) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PoolInfo) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetDepositToken() string {
+	if x != nil {
+		return x.DepositToken
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetStatus() PoolStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PoolStatus_POOL_STATUS_UNSPECIFIED
+}
+
+func (x *PoolInfo) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *PoolInfo) GetTotalDeposited() string {
+	if x != nil {
+		return x.TotalDeposited
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetMinDepositAmount() string {
+	if x != nil {
+		return x.MinDepositAmount
+	}
+	return ""
+}
+
+func (x *PoolInfo) GetRewardTokens() []string {
+	if x != nil {
+		return x.RewardTokens
+	}
+	return nil
+}
+
+func (x *PoolInfo) GetRemovedRewardTokens() []string {
+	if x != nil {
+		return x.RemovedRewardTokens
+	}
+	return nil
+}
+
+type GetPoolInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PoolId        string                 `protobuf:"bytes,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	Block         uint64                 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPoolInfoRequest) Reset() {
+	*x = GetPoolInfoRequest{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPoolInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPoolInfoRequest) ProtoMessage() {}
+
+func (x *GetPoolInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPoolInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetPoolInfoRequest) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPoolInfoRequest) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *GetPoolInfoRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type GetPoolInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	Pool          *PoolInfo              `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPoolInfoResponse) Reset() {
+	*x = GetPoolInfoResponse{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPoolInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPoolInfoResponse) ProtoMessage() {}
+
+func (x *GetPoolInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPoolInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetPoolInfoResponse) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPoolInfoResponse) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *GetPoolInfoResponse) GetPool() *PoolInfo {
+	if x != nil {
+		return x.Pool
+	}
+	return nil
+}
+
+type GetActivePoolIdsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActivePoolIdsRequest) Reset() {
+	*x = GetActivePoolIdsRequest{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActivePoolIdsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActivePoolIdsRequest) ProtoMessage() {}
+
+func (x *GetActivePoolIdsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActivePoolIdsRequest.ProtoReflect.Descriptor instead.
+func (*GetActivePoolIdsRequest) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetActivePoolIdsRequest) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+type GetActivePoolIdsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Block         uint64                 `protobuf:"varint,1,opt,name=block,proto3" json:"block,omitempty"`
+	PoolIds       []string               `protobuf:"bytes,2,rep,name=pool_ids,json=poolIds,proto3" json:"pool_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActivePoolIdsResponse) Reset() {
+	*x = GetActivePoolIdsResponse{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActivePoolIdsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActivePoolIdsResponse) ProtoMessage() {}
+
+func (x *GetActivePoolIdsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActivePoolIdsResponse.ProtoReflect.Descriptor instead.
+func (*GetActivePoolIdsResponse) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetActivePoolIdsResponse) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *GetActivePoolIdsResponse) GetPoolIds() []string {
+	if x != nil {
+		return x.PoolIds
+	}
+	return nil
+}
+
+type WatchPlayerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchPlayerRequest) Reset() {
+	*x = WatchPlayerRequest{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchPlayerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPlayerRequest) ProtoMessage() {}
+
+func (x *WatchPlayerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPlayerRequest.ProtoReflect.Descriptor instead.
+func (*WatchPlayerRequest) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchPlayerRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type Position struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	PoolId       string                 `protobuf:"bytes,1,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	Pool         string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"`
+	DepositToken string                 `protobuf:"bytes,3,opt,name=deposit_token,json=depositToken,proto3" json:"deposit_token,omitempty"`
+	Deposited    string                 `protobuf:"bytes,4,opt,name=deposited,proto3" json:"deposited,omitempty"`
+	// Pending rewards of every active reward token.
+	Pending []*TokenAmount `protobuf:"bytes,5,rep,name=pending,proto3" json:"pending,omitempty"`
+	// Non-zero rewards of removed reward tokens.
+	RemovedPending []*TokenAmount `protobuf:"bytes,6,rep,name=removed_pending,json=removedPending,proto3" json:"removed_pending,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Position) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *Position) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *Position) GetDepositToken() string {
+	if x != nil {
+		return x.DepositToken
+	}
+	return ""
+}
+
+func (x *Position) GetDeposited() string {
+	if x != nil {
+		return x.Deposited
+	}
+	return ""
+}
+
+func (x *Position) GetPending() []*TokenAmount {
+	if x != nil {
+		return x.Pending
+	}
+	return nil
+}
+
+func (x *Position) GetRemovedPending() []*TokenAmount {
+	if x != nil {
+		return x.RemovedPending
+	}
+	return nil
+}
+
+type PlayerUpdate struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Reason   PlayerUpdate_Reason    `protobuf:"varint,1,opt,name=reason,proto3,enum=cgr.v1.PlayerUpdate_Reason" json:"reason,omitempty"`
+	Block    uint64                 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	TxHash   string                 `protobuf:"bytes,3,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	LogIndex uint32                 `protobuf:"varint,4,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`
+	// amount of the triggering event; for REASON_REWARD_SYNCED the player's
+	// new pending amount of token.
+	Amount string `protobuf:"bytes,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Reward token for REASON_REWARD_CLAIMED and REASON_REWARD_SYNCED.
+	Token string `protobuf:"bytes,6,opt,name=token,proto3" json:"token,omitempty"`
+	// The player's position in the pool at the end of block.
+	Position      *Position `protobuf:"bytes,7,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayerUpdate) Reset() {
+	*x = PlayerUpdate{}
+	mi := &file_cgr_v1_reward_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayerUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayerUpdate) ProtoMessage() {}
+
+func (x *PlayerUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_cgr_v1_reward_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayerUpdate.ProtoReflect.Descriptor instead.
+func (*PlayerUpdate) Descriptor() ([]byte, []int) {
+	return file_cgr_v1_reward_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PlayerUpdate) GetReason() PlayerUpdate_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return PlayerUpdate_REASON_UNSPECIFIED
+}
+
+func (x *PlayerUpdate) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *PlayerUpdate) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetLogIndex() uint32 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *PlayerUpdate) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PlayerUpdate) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+var File_cgr_v1_reward_proto protoreflect.FileDescriptor
+
+const file_cgr_v1_reward_proto_rawDesc = "" +
+	"\n" +
+	"\x13cgr/v1/reward.proto\x12\x06cgr.v1\";\n" +
+	"\vTokenAmount\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\tR\x06amount\"^\n" +
+	"\x19GetUserDepositInfoRequest\x12\x17\n" +
+	"\apool_id\x18\x01 \x01(\tR\x06poolId\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x14\n" +
+	"\x05block\x18\x03 \x01(\x04R\x05block\"\x9b\x01\n" +
+	"\x1aGetUserDepositInfoResponse\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\x12)\n" +
+	"\x10deposited_amount\x18\x02 \x01(\tR\x0fdepositedAmount\x12<\n" +
+	"\x0fpending_rewards\x18\x03 \x03(\v2\x13.cgr.v1.TokenAmountR\x0ependingRewards\"`\n" +
+	"\x1bGetAllPendingRewardsRequest\x12\x17\n" +
+	"\apool_id\x18\x01 \x01(\tR\x06poolId\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x14\n" +
+	"\x05block\x18\x03 \x01(\x04R\x05block\"r\n" +
+	"\x1cGetAllPendingRewardsResponse\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\x12<\n" +
+	"\x0fpending_rewards\x18\x02 \x03(\v2\x13.cgr.v1.TokenAmountR\x0ependingRewards\"\x83\x03\n" +
+	"\bPoolInfo\x12\x17\n" +
+	"\apool_id\x18\x01 \x01(\tR\x06poolId\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12#\n" +
+	"\rdeposit_token\x18\x04 \x01(\tR\fdepositToken\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\x12*\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x12.cgr.v1.PoolStatusR\x06status\x12\x16\n" +
+	"\x06paused\x18\a \x01(\bR\x06paused\x12'\n" +
+	"\x0ftotal_deposited\x18\b \x01(\tR\x0etotalDeposited\x12,\n" +
+	"\x12min_deposit_amount\x18\t \x01(\tR\x10minDepositAmount\x12#\n" +
+	"\rreward_tokens\x18\n" +
+	" \x03(\tR\frewardTokens\x122\n" +
+	"\x15removed_reward_tokens\x18\v \x03(\tR\x13removedRewardTokens\"C\n" +
+	"\x12GetPoolInfoRequest\x12\x17\n" +
+	"\apool_id\x18\x01 \x01(\tR\x06poolId\x12\x14\n" +
+	"\x05block\x18\x02 \x01(\x04R\x05block\"Q\n" +
+	"\x13GetPoolInfoResponse\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\x12$\n" +
+	"\x04pool\x18\x02 \x01(\v2\x10.cgr.v1.PoolInfoR\x04pool\"/\n" +
+	"\x17GetActivePoolIdsRequest\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\"K\n" +
+	"\x18GetActivePoolIdsResponse\x12\x14\n" +
+	"\x05block\x18\x01 \x01(\x04R\x05block\x12\x19\n" +
+	"\bpool_ids\x18\x02 \x03(\tR\apoolIds\"(\n" +
+	"\x12WatchPlayerRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\"\xe7\x01\n" +
+	"\bPosition\x12\x17\n" +
+	"\apool_id\x18\x01 \x01(\tR\x06poolId\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\x12#\n" +
+	"\rdeposit_token\x18\x03 \x01(\tR\fdepositToken\x12\x1c\n" +
+	"\tdeposited\x18\x04 \x01(\tR\tdeposited\x12-\n" +
+	"\apending\x18\x05 \x03(\v2\x13.cgr.v1.TokenAmountR\apending\x12<\n" +
+	"\x0fremoved_pending\x18\x06 \x03(\v2\x13.cgr.v1.TokenAmountR\x0eremovedPending\"\xa9\x03\n" +
+	"\fPlayerUpdate\x123\n" +
+	"\x06reason\x18\x01 \x01(\x0e2\x1b.cgr.v1.PlayerUpdate.ReasonR\x06reason\x12\x14\n" +
+	"\x05block\x18\x02 \x01(\x04R\x05block\x12\x17\n" +
+	"\atx_hash\x18\x03 \x01(\tR\x06txHash\x12\x1b\n" +
+	"\tlog_index\x18\x04 \x01(\rR\blogIndex\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\tR\x06amount\x12\x14\n" +
+	"\x05token\x18\x06 \x01(\tR\x05token\x12,\n" +
+	"\bposition\x18\a \x01(\v2\x10.cgr.v1.PositionR\bposition\"\xbb\x01\n" +
+	"\x06Reason\x12\x16\n" +
+	"\x12REASON_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10REASON_DEPOSITED\x10\x01\x12\x14\n" +
+	"\x10REASON_WITHDRAWN\x10\x02\x12\x19\n" +
+	"\x15REASON_REWARD_CLAIMED\x10\x03\x12\x1b\n" +
+	"\x17REASON_DEPOSITED_NATIVE\x10\x04\x12\x1b\n" +
+	"\x17REASON_WITHDRAWN_NATIVE\x10\x05\x12\x18\n" +
+	"\x14REASON_REWARD_SYNCED\x10\x06*s\n" +
+	"\n" +
+	"PoolStatus\x12\x1b\n" +
+	"\x17POOL_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12POOL_STATUS_ACTIVE\x10\x01\x12\x18\n" +
+	"\x14POOL_STATUS_INACTIVE\x10\x02\x12\x16\n" +
+	"\x12POOL_STATUS_PAUSED\x10\x032\xb1\x03\n" +
+	"\rRewardService\x12[\n" +
+	"\x12GetUserDepositInfo\x12!.cgr.v1.GetUserDepositInfoRequest\x1a\".cgr.v1.GetUserDepositInfoResponse\x12a\n" +
+	"\x14GetAllPendingRewards\x12#.cgr.v1.GetAllPendingRewardsRequest\x1a$.cgr.v1.GetAllPendingRewardsResponse\x12F\n" +
+	"\vGetPoolInfo\x12\x1a.cgr.v1.GetPoolInfoRequest\x1a\x1b.cgr.v1.GetPoolInfoResponse\x12U\n" +
+	"\x10GetActivePoolIds\x12\x1f.cgr.v1.GetActivePoolIdsRequest\x1a .cgr.v1.GetActivePoolIdsResponse\x12A\n" +
+	"\vWatchPlayer\x12\x1a.cgr.v1.WatchPlayerRequest\x1a\x14.cgr.v1.PlayerUpdate0\x01BFZDgithub.com/to-nexus/cross-game-reward/binding/go/grpcapi/cgrv1;cgrv1b\x06proto3"
+
+var (
+	file_cgr_v1_reward_proto_rawDescOnce sync.Once
+	file_cgr_v1_reward_proto_rawDescData []byte
+)
+
+func file_cgr_v1_reward_proto_rawDescGZIP() []byte {
+	file_cgr_v1_reward_proto_rawDescOnce.Do(func() {
+		file_cgr_v1_reward_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cgr_v1_reward_proto_rawDesc), len(file_cgr_v1_reward_proto_rawDesc)))
+	})
+	return file_cgr_v1_reward_proto_rawDescData
+}
+
+var file_cgr_v1_reward_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_cgr_v1_reward_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_cgr_v1_reward_proto_goTypes = []any{
+	(PoolStatus)(0),                      // 0: cgr.v1.PoolStatus
+	(PlayerUpdate_Reason)(0),             // 1: cgr.v1.PlayerUpdate.Reason
+	(*TokenAmount)(nil),                  // 2: cgr.v1.TokenAmount
+	(*GetUserDepositInfoRequest)(nil),    // 3: cgr.v1.GetUserDepositInfoRequest
+	(*GetUserDepositInfoResponse)(nil),   // 4: cgr.v1.GetUserDepositInfoResponse
+	(*GetAllPendingRewardsRequest)(nil),  // 5: cgr.v1.GetAllPendingRewardsRequest
+	(*GetAllPendingRewardsResponse)(nil), // 6: cgr.v1.GetAllPendingRewardsResponse
+	(*PoolInfo)(nil),                     // 7: cgr.v1.PoolInfo
+	(*GetPoolInfoRequest)(nil),           // 8: cgr.v1.GetPoolInfoRequest
+	(*GetPoolInfoResponse)(nil),          // 9: cgr.v1.GetPoolInfoResponse
+	(*GetActivePoolIdsRequest)(nil),      // 10: cgr.v1.GetActivePoolIdsRequest
+	(*GetActivePoolIdsResponse)(nil),     // 11: cgr.v1.GetActivePoolIdsResponse
+	(*WatchPlayerRequest)(nil),           // 12: cgr.v1.WatchPlayerRequest
+	(*Position)(nil),                     // 13: cgr.v1.Position
+	(*PlayerUpdate)(nil),                 // 14: cgr.v1.PlayerUpdate
+}
+var file_cgr_v1_reward_proto_depIdxs = []int32{
+	2,  // 0: cgr.v1.GetUserDepositInfoResponse.pending_rewards:type_name -> cgr.v1.TokenAmount
+	2,  // 1: cgr.v1.GetAllPendingRewardsResponse.pending_rewards:type_name -> cgr.v1.TokenAmount
+	0,  // 2: cgr.v1.PoolInfo.status:type_name -> cgr.v1.PoolStatus
+	7,  // 3: cgr.v1.GetPoolInfoResponse.pool:type_name -> cgr.v1.PoolInfo
+	2,  // 4: cgr.v1.Position.pending:type_name -> cgr.v1.TokenAmount
+	2,  // 5: cgr.v1.Position.removed_pending:type_name -> cgr.v1.TokenAmount
+	1,  // 6: cgr.v1.PlayerUpdate.reason:type_name -> cgr.v1.PlayerUpdate.Reason
+	13, // 7: cgr.v1.PlayerUpdate.position:type_name -> cgr.v1.Position
+	3,  // 8: cgr.v1.RewardService.GetUserDepositInfo:input_type -> cgr.v1.GetUserDepositInfoRequest
+	5,  // 9: cgr.v1.RewardService.GetAllPendingRewards:input_type -> cgr.v1.GetAllPendingRewardsRequest
+	8,  // 10: cgr.v1.RewardService.GetPoolInfo:input_type -> cgr.v1.GetPoolInfoRequest
+	10, // 11: cgr.v1.RewardService.GetActivePoolIds:input_type -> cgr.v1.GetActivePoolIdsRequest
+	12, // 12: cgr.v1.RewardService.WatchPlayer:input_type -> cgr.v1.WatchPlayerRequest
+	4,  // 13: cgr.v1.RewardService.GetUserDepositInfo:output_type -> cgr.v1.GetUserDepositInfoResponse
+	6,  // 14: cgr.v1.RewardService.GetAllPendingRewards:output_type -> cgr.v1.GetAllPendingRewardsResponse
+	9,  // 15: cgr.v1.RewardService.GetPoolInfo:output_type -> cgr.v1.GetPoolInfoResponse
+	11, // 16: cgr.v1.RewardService.GetActivePoolIds:output_type -> cgr.v1.GetActivePoolIdsResponse
+	14, // 17: cgr.v1.RewardService.WatchPlayer:output_type -> cgr.v1.PlayerUpdate
+	13, // [13:18] is the sub-list for method output_type
+	8,  // [8:13] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_cgr_v1_reward_proto_init() }
+func file_cgr_v1_reward_proto_init() {
+	if File_cgr_v1_reward_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cgr_v1_reward_proto_rawDesc), len(file_cgr_v1_reward_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cgr_v1_reward_proto_goTypes,
+		DependencyIndexes: file_cgr_v1_reward_proto_depIdxs,
+		EnumInfos:         file_cgr_v1_reward_proto_enumTypes,
+		MessageInfos:      file_cgr_v1_reward_proto_msgTypes,
+	}.Build()
+	File_cgr_v1_reward_proto = out.File
+	file_cgr_v1_reward_proto_goTypes = nil
+	file_cgr_v1_reward_proto_depIdxs = nil
+}
@@ -0,0 +1,301 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: cgr/v1/reward.proto
+
+package cgrv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RewardService_GetUserDepositInfo_FullMethodName   = "/cgr.v1.RewardService/GetUserDepositInfo"
+	RewardService_GetAllPendingRewards_FullMethodName = "/cgr.v1.RewardService/GetAllPendingRewards"
+	RewardService_GetPoolInfo_FullMethodName          = "/cgr.v1.RewardService/GetPoolInfo"
+	RewardService_GetActivePoolIds_FullMethodName     = "/cgr.v1.RewardService/GetActivePoolIds"
+	RewardService_WatchPlayer_FullMethodName          = "/cgr.v1.RewardService/WatchPlayer"
+)
+
+// RewardServiceClient is the client API for RewardService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RewardService exposes pools and player positions to game servers.
+//
+// Amounts and pool IDs are uint256 values encoded as decimal strings;
+// addresses are 0x-prefixed hex. Requests with block = 0 read the latest
+// block; every response reports the block it was read at.
+type RewardServiceClient interface {
+	// GetUserDepositInfo mirrors the router's getUserDepositInfo.
+	GetUserDepositInfo(ctx context.Context, in *GetUserDepositInfoRequest, opts ...grpc.CallOption) (*GetUserDepositInfoResponse, error)
+	// GetAllPendingRewards mirrors the router's getAllPendingRewards: non-zero
+	// rewards of active and removed tokens.
+	GetAllPendingRewards(ctx context.Context, in *GetAllPendingRewardsRequest, opts ...grpc.CallOption) (*GetAllPendingRewardsResponse, error)
+	// GetPoolInfo mirrors the factory's getPoolInfo, extended with live state.
+	GetPoolInfo(ctx context.Context, in *GetPoolInfoRequest, opts ...grpc.CallOption) (*GetPoolInfoResponse, error)
+	// GetActivePoolIds mirrors the factory's getActivePoolIds.
+	GetActivePoolIds(ctx context.Context, in *GetActivePoolIdsRequest, opts ...grpc.CallOption) (*GetActivePoolIdsResponse, error)
+	// WatchPlayer streams an update whenever the player's position changes.
+	WatchPlayer(ctx context.Context, in *WatchPlayerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlayerUpdate], error)
+}
+
+type rewardServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRewardServiceClient(cc grpc.ClientConnInterface) RewardServiceClient {
+	return &rewardServiceClient{cc}
+}
+
+func (c *rewardServiceClient) GetUserDepositInfo(ctx context.Context, in *GetUserDepositInfoRequest, opts ...grpc.CallOption) (*GetUserDepositInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserDepositInfoResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetUserDepositInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) GetAllPendingRewards(ctx context.Context, in *GetAllPendingRewardsRequest, opts ...grpc.CallOption) (*GetAllPendingRewardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAllPendingRewardsResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetAllPendingRewards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) GetPoolInfo(ctx context.Context, in *GetPoolInfoRequest, opts ...grpc.CallOption) (*GetPoolInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPoolInfoResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetPoolInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) GetActivePoolIds(ctx context.Context, in *GetActivePoolIdsRequest, opts ...grpc.CallOption) (*GetActivePoolIdsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetActivePoolIdsResponse)
+	err := c.cc.Invoke(ctx, RewardService_GetActivePoolIds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rewardServiceClient) WatchPlayer(ctx context.Context, in *WatchPlayerRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlayerUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RewardService_ServiceDesc.Streams[0], RewardService_WatchPlayer_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchPlayerRequest, PlayerUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RewardService_WatchPlayerClient = grpc.ServerStreamingClient[PlayerUpdate]
+
+// RewardServiceServer is the server API for RewardService service.
+// All implementations must embed UnimplementedRewardServiceServer
+// for forward compatibility.
+//
+// RewardService exposes pools and player positions to game servers.
+//
+// Amounts and pool IDs are uint256 values encoded as decimal strings;
+// addresses are 0x-prefixed hex. Requests with block = 0 read the latest
+// block; every response reports the block it was read at.
+type RewardServiceServer interface {
+	// GetUserDepositInfo mirrors the router's getUserDepositInfo.
+	GetUserDepositInfo(context.Context, *GetUserDepositInfoRequest) (*GetUserDepositInfoResponse, error)
+	// GetAllPendingRewards mirrors the router's getAllPendingRewards: non-zero
+	// rewards of active and removed tokens.
+	GetAllPendingRewards(context.Context, *GetAllPendingRewardsRequest) (*GetAllPendingRewardsResponse, error)
+	// GetPoolInfo mirrors the factory's getPoolInfo, extended with live state.
+	GetPoolInfo(context.Context, *GetPoolInfoRequest) (*GetPoolInfoResponse, error)
+	// GetActivePoolIds mirrors the factory's getActivePoolIds.
+	GetActivePoolIds(context.Context, *GetActivePoolIdsRequest) (*GetActivePoolIdsResponse, error)
+	// WatchPlayer streams an update whenever the player's position changes.
+	WatchPlayer(*WatchPlayerRequest, grpc.ServerStreamingServer[PlayerUpdate]) error
+	mustEmbedUnimplementedRewardServiceServer()
+}
+
+// UnimplementedRewardServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRewardServiceServer struct{}
+
+func (UnimplementedRewardServiceServer) GetUserDepositInfo(context.Context, *GetUserDepositInfoRequest) (*GetUserDepositInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserDepositInfo not implemented")
+}
+func (UnimplementedRewardServiceServer) GetAllPendingRewards(context.Context, *GetAllPendingRewardsRequest) (*GetAllPendingRewardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllPendingRewards not implemented")
+}
+func (UnimplementedRewardServiceServer) GetPoolInfo(context.Context, *GetPoolInfoRequest) (*GetPoolInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPoolInfo not implemented")
+}
+func (UnimplementedRewardServiceServer) GetActivePoolIds(context.Context, *GetActivePoolIdsRequest) (*GetActivePoolIdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActivePoolIds not implemented")
+}
+func (UnimplementedRewardServiceServer) WatchPlayer(*WatchPlayerRequest, grpc.ServerStreamingServer[PlayerUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPlayer not implemented")
+}
+func (UnimplementedRewardServiceServer) mustEmbedUnimplementedRewardServiceServer() {}
+func (UnimplementedRewardServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeRewardServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RewardServiceServer will
+// result in compilation errors.
+type UnsafeRewardServiceServer interface {
+	mustEmbedUnimplementedRewardServiceServer()
+}
+
+func RegisterRewardServiceServer(s grpc.ServiceRegistrar, srv RewardServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRewardServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RewardService_ServiceDesc, srv)
+}
+
+func _RewardService_GetUserDepositInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserDepositInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetUserDepositInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetUserDepositInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetUserDepositInfo(ctx, req.(*GetUserDepositInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_GetAllPendingRewards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllPendingRewardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetAllPendingRewards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetAllPendingRewards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetAllPendingRewards(ctx, req.(*GetAllPendingRewardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_GetPoolInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPoolInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetPoolInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetPoolInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetPoolInfo(ctx, req.(*GetPoolInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_GetActivePoolIds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActivePoolIdsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RewardServiceServer).GetActivePoolIds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RewardService_GetActivePoolIds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RewardServiceServer).GetActivePoolIds(ctx, req.(*GetActivePoolIdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RewardService_WatchPlayer_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPlayerRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RewardServiceServer).WatchPlayer(m, &grpc.GenericServerStream[WatchPlayerRequest, PlayerUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RewardService_WatchPlayerServer = grpc.ServerStreamingServer[PlayerUpdate]
+
+// RewardService_ServiceDesc is the grpc.ServiceDesc for RewardService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RewardService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cgr.v1.RewardService",
+	HandlerType: (*RewardServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUserDepositInfo",
+			Handler:    _RewardService_GetUserDepositInfo_Handler,
+		},
+		{
+			MethodName: "GetAllPendingRewards",
+			Handler:    _RewardService_GetAllPendingRewards_Handler,
+		},
+		{
+			MethodName: "GetPoolInfo",
+			Handler:    _RewardService_GetPoolInfo_Handler,
+		},
+		{
+			MethodName: "GetActivePoolIds",
+			Handler:    _RewardService_GetActivePoolIds_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPlayer",
+			Handler:       _RewardService_WatchPlayer_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "cgr/v1/reward.proto",
+}
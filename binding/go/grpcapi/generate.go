@@ -0,0 +1,3 @@
+package grpcapi
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=github.com/to-nexus/cross-game-reward/binding/go/grpcapi --go-grpc_out=. --go-grpc_opt=module=github.com/to-nexus/cross-game-reward/binding/go/grpcapi cgr/v1/reward.proto
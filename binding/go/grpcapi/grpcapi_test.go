@@ -0,0 +1,191 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/grpcapi/cgrv1"
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/testlog"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	poolAddr   = common.HexToAddress("0xb0")
+	routerAddr = common.HexToAddress("0xc0")
+	player     = common.HexToAddress("0xa0")
+	other      = common.HexToAddress("0xa1")
+	tokenAddr  = common.HexToAddress("0x70")
+)
+
+func TestDecode(t *testing.T) {
+	d, err := newDecoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, router := binding.CrossGameRewardPoolMetaData, binding.CrossGameRewardRouterMetaData
+	one := common.BigToHash(big.NewInt(1))
+	for _, tc := range []struct {
+		name   string
+		meta   testlog.ABIProvider
+		addr   common.Address
+		event  string
+		topics []common.Hash
+		data   []any
+		reason cgrv1.PlayerUpdate_Reason
+		match  bool
+	}{
+		{"deposit", pool, poolAddr, "Deposited", []common.Hash{testlog.Topic(player)}, []any{big.NewInt(5)},
+			cgrv1.PlayerUpdate_REASON_DEPOSITED, true},
+		{"other player's withdrawal", pool, poolAddr, "Withdrawn", []common.Hash{testlog.Topic(other)}, []any{big.NewInt(5)},
+			cgrv1.PlayerUpdate_REASON_WITHDRAWN, false},
+		{"claim", pool, poolAddr, "RewardClaimed", []common.Hash{testlog.Topic(player), testlog.Topic(tokenAddr)}, []any{big.NewInt(5)},
+			cgrv1.PlayerUpdate_REASON_REWARD_CLAIMED, true},
+		{"sync", pool, poolAddr, "RewardSynced", []common.Hash{testlog.Topic(tokenAddr)}, []any{big.NewInt(5), big.NewInt(9)},
+			cgrv1.PlayerUpdate_REASON_REWARD_SYNCED, true},
+		{"native deposit", router, routerAddr, "DepositedNative", []common.Hash{testlog.Topic(player), one}, []any{big.NewInt(5)},
+			cgrv1.PlayerUpdate_REASON_DEPOSITED_NATIVE, true},
+		{"native withdrawal from a look-alike router", router, common.HexToAddress("0xdead"), "WithdrawnNative", []common.Hash{testlog.Topic(player), one}, []any{big.NewInt(5)},
+			0, false},
+	} {
+		ev, ok := d.decode(testlog.Event(t, tc.meta, tc.addr, tc.event, tc.topics, tc.data...), player, routerAddr)
+		if ok != tc.match {
+			t.Errorf("%s: match = %v, want %v", tc.name, ok, tc.match)
+			continue
+		}
+		if ok && ev.reason != tc.reason {
+			t.Errorf("%s: reason = %v, want %v", tc.name, ev.reason, tc.reason)
+		}
+	}
+}
+
+func TestPoolStatus(t *testing.T) {
+	want := []cgrv1.PoolStatus{
+		cgrv1.PoolStatus_POOL_STATUS_ACTIVE,
+		cgrv1.PoolStatus_POOL_STATUS_INACTIVE,
+		cgrv1.PoolStatus_POOL_STATUS_PAUSED,
+		cgrv1.PoolStatus_POOL_STATUS_UNSPECIFIED,
+	}
+	for s, w := range want {
+		if got := poolStatus(uint8(s)); got != w {
+			t.Errorf("poolStatus(%d) = %v, want %v", s, got, w)
+		}
+	}
+}
+
+// revertError is a JSON-RPC error as returned for a reverted eth_call.
+type revertError struct{}
+
+func (revertError) Error() string  { return "execution reverted: CGRPoolNotFound" }
+func (revertError) ErrorCode() int { return 3 }
+
+func TestToStatus(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want codes.Code
+	}{
+		{fmt.Errorf("pool 9: %w", query.ErrPoolNotFound), codes.NotFound},
+		{revertError{}, codes.FailedPrecondition},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, codes.Unavailable},
+		{rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, codes.Unavailable},
+		{errors.New("abi: cannot unmarshal tuple into []uint256"), codes.Internal},
+	} {
+		if got := status.Code(toStatus(c.err)); got != c.want {
+			t.Errorf("toStatus(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestPositionSplitsRemovedRewards(t *testing.T) {
+	pos := position(&query.Position{
+		PoolID: big.NewInt(1), Pool: poolAddr, DepositToken: tokenAddr, Deposited: big.NewInt(10),
+		Pending: []query.Reward{
+			{Token: tokenAddr, Amount: big.NewInt(3)},
+			{Token: other, Amount: big.NewInt(4), Removed: true},
+		},
+	})
+	if len(pos.Pending) != 1 || len(pos.RemovedPending) != 1 || pos.RemovedPending[0].Amount != "4" || pos.Deposited != "10" {
+		t.Fatalf("unexpected position: %v", pos)
+	}
+}
+
+// fakeLogs records log queries and subscriptions.
+type fakeLogs struct {
+	queries []ethereum.FilterQuery
+	subs    []chan error
+}
+
+func (f *fakeLogs) BlockNumber(context.Context) (uint64, error) { return 0, nil }
+
+func (f *fakeLogs) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.queries = append(f.queries, q)
+	return []types.Log{{Topics: q.Topics[0]}}, nil
+}
+
+func (f *fakeLogs) SubscribeFilterLogs(_ context.Context, q ethereum.FilterQuery, _ chan<- types.Log) (ethereum.Subscription, error) {
+	fail := make(chan error, 1)
+	f.subs = append(f.subs, fail)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case err := <-fail:
+			return err
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
+
+func TestPlayerSource(t *testing.T) {
+	d, err := newDecoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeLogs{}
+	src := playerSource(f, d, player)
+
+	logs, err := src.Filter(context.Background(), 5, 9)
+	if err != nil || len(logs) != 2 || len(f.queries) != 2 {
+		t.Fatalf("filter: %d logs from %d queries, %v", len(logs), len(f.queries), err)
+	}
+	for _, q := range f.queries {
+		if q.FromBlock.Uint64() != 5 || q.ToBlock.Uint64() != 9 {
+			t.Errorf("queried %v-%v, want 5-9", q.FromBlock, q.ToBlock)
+		}
+	}
+	if own := f.queries[0].Topics; len(own) != 2 || own[1][0] != testlog.Topic(player) {
+		t.Errorf("own topics %v", own)
+	}
+	if synced := f.queries[1].Topics; len(synced) != 1 || synced[0][0] != d.syncedSig {
+		t.Errorf("synced topics %v", synced)
+	}
+
+	// Losing either subscription ends the session, so the loop reconnects
+	// and backfills both.
+	sub, err := src.Watch(context.Background(), make(chan types.Log))
+	if err != nil || len(f.subs) != 2 {
+		t.Fatalf("watch: %d subscriptions, %v", len(f.subs), err)
+	}
+	f.subs[1] <- errors.New("websocket: close 1006")
+	select {
+	case err := <-sub.Err():
+		if err == nil || !strings.Contains(err.Error(), "1006") {
+			t.Fatalf("session ended with %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("session survived a dropped subscription")
+	}
+}
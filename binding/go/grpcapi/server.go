@@ -0,0 +1,273 @@
+// Package grpcapi implements the cgr.v1.RewardService gRPC API.
+//
+// Unary calls mirror the router and factory views at a requested block.
+// WatchPlayer streams an update whenever an event touches the player's
+// position. The service definition lives in proto/cgr/v1/reward.proto; the
+// generated code is in cgrv1.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/grpcapi/cgrv1"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+// Server implements cgrv1.RewardServiceServer.
+type Server struct {
+	cgrv1.UnimplementedRewardServiceServer
+
+	net    *config.Network
+	reader *query.Reader
+	pools  *poolSet
+	dec    *decoder
+}
+
+// New returns a Server reading from net.
+func New(net *config.Network) (*Server, error) {
+	dec, err := newDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{net: net, reader: query.New(net), pools: newPoolSet(net), dec: dec}, nil
+}
+
+// GetUserDepositInfo implements cgrv1.RewardServiceServer.
+func (s *Server) GetUserDepositInfo(ctx context.Context, req *cgrv1.GetUserDepositInfoRequest) (*cgrv1.GetUserDepositInfoResponse, error) {
+	id, user, err := parsePoolUser(req.PoolId, req.User)
+	if err != nil {
+		return nil, err
+	}
+	opts, block, err := s.callOpts(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRouter(opts, id); err != nil {
+		return nil, err
+	}
+	info, err := s.net.Router.GetUserDepositInfo(opts, id, user)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &cgrv1.GetUserDepositInfoResponse{
+		Block:           block,
+		DepositedAmount: info.DepositedAmount.String(),
+		PendingRewards:  tokenAmounts(info.RewardTokens, info.PendingRewards),
+	}, nil
+}
+
+// GetAllPendingRewards implements cgrv1.RewardServiceServer.
+func (s *Server) GetAllPendingRewards(ctx context.Context, req *cgrv1.GetAllPendingRewardsRequest) (*cgrv1.GetAllPendingRewardsResponse, error) {
+	id, user, err := parsePoolUser(req.PoolId, req.User)
+	if err != nil {
+		return nil, err
+	}
+	opts, block, err := s.callOpts(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRouter(opts, id); err != nil {
+		return nil, err
+	}
+	res, err := s.net.Router.GetAllPendingRewards(opts, id, user)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &cgrv1.GetAllPendingRewardsResponse{
+		Block:          block,
+		PendingRewards: tokenAmounts(res.RewardTokens, res.PendingRewards),
+	}, nil
+}
+
+// GetPoolInfo implements cgrv1.RewardServiceServer.
+func (s *Server) GetPoolInfo(ctx context.Context, req *cgrv1.GetPoolInfoRequest) (*cgrv1.GetPoolInfoResponse, error) {
+	id, err := parsePoolID(req.PoolId)
+	if err != nil {
+		return nil, err
+	}
+	opts, block, err := s.callOpts(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.reader.Pool(opts, id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &cgrv1.GetPoolInfoResponse{Block: block, Pool: poolInfo(p)}, nil
+}
+
+// GetActivePoolIds implements cgrv1.RewardServiceServer.
+func (s *Server) GetActivePoolIds(ctx context.Context, req *cgrv1.GetActivePoolIdsRequest) (*cgrv1.GetActivePoolIdsResponse, error) {
+	opts, block, err := s.callOpts(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.reader.PoolIDs(opts, true)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &cgrv1.GetActivePoolIdsResponse{Block: block, PoolIds: make([]string, len(ids))}
+	for i, id := range ids {
+		out.PoolIds[i] = id.String()
+	}
+	return out, nil
+}
+
+// callOpts pins reads to block, resolving 0 to the current head.
+func (s *Server) callOpts(ctx context.Context, block uint64) (*bind.CallOpts, uint64, error) {
+	if block == 0 {
+		head, err := s.net.Client.BlockNumber(ctx)
+		if err != nil {
+			return nil, 0, status.Errorf(codes.Unavailable, "head: %v", err)
+		}
+		block = head
+	}
+	return &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)}, block, nil
+}
+
+// checkRouter fails unless the router is set and pool id exists, so unknown
+// pools map to NotFound rather than a revert.
+func (s *Server) checkRouter(opts *bind.CallOpts, id *big.Int) error {
+	if s.net.Router == nil {
+		return toStatus(query.ErrNoRouter)
+	}
+	if err := s.reader.CheckPool(opts, id); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+func parsePoolID(v string) (*big.Int, error) {
+	id, ok := new(big.Int).SetString(v, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid pool_id %q", v)
+	}
+	return id, nil
+}
+
+func parseAddress(field, v string) (common.Address, error) {
+	if !common.IsHexAddress(v) {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, "invalid %s %q", field, v)
+	}
+	return common.HexToAddress(v), nil
+}
+
+func parsePoolUser(poolID, user string) (*big.Int, common.Address, error) {
+	id, err := parsePoolID(poolID)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	addr, err := parseAddress("user", user)
+	return id, addr, err
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, query.ErrPoolNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, query.ErrNoRouter):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, bind.ErrNoCode), isRevert(err):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case isTransport(err):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	// Node errors and undecodable results do not go away on retry.
+	return status.Error(codes.Internal, err.Error())
+}
+
+// isRevert reports whether err is a contract call that reverted.
+func isRevert(err error) bool {
+	var data rpc.DataError
+	if errors.As(err, &data) {
+		return true
+	}
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 || strings.Contains(err.Error(), "execution reverted")
+}
+
+// isTransport reports whether err is a failure to reach the node, which a
+// client may retry.
+func isTransport(err error) bool {
+	var (
+		netErr  net.Error
+		httpErr rpc.HTTPError
+	)
+	return errors.As(err, &netErr) || errors.As(err, &httpErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, rpc.ErrClientQuit)
+}
+
+func tokenAmounts(tokens []common.Address, amounts []*big.Int) []*cgrv1.TokenAmount {
+	out := make([]*cgrv1.TokenAmount, len(tokens))
+	for i, t := range tokens {
+		out[i] = &cgrv1.TokenAmount{Token: t.Hex(), Amount: amounts[i].String()}
+	}
+	return out
+}
+
+func poolInfo(p *query.Pool) *cgrv1.PoolInfo {
+	return &cgrv1.PoolInfo{
+		PoolId:              p.ID.String(),
+		Pool:                p.Address.Hex(),
+		Name:                p.Name,
+		DepositToken:        p.DepositToken.Hex(),
+		CreatedAt:           p.CreatedAt.String(),
+		Status:              poolStatus(p.Status),
+		Paused:              p.Paused,
+		TotalDeposited:      p.TotalDeposited.String(),
+		MinDepositAmount:    p.MinDepositAmount.String(),
+		RewardTokens:        hexes(p.RewardTokens),
+		RemovedRewardTokens: hexes(p.RemovedTokens),
+	}
+}
+
+// poolStatus shifts the contract's PoolStatus by one so that the proto
+// enum keeps 0 for UNSPECIFIED.
+func poolStatus(s uint8) cgrv1.PoolStatus {
+	if s > 2 {
+		return cgrv1.PoolStatus_POOL_STATUS_UNSPECIFIED
+	}
+	return cgrv1.PoolStatus(s + 1)
+}
+
+func position(p *query.Position) *cgrv1.Position {
+	out := &cgrv1.Position{
+		PoolId:       p.PoolID.String(),
+		Pool:         p.Pool.Hex(),
+		DepositToken: p.DepositToken.Hex(),
+		Deposited:    p.Deposited.String(),
+	}
+	for _, r := range p.Pending {
+		ta := &cgrv1.TokenAmount{Token: r.Token.Hex(), Amount: r.Amount.String()}
+		if r.Removed {
+			out.RemovedPending = append(out.RemovedPending, ta)
+		} else {
+			out.Pending = append(out.Pending, ta)
+		}
+	}
+	return out
+}
+
+func hexes(addrs []common.Address) []string {
+	out := make([]string, len(addrs))
+	for i, a := range addrs {
+		out[i] = a.Hex()
+	}
+	return out
+}
@@ -0,0 +1,332 @@
+package grpcapi
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/grpcapi/cgrv1"
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/follow"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+// playerEvent is a decoded log that may change a player's position.
+type playerEvent struct {
+	reason cgrv1.PlayerUpdate_Reason
+	// poolID is set for router events, which name the pool; pool events
+	// are resolved from the emitting address.
+	poolID *big.Int
+	token  common.Address
+	amount *big.Int
+}
+
+// decoder recognises the events WatchPlayer reports.
+type decoder struct {
+	pool   *binding.CrossGameRewardPoolFilterer
+	router *binding.CrossGameRewardRouterFilterer
+	// userSigs are events whose first indexed topic is the player;
+	// syncedSig is RewardSynced, which concerns every depositor.
+	userSigs  []common.Hash
+	syncedSig common.Hash
+}
+
+func newDecoder() (*decoder, error) {
+	pool, err := binding.NewCrossGameRewardPoolFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	router, err := binding.NewCrossGameRewardRouterFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	poolABI, err := binding.CrossGameRewardPoolMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	routerABI, err := binding.CrossGameRewardRouterMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{
+		pool:   pool,
+		router: router,
+		userSigs: []common.Hash{
+			poolABI.Events["Deposited"].ID,
+			poolABI.Events["Withdrawn"].ID,
+			poolABI.Events["RewardClaimed"].ID,
+			routerABI.Events["DepositedNative"].ID,
+			routerABI.Events["WithdrawnNative"].ID,
+		},
+		syncedSig: poolABI.Events["RewardSynced"].ID,
+	}, nil
+}
+
+// decode returns the event in l if it involves user. Router events are
+// accepted only from routerAddr. RewardSynced involves every depositor, so
+// it is returned for any user and the caller decides whether the player's
+// pending amount changed.
+func (d *decoder) decode(l types.Log, user, routerAddr common.Address) (*playerEvent, bool) {
+	if l.Address == routerAddr {
+		if ev, err := d.router.ParseDepositedNative(l); err == nil && ev.User == user {
+			return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_DEPOSITED_NATIVE, poolID: ev.PoolId, amount: ev.Amount}, true
+		}
+		if ev, err := d.router.ParseWithdrawnNative(l); err == nil && ev.User == user {
+			return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_WITHDRAWN_NATIVE, poolID: ev.PoolId, amount: ev.Amount}, true
+		}
+		return nil, false
+	}
+	if ev, err := d.pool.ParseDeposited(l); err == nil {
+		return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_DEPOSITED, amount: ev.Amount}, ev.Account == user
+	}
+	if ev, err := d.pool.ParseWithdrawn(l); err == nil {
+		return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_WITHDRAWN, amount: ev.Amount}, ev.Account == user
+	}
+	if ev, err := d.pool.ParseRewardClaimed(l); err == nil {
+		return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_REWARD_CLAIMED, token: ev.Token, amount: ev.Amount}, ev.Account == user
+	}
+	if ev, err := d.pool.ParseRewardSynced(l); err == nil {
+		return &playerEvent{reason: cgrv1.PlayerUpdate_REASON_REWARD_SYNCED, token: ev.Token}, true
+	}
+	return nil, false
+}
+
+// poolSet maps log emitters to pool IDs, so logs from look-alike contracts
+// are ignored. Results are cached: a pool is registered before it can emit,
+// so an address unknown at a log's block never becomes one of ours for it.
+type poolSet struct {
+	net *config.Network
+	mu  sync.Mutex
+	ids map[common.Address]*big.Int
+}
+
+func newPoolSet(net *config.Network) *poolSet {
+	return &poolSet{net: net, ids: make(map[common.Address]*big.Int)}
+}
+
+// id returns the pool ID of addr, or nil if addr is not a protocol pool.
+func (p *poolSet) id(opts *bind.CallOpts, addr common.Address) (*big.Int, error) {
+	p.mu.Lock()
+	id, ok := p.ids[addr]
+	p.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+	id, err := p.net.Factory.PoolIds(opts, addr)
+	if err != nil {
+		return nil, err
+	}
+	if id.Sign() == 0 {
+		id = nil
+	}
+	p.mu.Lock()
+	p.ids[addr] = id
+	p.mu.Unlock()
+	return id, nil
+}
+
+// WatchPlayer implements cgrv1.RewardServiceServer. The node must support
+// log subscriptions. A dropped subscription is reopened with backoff and
+// the blocks missed meanwhile are replayed, as in package subscription.
+// Logs removed by a reorg are skipped.
+func (s *Server) WatchPlayer(req *cgrv1.WatchPlayerRequest, stream cgrv1.RewardService_WatchPlayerServer) error {
+	user, err := parseAddress("user", req.User)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	// failed ends the stream with err; node failures are retried instead.
+	var failed error
+	fail := func(err error) error {
+		failed = err
+		cancel()
+		return err
+	}
+
+	src := playerSource(s.net.Client, s.dec, user)
+	watch := src.Watch
+	started := false
+	src.Watch = func(ctx context.Context, sink chan<- types.Log) (ethereum.Subscription, error) {
+		sub, err := watch(ctx, sink)
+		if err != nil && !started {
+			// A node without log subscriptions will not gain them.
+			return nil, fail(status.Errorf(codes.Unavailable, "subscribe: %v", err))
+		}
+		started = true
+		return sub, err
+	}
+	w := &playerWatch{s: s, user: user, pending: make(map[common.Address]map[common.Address]*big.Int)}
+	loop := follow.New(src, follow.Config{Name: "grpcapi"}, func(ctx context.Context, l types.Log) error {
+		upd, err := w.handle(ctx, l)
+		if err != nil {
+			if isTransport(err) || ctx.Err() != nil {
+				return err
+			}
+			return fail(toStatus(err))
+		}
+		if upd != nil {
+			if err := stream.Send(upd); err != nil {
+				return fail(err)
+			}
+		}
+		return nil
+	})
+	loop.Run(ctx)
+	return failed
+}
+
+// playerSource reads the logs that may change user's position: the
+// player's own pool and router events, and every RewardSynced.
+func playerSource(client playerClient, dec *decoder, user common.Address) follow.Source[types.Log] {
+	queries := []ethereum.FilterQuery{
+		{Topics: [][]common.Hash{dec.userSigs, {common.BytesToHash(user.Bytes())}}},
+		{Topics: [][]common.Hash{{dec.syncedSig}}},
+	}
+	return follow.Source[types.Log]{
+		Head: client,
+		Watch: func(ctx context.Context, sink chan<- types.Log) (ethereum.Subscription, error) {
+			subs := make([]ethereum.Subscription, 0, len(queries))
+			stop := func() {
+				for _, sub := range subs {
+					sub.Unsubscribe()
+				}
+			}
+			for _, q := range queries {
+				sub, err := client.SubscribeFilterLogs(ctx, q, sink)
+				if err != nil {
+					stop()
+					return nil, err
+				}
+				subs = append(subs, sub)
+			}
+			// The session ends when either subscription fails.
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				defer stop()
+				select {
+				case err := <-subs[0].Err():
+					return err
+				case err := <-subs[1].Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			}), nil
+		},
+		Filter: func(ctx context.Context, from, to uint64) ([]types.Log, error) {
+			var out []types.Log
+			for _, q := range queries {
+				q.FromBlock, q.ToBlock = new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)
+				logs, err := client.FilterLogs(ctx, q)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, logs...)
+			}
+			return out, nil
+		},
+		Log: func(l types.Log) types.Log { return l },
+	}
+}
+
+// playerClient is the node API playerSource needs; *ethclient.Client
+// satisfies it.
+type playerClient interface {
+	ethereum.LogFilterer
+	ethereum.BlockNumberReader
+}
+
+// playerWatch is the state of one WatchPlayer stream.
+type playerWatch struct {
+	s    *Server
+	user common.Address
+	// pending is the last known pending amount per pool and reward token,
+	// used to tell whether a RewardSynced changed anything for the player.
+	pending map[common.Address]map[common.Address]*big.Int
+}
+
+func (w *playerWatch) handle(ctx context.Context, l types.Log) (*cgrv1.PlayerUpdate, error) {
+	if l.Removed {
+		return nil, nil
+	}
+	ev, ok := w.s.dec.decode(l, w.user, w.s.net.RouterAddress)
+	if !ok {
+		return nil, nil
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(l.BlockNumber)}
+	id := ev.poolID
+	if id == nil {
+		var err error
+		if id, err = w.s.pools.id(opts, l.Address); err != nil || id == nil {
+			return nil, err
+		}
+	}
+	if ev.reason == cgrv1.PlayerUpdate_REASON_REWARD_SYNCED {
+		amount, changed, err := w.syncChanged(opts, l.Address, ev.token)
+		if err != nil || !changed {
+			return nil, err
+		}
+		ev.amount = amount
+	} else if ev.poolID == nil {
+		// The player's own action resets what we know about this pool.
+		delete(w.pending, l.Address)
+	}
+
+	pos, err := w.s.reader.Position(opts, id, w.user)
+	if err != nil {
+		return nil, err
+	}
+	upd := &cgrv1.PlayerUpdate{
+		Reason:   ev.reason,
+		Block:    l.BlockNumber,
+		TxHash:   l.TxHash.Hex(),
+		LogIndex: uint32(l.Index),
+		Amount:   ev.amount.String(),
+		Position: position(pos),
+	}
+	if ev.token != (common.Address{}) {
+		upd.Token = ev.token.Hex()
+	}
+	return upd, nil
+}
+
+// syncChanged reports whether the player's pending amount of token in pool
+// differs from the last known value, or from the previous block's when none
+// is known. Players without a deposit are unaffected by syncs.
+func (w *playerWatch) syncChanged(opts *bind.CallOpts, poolAddr, token common.Address) (*big.Int, bool, error) {
+	pool, err := w.s.net.Pool(poolAddr)
+	if err != nil {
+		return nil, false, err
+	}
+	bal, err := pool.Balances(opts, w.user)
+	if err != nil || bal.Sign() == 0 {
+		return nil, false, err
+	}
+	now, err := pool.PendingReward(opts, w.user, token)
+	if err != nil {
+		return nil, false, err
+	}
+	known := w.pending[poolAddr]
+	if known == nil {
+		known = make(map[common.Address]*big.Int)
+		w.pending[poolAddr] = known
+	}
+	prev, ok := known[token]
+	if !ok {
+		before := *opts
+		before.BlockNumber = new(big.Int).Sub(opts.BlockNumber, common.Big1)
+		if prev, err = pool.PendingReward(&before, w.user, token); err != nil {
+			return nil, false, err
+		}
+	}
+	known[token] = now
+	return now, now.Cmp(prev) != 0, nil
+}
@@ -0,0 +1,249 @@
+// Package indexer scans pool events into a local store.
+//
+// Only blocks that are final under the profile's finality policy are
+// indexed, so stored events are never rolled back. Depositors, replayed balances and
+// reward claims are served from the store without further RPC calls.
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+// DefaultChunkSize is the number of blocks requested per eth_getLogs call.
+const DefaultChunkSize = 5000
+
+var poolEvents = mustPoolEventIDs(KindDeposited, KindWithdrawn, KindRewardClaimed, KindRewardClaimFailed)
+
+func mustPoolEventIDs(kinds ...Kind) map[common.Hash]Kind {
+	parsed, err := binding.CrossGameRewardPoolMetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	ids := make(map[common.Hash]Kind, len(kinds))
+	for _, k := range kinds {
+		ids[parsed.Events[string(k)].ID] = k
+	}
+	return ids
+}
+
+// Indexer fills a Store from the chain.
+type Indexer struct {
+	net    *config.Network
+	store  *Store
+	parser *binding.CrossGameRewardPoolFilterer
+
+	// ChunkSize bounds each log query. Zero means DefaultChunkSize.
+	ChunkSize uint64
+}
+
+// New returns an Indexer writing into store.
+func New(net *config.Network, store *Store) *Indexer {
+	parser, err := binding.NewCrossGameRewardPoolFilterer(common.Address{}, nil)
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	return &Indexer{net: net, store: store, parser: parser}
+}
+
+// Store returns the store the indexer writes to.
+func (ix *Indexer) Store() *Store {
+	return ix.store
+}
+
+// Sync indexes from the stored head up to the final block and returns the
+// new head.
+func (ix *Indexer) Sync(ctx context.Context) (uint64, error) {
+	target, err := finality.ForProfile(ix.net.Profile).FinalBlock(ctx, ix.net.Client)
+	if err != nil {
+		return 0, fmt.Errorf("indexer: head: %w", err)
+	}
+	return ix.SyncTo(ctx, target)
+}
+
+// SyncTo indexes up to and including block target.
+func (ix *Indexer) SyncTo(ctx context.Context, target uint64) (uint64, error) {
+	from := ix.store.Head() + 1
+	if start := ix.net.Profile.StartBlock; from < start {
+		from = start
+	}
+	if from > target {
+		return ix.store.Head(), nil
+	}
+	chunk := ix.ChunkSize
+	if chunk == 0 {
+		chunk = DefaultChunkSize
+	}
+	pools, err := ix.refreshPools(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	if len(pools) == 0 {
+		// An empty address list would match every contract on chain.
+		return target, ix.store.Append(target)
+	}
+	ids := ix.store.Pools()
+	times := make(map[uint64]uint64)
+	for from <= target {
+		to := min(from+chunk-1, target)
+		logs, err := ix.net.Client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: pools,
+			Topics:    [][]common.Hash{eventIDs()},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("indexer: logs %d-%d: %w", from, to, err)
+		}
+		evs := make([]Event, 0, len(logs))
+		for _, l := range logs {
+			ev, ok, err := ix.decode(l, ids)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				continue
+			}
+			if ev.Time, err = ix.blockTime(ctx, times, l.BlockNumber); err != nil {
+				return 0, err
+			}
+			evs = append(evs, ev)
+		}
+		if err := ix.store.Append(to, evs...); err != nil {
+			return 0, err
+		}
+		from = to + 1
+	}
+	return target, nil
+}
+
+// Run syncs every interval until ctx is done. After each successful sync
+// onSync, if set, is called with the new head; returning an error stops Run.
+// Sync errors are passed to onError, if set, and retried on the next tick.
+func (ix *Indexer) Run(ctx context.Context, interval time.Duration, onSync func(head uint64) error, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		head, err := ix.Sync(ctx)
+		switch {
+		case err != nil:
+			if onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		case onSync != nil:
+			if err := onSync(head); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ix *Indexer) refreshPools(ctx context.Context, block uint64) ([]common.Address, error) {
+	refs, err := ix.net.Pools(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)})
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, len(refs))
+	for i, r := range refs {
+		ix.store.SetPool(r.Address, r.ID.Uint64())
+		addrs[i] = r.Address
+	}
+	return addrs, nil
+}
+
+func eventIDs() []common.Hash {
+	ids := make([]common.Hash, 0, len(poolEvents))
+	for id := range poolEvents {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (ix *Indexer) decode(l types.Log, ids map[common.Address]uint64) (Event, bool, error) {
+	if len(l.Topics) == 0 || l.Removed {
+		return Event{}, false, nil
+	}
+	kind, ok := poolEvents[l.Topics[0]]
+	if !ok {
+		return Event{}, false, nil
+	}
+	ev := Event{
+		Kind:     kind,
+		PoolID:   ids[l.Address],
+		Pool:     l.Address,
+		Block:    l.BlockNumber,
+		TxHash:   l.TxHash,
+		LogIndex: l.Index,
+	}
+	var err error
+	switch kind {
+	case KindDeposited:
+		var e *binding.CrossGameRewardPoolDeposited
+		if e, err = ix.parser.ParseDeposited(l); err == nil {
+			ev.Account, ev.Amount = e.Account, e.Amount
+		}
+	case KindWithdrawn:
+		var e *binding.CrossGameRewardPoolWithdrawn
+		if e, err = ix.parser.ParseWithdrawn(l); err == nil {
+			ev.Account, ev.Amount = e.Account, e.Amount
+		}
+	case KindRewardClaimed:
+		var e *binding.CrossGameRewardPoolRewardClaimed
+		if e, err = ix.parser.ParseRewardClaimed(l); err == nil {
+			ev.Account, ev.Token, ev.Amount = e.Account, e.Token, e.Amount
+		}
+	case KindRewardClaimFailed:
+		var e *binding.CrossGameRewardPoolRewardClaimFailed
+		if e, err = ix.parser.ParseRewardClaimFailed(l); err == nil {
+			ev.Account, ev.Token, ev.Amount = e.Account, e.Token, e.Amount
+		}
+	}
+	if err != nil {
+		return Event{}, false, fmt.Errorf("indexer: decode %s at %s#%d: %w", kind, l.TxHash, l.Index, err)
+	}
+	return ev, true, nil
+}
+
+func (ix *Indexer) blockTime(ctx context.Context, cache map[uint64]uint64, block uint64) (uint64, error) {
+	if t, ok := cache[block]; ok {
+		return t, nil
+	}
+	h, err := ix.net.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return 0, fmt.Errorf("indexer: header %d: %w", block, err)
+	}
+	cache[block] = h.Time
+	return h.Time, nil
+}
+
+// SyncFile loads the store at path (empty if missing), syncs it to the
+// final block and saves it back. Commands use it to share one index file.
+func SyncFile(ctx context.Context, net *config.Network, path string) (*Store, error) {
+	store, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := New(net, store).Sync(ctx); err != nil {
+		return nil, err
+	}
+	if err := store.Save(path); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
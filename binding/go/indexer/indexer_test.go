@@ -0,0 +1,40 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+func TestRunReportsSyncErrors(t *testing.T) {
+	// A server without methods fails every head request.
+	client := ethclient.NewClient(rpc.DialInProc(rpc.NewServer()))
+	defer client.Close()
+	net := &config.Network{Profile: &config.Profile{}, Client: client}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	errs := make(chan error, 1)
+	go New(net, NewStore()).Run(ctx, time.Millisecond, func(uint64) error {
+		t.Error("onSync called after a failed sync")
+		return nil
+	}, func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("nil error reported")
+		}
+	case <-ctx.Done():
+		t.Fatal("sync error never reported")
+	}
+}
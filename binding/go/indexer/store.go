@@ -0,0 +1,254 @@
+package indexer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Kind names a decoded pool event.
+type Kind string
+
+// Indexed pool events.
+const (
+	KindDeposited         Kind = "Deposited"
+	KindWithdrawn         Kind = "Withdrawn"
+	KindRewardClaimed     Kind = "RewardClaimed"
+	KindRewardClaimFailed Kind = "RewardClaimFailed"
+)
+
+// Event is one indexed pool log.
+type Event struct {
+	Kind     Kind           `json:"kind"`
+	PoolID   uint64         `json:"poolId"`
+	Pool     common.Address `json:"pool"`
+	Account  common.Address `json:"account"`
+	Token    common.Address `json:"token,omitempty"` // reward token; zero for deposits and withdrawals
+	Amount   *big.Int       `json:"amount"`
+	Block    uint64         `json:"block"`
+	Time     uint64         `json:"time"`
+	TxHash   common.Hash    `json:"txHash"`
+	LogIndex uint           `json:"logIndex"`
+}
+
+// Filter selects events from a Store. Zero fields match everything.
+type Filter struct {
+	Pools     []common.Address
+	Kinds     []Kind
+	Account   common.Address
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+func (f *Filter) match(ev *Event) bool {
+	if len(f.Pools) > 0 && !contains(f.Pools, ev.Pool) {
+		return false
+	}
+	if len(f.Kinds) > 0 && !contains(f.Kinds, ev.Kind) {
+		return false
+	}
+	if f.Account != (common.Address{}) && f.Account != ev.Account {
+		return false
+	}
+	if ev.Block < f.FromBlock || (f.ToBlock != 0 && ev.Block > f.ToBlock) {
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](s []T, v T) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps indexed events in block order. It is safe for concurrent use.
+type Store struct {
+	mu     sync.RWMutex
+	head   uint64
+	pools  map[common.Address]uint64
+	events []Event
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{pools: make(map[common.Address]uint64)}
+}
+
+// Head returns the last block fully indexed, or zero for an empty store.
+func (s *Store) Head() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.head
+}
+
+// Append records the events of a block range and advances the head to
+// head. Events must be newer than everything already stored.
+func (s *Store) Append(head uint64, evs ...Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if head < s.head {
+		return fmt.Errorf("indexer: head %d is behind stored head %d", head, s.head)
+	}
+	for i := range evs {
+		if evs[i].Block <= s.head && s.head != 0 {
+			return fmt.Errorf("indexer: event at block %d is not after head %d", evs[i].Block, s.head)
+		}
+	}
+	sort.SliceStable(evs, func(i, j int) bool {
+		if evs[i].Block != evs[j].Block {
+			return evs[i].Block < evs[j].Block
+		}
+		return evs[i].LogIndex < evs[j].LogIndex
+	})
+	for _, ev := range evs {
+		s.pools[ev.Pool] = ev.PoolID
+	}
+	s.events = append(s.events, evs...)
+	s.head = head
+	return nil
+}
+
+// SetPool records a pool's ID so it is known before it emits any event.
+func (s *Store) SetPool(addr common.Address, id uint64) {
+	s.mu.Lock()
+	s.pools[addr] = id
+	s.mu.Unlock()
+}
+
+// Pools returns the known pools keyed by address.
+func (s *Store) Pools() map[common.Address]uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[common.Address]uint64, len(s.pools))
+	for k, v := range s.pools {
+		out[k] = v
+	}
+	return out
+}
+
+// Events returns the events matching f in block order.
+func (s *Store) Events(f Filter) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []Event
+	for i := range s.events {
+		if f.match(&s.events[i]) {
+			out = append(out, s.events[i])
+		}
+	}
+	return out
+}
+
+// Depositors returns every account that has ever deposited into pool, in
+// order of first deposit.
+func (s *Store) Depositors(pool common.Address) []common.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[common.Address]bool)
+	var out []common.Address
+	for i := range s.events {
+		ev := &s.events[i]
+		if ev.Kind == KindDeposited && ev.Pool == pool && !seen[ev.Account] {
+			seen[ev.Account] = true
+			out = append(out, ev.Account)
+		}
+	}
+	return out
+}
+
+// Balances replays deposits and withdrawals of pool up to block (inclusive,
+// zero for all) and returns each account's deposited balance.
+func (s *Store) Balances(pool common.Address, block uint64) map[common.Address]*big.Int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[common.Address]*big.Int)
+	for i := range s.events {
+		ev := &s.events[i]
+		if ev.Pool != pool || (block != 0 && ev.Block > block) {
+			continue
+		}
+		bal, ok := out[ev.Account]
+		if !ok {
+			bal = new(big.Int)
+			out[ev.Account] = bal
+		}
+		switch ev.Kind {
+		case KindDeposited:
+			bal.Add(bal, ev.Amount)
+		case KindWithdrawn:
+			bal.Sub(bal, ev.Amount)
+		}
+	}
+	for acct, bal := range out {
+		if bal.Sign() == 0 {
+			delete(out, acct)
+		}
+	}
+	return out
+}
+
+type storeFile struct {
+	Head   uint64                    `json:"head"`
+	Pools  map[common.Address]uint64 `json:"pools"`
+	Events []Event                   `json:"events"`
+}
+
+// Load reads a store saved with Save. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewStore(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("indexer: read %s: %w", path, err)
+	}
+	var f storeFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("indexer: parse %s: %w", path, err)
+	}
+	s := NewStore()
+	s.head, s.events = f.Head, f.Events
+	for k, v := range f.Pools {
+		s.pools[k] = v
+	}
+	return s, nil
+}
+
+// Save writes the store to path atomically.
+func (s *Store) Save(path string) error {
+	s.mu.RLock()
+	raw, err := json.Marshal(storeFile{Head: s.head, Pools: s.pools, Events: s.events})
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, raw)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,96 @@
+package indexer
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	poolA = common.HexToAddress("0xa0")
+	poolB = common.HexToAddress("0xb0")
+	alice = common.HexToAddress("0xa11ce")
+	bob   = common.HexToAddress("0xb0b")
+)
+
+func ev(kind Kind, pool, acct common.Address, amount int64, block uint64, idx uint) Event {
+	return Event{Kind: kind, Pool: pool, PoolID: 1, Account: acct, Amount: big.NewInt(amount), Block: block, LogIndex: idx}
+}
+
+func TestStoreAppendOrdersAndGuardsHead(t *testing.T) {
+	s := NewStore()
+	err := s.Append(20,
+		ev(KindDeposited, poolA, bob, 5, 12, 0),
+		ev(KindDeposited, poolA, alice, 10, 11, 3),
+		ev(KindWithdrawn, poolA, alice, 4, 12, 1),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Events(Filter{})
+	if got[0].Account != alice || got[1].Account != bob || got[2].Kind != KindWithdrawn {
+		t.Fatalf("events not in block/log order: %+v", got)
+	}
+	if err := s.Append(25, ev(KindDeposited, poolA, bob, 1, 19, 0)); err == nil {
+		t.Fatal("want error for event at or before head")
+	}
+	if err := s.Append(10); err == nil {
+		t.Fatal("want error for head moving backwards")
+	}
+}
+
+func TestStoreQueries(t *testing.T) {
+	s := NewStore()
+	s.Append(30,
+		ev(KindDeposited, poolA, alice, 10, 11, 0),
+		ev(KindDeposited, poolB, bob, 7, 12, 0),
+		ev(KindDeposited, poolA, bob, 5, 13, 0),
+		ev(KindDeposited, poolA, alice, 1, 14, 0),
+		ev(KindWithdrawn, poolA, alice, 11, 15, 0),
+		ev(KindRewardClaimed, poolA, alice, 99, 15, 1),
+	)
+
+	deps := s.Depositors(poolA)
+	if len(deps) != 2 || deps[0] != alice || deps[1] != bob {
+		t.Fatalf("unexpected depositors: %v", deps)
+	}
+
+	bal := s.Balances(poolA, 0)
+	if _, ok := bal[alice]; ok || bal[bob].Int64() != 5 {
+		t.Fatalf("unexpected balances at head: %v", bal)
+	}
+	bal = s.Balances(poolA, 14)
+	if bal[alice].Int64() != 11 {
+		t.Fatalf("unexpected balance at block 14: %v", bal)
+	}
+
+	claims := s.Events(Filter{Kinds: []Kind{KindRewardClaimed}, Account: alice})
+	if len(claims) != 1 || claims[0].Amount.Int64() != 99 {
+		t.Fatalf("unexpected claims: %v", claims)
+	}
+	if n := len(s.Events(Filter{Pools: []common.Address{poolB}, FromBlock: 12, ToBlock: 12})); n != 1 {
+		t.Fatalf("want 1 event in pool B at block 12, got %d", n)
+	}
+}
+
+func TestStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	s, err := Load(path)
+	if err != nil || s.Head() != 0 {
+		t.Fatalf("missing file should load empty: %v", err)
+	}
+	s.SetPool(poolB, 2)
+	s.Append(40, ev(KindDeposited, poolA, alice, 10, 11, 0))
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Head() != 40 || loaded.Pools()[poolB] != 2 || len(loaded.Events(Filter{})) != 1 {
+		t.Fatalf("round trip lost data: head %d pools %v", loaded.Head(), loaded.Pools())
+	}
+}
@@ -0,0 +1,227 @@
+// Package follow is the reconnect, backfill and dedupe loop shared by
+// package subscription, package stream and the gRPC WatchPlayer stream.
+//
+// A Loop opens a live feed, reads the head, replays every block from its
+// cursor to the head in chunks, and then forwards live items until the feed
+// fails. Failures are retried with exponential backoff. Items are keyed by
+// (block hash, tx hash, log index, removed) and delivered once each, so the
+// overlap between backfill and live feed, and replays by the node, are
+// dropped.
+package follow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Defaults for zero Config fields.
+const (
+	DefaultMinBackoff  = time.Second
+	DefaultMaxBackoff  = time.Minute
+	DefaultChunkSize   = 5000
+	DefaultDedupeDepth = 256
+)
+
+// ErrResubscribe is returned by a deliver func to reopen the feed at once,
+// without backoff or OnError. The next session resumes at the block of the
+// item just delivered.
+var ErrResubscribe = errors.New("follow: resubscribe")
+
+// Source is where a Loop reads items of type T from.
+type Source[T any] struct {
+	Head ethereum.BlockNumberReader
+	// Watch opens the live feed; it is called once per session.
+	Watch func(ctx context.Context, sink chan<- T) (ethereum.Subscription, error)
+	// Filter returns the items of blocks from through to, inclusive, with
+	// the same filter as the Watch call of the same session.
+	Filter func(ctx context.Context, from, to uint64) ([]T, error)
+	// Log returns the raw log of an item.
+	Log func(v T) types.Log
+}
+
+// Config tunes a Loop. Zero fields take the defaults.
+type Config struct {
+	// Name prefixes error messages.
+	Name string
+	// From is the first block to deliver; zero starts at the head.
+	From                   uint64
+	MinBackoff, MaxBackoff time.Duration
+	ChunkSize              uint64
+	DedupeDepth            uint64
+	// OnError receives every session failure before the retry.
+	OnError func(error)
+}
+
+type key struct {
+	block   common.Hash
+	tx      common.Hash
+	index   uint
+	removed bool
+}
+
+// Loop delivers the items of one Source.
+type Loop[T any] struct {
+	src     Source[T]
+	cfg     Config
+	deliver func(ctx context.Context, v T) error
+
+	// next is the first block not yet known to be fully delivered.
+	next   atomic.Uint64
+	newest uint64
+	seen   map[key]uint64
+}
+
+// New returns a Loop handing every new item of src to deliver. An error
+// from deliver other than ErrResubscribe ends the session without marking
+// the item delivered.
+func New[T any](src Source[T], cfg Config, deliver func(ctx context.Context, v T) error) *Loop[T] {
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = DefaultChunkSize
+	}
+	if cfg.DedupeDepth == 0 {
+		cfg.DedupeDepth = DefaultDedupeDepth
+	}
+	l := &Loop[T]{src: src, cfg: cfg, deliver: deliver, seen: make(map[key]uint64)}
+	l.next.Store(cfg.From)
+	return l
+}
+
+// Cursor returns the block to resume from after a restart.
+func (l *Loop[T]) Cursor() uint64 {
+	return l.next.Load()
+}
+
+// Run delivers items until ctx is done, reconnecting on failure.
+func (l *Loop[T]) Run(ctx context.Context) error {
+	backoff := l.cfg.MinBackoff
+	for {
+		established, err := l.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ErrResubscribe) {
+			continue
+		}
+		if established {
+			backoff = l.cfg.MinBackoff
+		}
+		if l.cfg.OnError != nil {
+			l.cfg.OnError(err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = min(2*backoff, l.cfg.MaxBackoff)
+	}
+}
+
+// session subscribes, backfills everything since the cursor and then
+// forwards live items until the feed fails. established reports whether
+// the backfill completed.
+func (l *Loop[T]) session(ctx context.Context) (established bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	live := make(chan T, 256)
+	sub, err := l.src.Watch(ctx, live)
+	if err != nil {
+		return false, fmt.Errorf("%s: watch: %w", l.cfg.Name, err)
+	}
+	defer sub.Unsubscribe()
+
+	// Read the head only once subscribed, so no block falls between the
+	// backfill and the live feed.
+	head, err := l.src.Head.BlockNumber(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: head: %w", l.cfg.Name, err)
+	}
+	if l.next.Load() == 0 {
+		l.next.Store(head + 1)
+	}
+	if err := l.backfill(ctx, head); err != nil {
+		return false, err
+	}
+	for {
+		select {
+		case v := <-live:
+			if err := l.emit(ctx, v); err != nil {
+				return true, err
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("closed by node")
+			}
+			return true, fmt.Errorf("%s: subscription: %w", l.cfg.Name, err)
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+	}
+}
+
+func (l *Loop[T]) backfill(ctx context.Context, head uint64) error {
+	for from := l.next.Load(); from <= head; {
+		to := min(from+l.cfg.ChunkSize-1, head)
+		items, err := l.src.Filter(ctx, from, to)
+		if err != nil {
+			return fmt.Errorf("%s: backfill %d-%d: %w", l.cfg.Name, from, to, err)
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			a, b := l.src.Log(items[i]), l.src.Log(items[j])
+			if a.BlockNumber != b.BlockNumber {
+				return a.BlockNumber < b.BlockNumber
+			}
+			return a.Index < b.Index
+		})
+		for _, v := range items {
+			if err := l.emit(ctx, v); err != nil {
+				return err
+			}
+		}
+		l.next.Store(to + 1)
+		from = to + 1
+	}
+	return nil
+}
+
+// emit delivers v unless it was delivered before.
+func (l *Loop[T]) emit(ctx context.Context, v T) error {
+	lg := l.src.Log(v)
+	k := key{lg.BlockHash, lg.TxHash, lg.Index, lg.Removed}
+	if _, dup := l.seen[k]; dup {
+		return nil
+	}
+	err := l.deliver(ctx, v)
+	if err != nil && !errors.Is(err, ErrResubscribe) {
+		return err
+	}
+	l.seen[k] = lg.BlockNumber
+	if lg.BlockNumber > l.newest {
+		l.newest = lg.BlockNumber
+		for k, b := range l.seen {
+			if b+l.cfg.DedupeDepth < l.newest {
+				delete(l.seen, k)
+			}
+		}
+	}
+	// Later logs of this block may still be missed, so a reconnect resumes
+	// at the block itself.
+	if lg.BlockNumber > l.next.Load() || err != nil {
+		l.next.Store(lg.BlockNumber)
+	}
+	return err
+}
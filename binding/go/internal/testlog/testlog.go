@@ -0,0 +1,39 @@
+// Package testlog builds ABI-encoded event logs for tests.
+package testlog
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ABIProvider is satisfied by the generated *bind.MetaData values.
+type ABIProvider interface {
+	GetAbi() (*abi.ABI, error)
+}
+
+// Event encodes the named event emitted by addr. indexed holds the topic
+// values after the signature; data holds the non-indexed arguments in order.
+func Event(t testing.TB, meta ABIProvider, addr common.Address, name string, indexed []common.Hash, data ...any) types.Log {
+	t.Helper()
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, ok := parsed.Events[name]
+	if !ok {
+		t.Fatalf("no event %s in ABI", name)
+	}
+	packed, err := ev.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return types.Log{Address: addr, Topics: append([]common.Hash{ev.ID}, indexed...), Data: packed}
+}
+
+// Topic left-pads an address into an indexed topic.
+func Topic(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
@@ -0,0 +1,243 @@
+// Package invariant checks protocol accounting invariants against a live
+// deployment.
+//
+// For every pool at a given block it verifies that:
+//
+//   - the balances of all known depositors sum to totalDeposited;
+//   - the pool holds at least totalDeposited of its deposit token;
+//   - each reward token balance covers all pending rewards plus the
+//     reclaimable amount;
+//   - rewardPerTokenStored never decreases between runs.
+//
+// The last check needs the previous run's accumulators, kept in a State file.
+package invariant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/erc20"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+// ErrIndexBehind is returned by Run for blocks past the index head, whose
+// depositors the index has not seen yet.
+var ErrIndexBehind = errors.New("invariant: index does not reach block")
+
+// Check names one invariant.
+type Check string
+
+// Invariants evaluated by Run.
+const (
+	CheckDepositSum      Check = "deposit-sum"
+	CheckDepositBacking  Check = "deposit-backing"
+	CheckRewardBacking   Check = "reward-backing"
+	CheckMonotonicReward Check = "monotonic-reward-per-token"
+)
+
+// Finding is one invariant violation.
+type Finding struct {
+	Check    Check          `json:"check"`
+	Block    uint64         `json:"block"`
+	PoolID   *big.Int       `json:"poolId"`
+	Pool     common.Address `json:"pool"`
+	Token    common.Address `json:"token,omitempty"`
+	Expected *big.Int       `json:"expected"`
+	Actual   *big.Int       `json:"actual"`
+	Message  string         `json:"message"`
+}
+
+type rewardState struct {
+	token       common.Address
+	removed     bool
+	balance     *big.Int
+	pendingSum  *big.Int
+	reclaimable *big.Int
+	perToken    *big.Int // nil for removed tokens; getRewardToken reverts for them
+}
+
+type poolState struct {
+	id             *big.Int
+	addr           common.Address
+	totalDeposited *big.Int
+	depositBalance *big.Int
+	balanceSum     *big.Int
+	rewards        []rewardState
+}
+
+// Checker evaluates the invariants of one network.
+type Checker struct {
+	net   *config.Network
+	store *indexer.Store
+}
+
+// New returns a Checker that takes depositors from store.
+func New(net *config.Network, store *indexer.Store) *Checker {
+	return &Checker{net: net, store: store}
+}
+
+// Run checks every pool at block against prev, the state of the previous
+// run (nil for the first), and returns the findings together with the state
+// to persist for the next run. block must not be past the store's head.
+func (c *Checker) Run(ctx context.Context, block uint64, prev *State) ([]Finding, *State, error) {
+	if head := c.store.Head(); block > head {
+		return nil, nil, fmt.Errorf("%w: block %d, index head %d", ErrIndexBehind, block, head)
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)}
+	pools, err := c.net.Pools(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	states := make([]poolState, 0, len(pools))
+	for _, p := range pools {
+		st, err := c.collect(opts, p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invariant: pool %s: %w", p.ID, err)
+		}
+		states = append(states, st)
+	}
+	findings, next := evaluate(block, states, prev)
+	return findings, next, nil
+}
+
+func (c *Checker) collect(opts *bind.CallOpts, p config.PoolRef) (poolState, error) {
+	st := poolState{id: p.ID, addr: p.Address, balanceSum: new(big.Int)}
+	var err error
+	if st.totalDeposited, err = p.Pool.TotalDeposited(opts); err != nil {
+		return st, err
+	}
+	depositToken, err := p.Pool.DepositToken(opts)
+	if err != nil {
+		return st, err
+	}
+	if st.depositBalance, err = c.balanceOf(opts, depositToken, p.Address); err != nil {
+		return st, err
+	}
+	depositors := c.store.Depositors(p.Address)
+	for _, acct := range depositors {
+		bal, err := p.Pool.Balances(opts, acct)
+		if err != nil {
+			return st, err
+		}
+		st.balanceSum.Add(st.balanceSum, bal)
+	}
+
+	active, err := p.Pool.GetRewardTokens(opts)
+	if err != nil {
+		return st, err
+	}
+	removed, err := p.Pool.GetRemovedRewardTokens(opts)
+	if err != nil {
+		return st, err
+	}
+	for i, token := range append(active, removed...) {
+		rs := rewardState{token: token, removed: i >= len(active), pendingSum: new(big.Int)}
+		if rs.balance, err = c.balanceOf(opts, token, p.Address); err != nil {
+			return st, err
+		}
+		if rs.reclaimable, err = p.Pool.GetReclaimableAmount(opts, token); err != nil {
+			return st, err
+		}
+		if !rs.removed {
+			rt, err := p.Pool.GetRewardToken(opts, token)
+			if err != nil {
+				return st, err
+			}
+			rs.perToken = rt.RewardPerTokenStored
+		}
+		for _, acct := range depositors {
+			pending, err := c.pendingOf(opts, p, acct, token, rs.removed)
+			if err != nil {
+				return st, err
+			}
+			rs.pendingSum.Add(rs.pendingSum, pending)
+		}
+		st.rewards = append(st.rewards, rs)
+	}
+	return st, nil
+}
+
+func (c *Checker) balanceOf(opts *bind.CallOpts, token, holder common.Address) (*big.Int, error) {
+	t, err := erc20.NewCaller(token, c.net.Client)
+	if err != nil {
+		return nil, err
+	}
+	return t.BalanceOf(opts, holder)
+}
+
+// pendingOf returns what acct could claim of token. For removed tokens
+// pendingReward skips the simulated sync, so the removed-token view is used
+// to stay consistent with what the pool pays out.
+func (c *Checker) pendingOf(opts *bind.CallOpts, p config.PoolRef, acct, token common.Address, removed bool) (*big.Int, error) {
+	if !removed {
+		return p.Pool.PendingReward(opts, acct, token)
+	}
+	res, err := p.Pool.GetRemovedTokenRewards(opts, acct)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range res.Tokens {
+		if t == token {
+			return res.Rewards[i], nil
+		}
+	}
+	return new(big.Int), nil
+}
+
+func evaluate(block uint64, pools []poolState, prev *State) ([]Finding, *State) {
+	next := &State{Block: block, RewardPerToken: make(map[string]*big.Int)}
+	compare := prev != nil && block >= prev.Block
+	var out []Finding
+	for _, p := range pools {
+		if p.balanceSum.Cmp(p.totalDeposited) != 0 {
+			out = append(out, Finding{
+				Check: CheckDepositSum, Block: block, PoolID: p.id, Pool: p.addr,
+				Expected: p.totalDeposited, Actual: p.balanceSum,
+				Message: "sum of depositor balances differs from totalDeposited",
+			})
+		}
+		if p.depositBalance.Cmp(p.totalDeposited) < 0 {
+			out = append(out, Finding{
+				Check: CheckDepositBacking, Block: block, PoolID: p.id, Pool: p.addr,
+				Expected: p.totalDeposited, Actual: p.depositBalance,
+				Message: "deposit token balance is below totalDeposited",
+			})
+		}
+		for _, r := range p.rewards {
+			owed := new(big.Int).Add(r.pendingSum, r.reclaimable)
+			if r.balance.Cmp(owed) < 0 {
+				out = append(out, Finding{
+					Check: CheckRewardBacking, Block: block, PoolID: p.id, Pool: p.addr, Token: r.token,
+					Expected: owed, Actual: r.balance,
+					Message: "reward token balance does not cover pending rewards plus reclaimable",
+				})
+			}
+			if r.perToken == nil {
+				continue
+			}
+			key := stateKey(p.addr, r.token)
+			next.RewardPerToken[key] = r.perToken
+			if !compare {
+				continue
+			}
+			if last, ok := prev.RewardPerToken[key]; ok && r.perToken.Cmp(last) < 0 {
+				out = append(out, Finding{
+					Check: CheckMonotonicReward, Block: block, PoolID: p.id, Pool: p.addr, Token: r.token,
+					Expected: last, Actual: r.perToken,
+					Message: fmt.Sprintf("rewardPerTokenStored decreased since block %d", prev.Block),
+				})
+			}
+		}
+	}
+	if !compare && prev != nil {
+		// Checking an older block must not rewind the stored accumulators.
+		return out, prev
+	}
+	return out, next
+}
@@ -0,0 +1,124 @@
+package invariant
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+var (
+	pool  = common.HexToAddress("0xb0")
+	token = common.HexToAddress("0x70")
+)
+
+func healthy() poolState {
+	return poolState{
+		id:             big.NewInt(1),
+		addr:           pool,
+		totalDeposited: big.NewInt(100),
+		depositBalance: big.NewInt(100),
+		balanceSum:     big.NewInt(100),
+		rewards: []rewardState{{
+			token:       token,
+			balance:     big.NewInt(50),
+			pendingSum:  big.NewInt(40),
+			reclaimable: big.NewInt(10),
+			perToken:    big.NewInt(7),
+		}},
+	}
+}
+
+func checks(fs []Finding) []Check {
+	out := make([]Check, len(fs))
+	for i, f := range fs {
+		out[i] = f.Check
+	}
+	return out
+}
+
+func TestEvaluateHealthy(t *testing.T) {
+	fs, next := evaluate(10, []poolState{healthy()}, nil)
+	if len(fs) != 0 {
+		t.Fatalf("unexpected findings: %v", checks(fs))
+	}
+	if next.Block != 10 || next.RewardPerToken[stateKey(pool, token)].Int64() != 7 {
+		t.Fatalf("state not recorded: %+v", next)
+	}
+}
+
+func TestEvaluateViolations(t *testing.T) {
+	p := healthy()
+	p.balanceSum = big.NewInt(90)
+	p.depositBalance = big.NewInt(99)
+	p.rewards[0].balance = big.NewInt(49)
+	p.rewards[0].perToken = big.NewInt(6)
+	prev := &State{Block: 5, RewardPerToken: map[string]*big.Int{stateKey(pool, token): big.NewInt(7)}}
+
+	fs, _ := evaluate(10, []poolState{p}, prev)
+	want := []Check{CheckDepositSum, CheckDepositBacking, CheckRewardBacking, CheckMonotonicReward}
+	got := checks(fs)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEvaluateOlderBlockKeepsState(t *testing.T) {
+	p := healthy()
+	p.rewards[0].perToken = big.NewInt(1)
+	prev := &State{Block: 20, RewardPerToken: map[string]*big.Int{stateKey(pool, token): big.NewInt(7)}}
+	fs, next := evaluate(10, []poolState{p}, prev)
+	if len(fs) != 0 {
+		t.Fatalf("historical run must not compare against newer state: %v", checks(fs))
+	}
+	if next != prev {
+		t.Fatal("historical run must not replace newer state")
+	}
+}
+
+func TestRemovedTokensSkipMonotonicCheck(t *testing.T) {
+	p := healthy()
+	p.rewards[0].removed = true
+	p.rewards[0].perToken = nil
+	prev := &State{Block: 5, RewardPerToken: map[string]*big.Int{stateKey(pool, token): big.NewInt(7)}}
+	fs, next := evaluate(10, []poolState{p}, prev)
+	if len(fs) != 0 || len(next.RewardPerToken) != 0 {
+		t.Fatalf("removed token handled as active: %v %v", checks(fs), next.RewardPerToken)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if s, err := LoadState(path); s != nil || err != nil {
+		t.Fatalf("missing state: %v %v", s, err)
+	}
+	_, next := evaluate(10, []poolState{healthy()}, nil)
+	if err := next.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadState(path)
+	if err != nil || s.RewardPerToken[stateKey(pool, token)].Int64() != 7 {
+		t.Fatalf("round trip: %+v %v", s, err)
+	}
+}
+
+func TestRunRejectsBlockPastIndex(t *testing.T) {
+	store := indexer.NewStore()
+	if err := store.Append(50); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := New(nil, store).Run(context.Background(), 51, nil)
+	if !errors.Is(err, ErrIndexBehind) {
+		t.Fatalf("err %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package invariant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// State carries accumulators from one run to the next.
+type State struct {
+	Block uint64 `json:"block"`
+	// RewardPerToken is keyed by "pool:token".
+	RewardPerToken map[string]*big.Int `json:"rewardPerToken"`
+}
+
+func stateKey(pool, token common.Address) string {
+	return pool.Hex() + ":" + token.Hex()
+}
+
+// LoadState reads the state file at path; a missing file yields nil.
+func LoadState(path string) (*State, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := new(State)
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("invariant: parse %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the state to path.
+func (s *State) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
@@ -0,0 +1,64 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// Mismatch is an account whose all-time depositor score differs from its
+// balance in the pool.
+type Mismatch struct {
+	PoolID  uint64         `json:"poolId"`
+	Account common.Address `json:"account"`
+	Score   *big.Int       `json:"score"`
+	Balance *big.Int       `json:"balance"`
+}
+
+// Check compares every all-time depositor score with balances(account) read
+// from its pool at the board's head.
+func (b *Board) Check(ctx context.Context, net *config.Network) ([]Mismatch, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(b.Head())}
+	return b.check(func(pool common.Address, acct common.Address) (*big.Int, error) {
+		p, err := net.Pool(pool)
+		if err != nil {
+			return nil, err
+		}
+		return p.Balances(opts, acct)
+	})
+}
+
+func (b *Board) check(balance func(pool, acct common.Address) (*big.Int, error)) ([]Mismatch, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ids := make([]uint64, 0, len(b.pools))
+	for id := range b.windows[AllTime].depositors {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var out []Mismatch
+	for _, id := range ids {
+		r := b.windows[AllTime].depositors[id]
+		accts := make([]common.Address, 0, len(r.scores))
+		for acct := range r.scores {
+			accts = append(accts, acct)
+		}
+		sort.Slice(accts, func(i, j int) bool { return accts[i].Cmp(accts[j]) < 0 })
+		for _, acct := range accts {
+			bal, err := balance(b.pools[id], acct)
+			if err != nil {
+				return nil, fmt.Errorf("leaderboard: balance of %s in pool %d: %w", acct, id, err)
+			}
+			if score := r.scores[acct]; score.Cmp(bal) != 0 {
+				out = append(out, Mismatch{PoolID: id, Account: acct, Score: score, Balance: bal})
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,317 @@
+// Package leaderboard ranks depositors per pool and reward earners per token
+// from indexed pool events.
+//
+// A Board is fed incrementally from an indexer.Store with Sync and can be
+// rebuilt from scratch with Build. Every board is kept for the all-time
+// window, each configured season and, optionally, each ISO week. Depositors
+// are ranked by net deposits (Deposited minus Withdrawn) within the window,
+// which for the all-time window is the deposited balance; earners are
+// ranked by the amount of a token claimed within the window. Accounts with
+// equal scores share a rank and are listed by address, so pages are stable.
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+var (
+	// ErrUnknownWindow is returned for a window name the board does not keep.
+	ErrUnknownWindow = errors.New("leaderboard: unknown window")
+	// ErrInvalidLimit is returned for page sizes below one.
+	ErrInvalidLimit = errors.New("leaderboard: limit must be at least 1")
+)
+
+// Names of the built-in windows.
+const (
+	AllTime = "all"
+	// CurrentWeek resolves to the ISO week of the current time.
+	CurrentWeek = "week"
+)
+
+// Kind selects the ranked quantity.
+type Kind string
+
+// Leaderboard kinds.
+const (
+	Depositors Kind = "depositors"
+	Earners    Kind = "earners"
+)
+
+// Window is a named time range. Events with Start <= time < End count
+// towards it; a zero Start or End leaves that side open.
+type Window struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start,omitzero"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+func (w Window) contains(t uint64) bool {
+	at := time.Unix(int64(t), 0)
+	return (w.Start.IsZero() || !at.Before(w.Start)) && (w.End.IsZero() || at.Before(w.End))
+}
+
+// Week returns the ISO week containing t, named like 2024-W13.
+func Week(t time.Time) Window {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	year, week := t.ISOWeek()
+	return Window{Name: fmt.Sprintf("%d-W%02d", year, week), Start: start, End: start.AddDate(0, 0, 7)}
+}
+
+// Config selects the windows a Board keeps besides the all-time window.
+type Config struct {
+	Seasons []Window `json:"seasons"`
+	Weekly  bool     `json:"weekly"`
+}
+
+// Entry is one ranked account.
+type Entry struct {
+	Rank    int            `json:"rank"`
+	Account common.Address `json:"account"`
+	Score   *big.Int       `json:"score"`
+}
+
+// Page is a slice of one leaderboard.
+type Page struct {
+	Window Window `json:"window"`
+	Kind   Kind   `json:"kind"`
+	// Total is the number of ranked accounts.
+	Total   int     `json:"total"`
+	Offset  int     `json:"offset"`
+	Entries []Entry `json:"entries"`
+}
+
+type windowBoards struct {
+	window     Window
+	depositors map[uint64]*ranking
+	earners    map[common.Address]*ranking
+}
+
+func newWindowBoards(w Window) *windowBoards {
+	return &windowBoards{window: w, depositors: make(map[uint64]*ranking), earners: make(map[common.Address]*ranking)}
+}
+
+func (wb *windowBoards) apply(ev *indexer.Event) {
+	switch ev.Kind {
+	case indexer.KindDeposited, indexer.KindWithdrawn:
+		r, ok := wb.depositors[ev.PoolID]
+		if !ok {
+			r = newRanking()
+			wb.depositors[ev.PoolID] = r
+		}
+		delta := ev.Amount
+		if ev.Kind == indexer.KindWithdrawn {
+			delta = new(big.Int).Neg(delta)
+		}
+		r.add(ev.Account, delta)
+	case indexer.KindRewardClaimed:
+		r, ok := wb.earners[ev.Token]
+		if !ok {
+			r = newRanking()
+			wb.earners[ev.Token] = r
+		}
+		r.add(ev.Account, ev.Amount)
+	}
+}
+
+type position struct {
+	block uint64
+	index uint
+}
+
+// Board holds the leaderboards of every window. It is safe for concurrent
+// use.
+type Board struct {
+	mu      sync.RWMutex
+	cfg     Config
+	now     func() time.Time
+	head    uint64
+	last    *position
+	pools   map[uint64]common.Address
+	windows map[string]*windowBoards
+}
+
+// New returns an empty board.
+func New(cfg Config) *Board {
+	b := &Board{cfg: cfg, now: time.Now, pools: make(map[uint64]common.Address), windows: make(map[string]*windowBoards)}
+	b.windows[AllTime] = newWindowBoards(Window{Name: AllTime})
+	for _, w := range cfg.Seasons {
+		b.windows[w.Name] = newWindowBoards(w)
+	}
+	return b
+}
+
+// Build returns a board computed from every event in store.
+func Build(store *indexer.Store, cfg Config) *Board {
+	b := New(cfg)
+	b.Sync(store)
+	return b
+}
+
+// Head returns the last indexed block the board reflects.
+func (b *Board) Head() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.head
+}
+
+// Apply updates the board with events in block order and advances its head.
+// Events at or before the last applied one are ignored, so overlapping
+// batches are safe.
+func (b *Board) Apply(head uint64, evs ...indexer.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := range evs {
+		ev := &evs[i]
+		if b.last != nil && (ev.Block < b.last.block || ev.Block == b.last.block && ev.LogIndex <= b.last.index) {
+			continue
+		}
+		b.last = &position{ev.Block, ev.LogIndex}
+		b.pools[ev.PoolID] = ev.Pool
+		for _, wb := range b.windowsOf(ev.Time) {
+			wb.apply(ev)
+		}
+	}
+	b.head = max(b.head, head)
+}
+
+// windowsOf returns the windows containing t, creating its week if weekly
+// boards are kept.
+func (b *Board) windowsOf(t uint64) []*windowBoards {
+	var out []*windowBoards
+	for _, wb := range b.windows {
+		if wb.window.contains(t) {
+			out = append(out, wb)
+		}
+	}
+	if b.cfg.Weekly {
+		w := Week(time.Unix(int64(t), 0))
+		if _, ok := b.windows[w.Name]; !ok {
+			wb := newWindowBoards(w)
+			b.windows[w.Name] = wb
+			out = append(out, wb)
+		}
+	}
+	return out
+}
+
+// Sync applies the events store has indexed since the last call.
+func (b *Board) Sync(store *indexer.Store) {
+	var from uint64
+	b.mu.RLock()
+	if b.last != nil {
+		from = b.last.block
+	}
+	b.mu.RUnlock()
+	head := store.Head()
+	b.Apply(head, store.Events(indexer.Filter{
+		Kinds:     []indexer.Kind{indexer.KindDeposited, indexer.KindWithdrawn, indexer.KindRewardClaimed},
+		FromBlock: from,
+		ToBlock:   head,
+	})...)
+}
+
+// Windows returns the windows the board keeps, all-time first, then by
+// start time.
+func (b *Board) Windows() []Window {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]Window, 0, len(b.windows))
+	for _, wb := range b.windows {
+		out = append(out, wb.window)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name == AllTime || out[j].Name == AllTime {
+			return out[i].Name == AllTime
+		}
+		if !out[i].Start.Equal(out[j].Start) {
+			return out[i].Start.Before(out[j].Start)
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// lookup returns the window named name and its ranking for kind and key
+// (a pool ID or token address), which is empty if nothing was ranked yet.
+func (b *Board) lookup(name string, kind Kind, pool uint64, token common.Address) (Window, *ranking, error) {
+	if name == CurrentWeek && b.cfg.Weekly {
+		name = Week(b.now()).Name
+	}
+	wb, ok := b.windows[name]
+	if !ok {
+		if w := Week(b.now()); b.cfg.Weekly && name == w.Name {
+			return w, newRanking(), nil
+		}
+		return Window{}, nil, fmt.Errorf("%w %q", ErrUnknownWindow, name)
+	}
+	var r *ranking
+	switch kind {
+	case Depositors:
+		r = wb.depositors[pool]
+	case Earners:
+		r = wb.earners[token]
+	default:
+		return Window{}, nil, fmt.Errorf("leaderboard: unknown kind %q", kind)
+	}
+	if r == nil {
+		r = newRanking()
+	}
+	return wb.window, r, nil
+}
+
+func (b *Board) page(window string, kind Kind, pool uint64, token common.Address, offset, limit int) (Page, error) {
+	if limit < 1 {
+		return Page{}, fmt.Errorf("%w: got %d", ErrInvalidLimit, limit)
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	w, r, err := b.lookup(window, kind, pool, token)
+	if err != nil {
+		return Page{}, err
+	}
+	return Page{Window: w, Kind: kind, Total: len(r.ranked), Offset: offset, Entries: r.page(max(offset, 0), limit)}, nil
+}
+
+func (b *Board) entry(window string, kind Kind, pool uint64, token common.Address, acct common.Address) (Entry, bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, r, err := b.lookup(window, kind, pool, token)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	e, ok := r.entry(acct)
+	return e, ok, nil
+}
+
+// TopDepositors returns up to limit depositors of pool from offset.
+func (b *Board) TopDepositors(window string, pool uint64, offset, limit int) (Page, error) {
+	return b.page(window, Depositors, pool, common.Address{}, offset, limit)
+}
+
+// TopEarners returns up to limit earners of token from offset.
+func (b *Board) TopEarners(window string, token common.Address, offset, limit int) (Page, error) {
+	return b.page(window, Earners, 0, token, offset, limit)
+}
+
+// Depositor returns acct's entry on the depositors board of pool; ok is
+// false if it is not ranked.
+func (b *Board) Depositor(window string, pool uint64, acct common.Address) (e Entry, ok bool, err error) {
+	return b.entry(window, Depositors, pool, common.Address{}, acct)
+}
+
+// Earner returns acct's entry on the earners board of token; ok is false if
+// it is not ranked.
+func (b *Board) Earner(window string, token common.Address, acct common.Address) (e Entry, ok bool, err error) {
+	return b.entry(window, Earners, 0, token, acct)
+}
@@ -0,0 +1,171 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+var (
+	pool  = common.HexToAddress("0x01")
+	token = common.HexToAddress("0x70")
+	a     = common.HexToAddress("0xaa")
+	b     = common.HexToAddress("0xbb")
+	c     = common.HexToAddress("0xcc")
+	d     = common.HexToAddress("0xdd")
+)
+
+// Monday 2024-03-25 00:00 UTC.
+const monday = 1711324800
+
+func ev(kind indexer.Kind, acct common.Address, amount int64, block uint64, at uint64) indexer.Event {
+	e := indexer.Event{Kind: kind, PoolID: 1, Pool: pool, Account: acct, Amount: big.NewInt(amount), Block: block, Time: at}
+	if kind == indexer.KindRewardClaimed {
+		e.Token = token
+	}
+	return e
+}
+
+func events() []indexer.Event {
+	day := uint64(24 * 3600)
+	return []indexer.Event{
+		ev(indexer.KindDeposited, a, 100, 1, monday),
+		ev(indexer.KindDeposited, b, 50, 2, monday+day),
+		ev(indexer.KindDeposited, c, 50, 3, monday+2*day),
+		ev(indexer.KindDeposited, d, 80, 4, monday+8*day),
+		ev(indexer.KindWithdrawn, a, 60, 5, monday+9*day),
+		ev(indexer.KindRewardClaimed, b, 7, 6, monday+9*day),
+		ev(indexer.KindRewardClaimed, c, 9, 7, monday+10*day),
+	}
+}
+
+func store(t *testing.T) *indexer.Store {
+	s := indexer.NewStore()
+	if err := s.Append(10, events()...); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func ranks(p Page) []string {
+	var out []string
+	for _, e := range p.Entries {
+		out = append(out, fmt.Sprintf("%x:%s#%d", e.Account[19:], e.Score, e.Rank))
+	}
+	return out
+}
+
+func TestAllTimeTies(t *testing.T) {
+	bd := Build(store(t), Config{})
+	p, err := bd.TopDepositors(AllTime, 1, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"dd:80#1", "bb:50#2", "cc:50#2", "aa:40#4"}
+	if got := ranks(p); !reflect.DeepEqual(got, want) || p.Total != 4 {
+		t.Fatalf("got %v (total %d), want %v", got, p.Total, want)
+	}
+	p, _ = bd.TopDepositors(AllTime, 1, 2, 1)
+	if got := ranks(p); !reflect.DeepEqual(got, []string{"cc:50#2"}) {
+		t.Errorf("page 2: %v", got)
+	}
+	p, _ = bd.TopDepositors(AllTime, 1, 9, 5)
+	if len(p.Entries) != 0 || p.Total != 4 {
+		t.Errorf("past end: %+v", p)
+	}
+	for _, limit := range []int{0, -1} {
+		if _, err := bd.TopEarners(AllTime, token, 0, limit); !errors.Is(err, ErrInvalidLimit) {
+			t.Errorf("limit %d: err %v", limit, err)
+		}
+	}
+	if e, ok, _ := bd.Depositor(AllTime, 1, c); !ok || e.Rank != 2 {
+		t.Errorf("entry of c: %+v %v", e, ok)
+	}
+	p, _ = bd.TopEarners(AllTime, token, 0, 10)
+	if got := ranks(p); !reflect.DeepEqual(got, []string{"cc:9#1", "bb:7#2"}) {
+		t.Errorf("earners: %v", got)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	season := Window{Name: "s1", Start: time.Unix(monday+7*24*3600, 0), End: time.Unix(monday+14*24*3600, 0)}
+	bd := Build(store(t), Config{Seasons: []Window{season}, Weekly: true})
+	p, err := bd.TopDepositors("s1", 1, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a's withdrawal makes its net deposits negative, so it is not ranked.
+	if got := ranks(p); !reflect.DeepEqual(got, []string{"dd:80#1"}) {
+		t.Errorf("season: %v", got)
+	}
+	p, _ = bd.TopDepositors("2024-W13", 1, 0, 10)
+	if got := ranks(p); !reflect.DeepEqual(got, []string{"aa:100#1", "bb:50#2", "cc:50#2"}) {
+		t.Errorf("week 13: %v", got)
+	}
+	var names []string
+	for _, w := range bd.Windows() {
+		names = append(names, w.Name)
+	}
+	if want := []string{AllTime, "2024-W13", "2024-W14", "s1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("windows %v, want %v", names, want)
+	}
+	if _, err := bd.TopDepositors("s2", 1, 0, 10); !errors.Is(err, ErrUnknownWindow) {
+		t.Errorf("unknown window: %v", err)
+	}
+	bd.now = func() time.Time { return time.Unix(monday+8*24*3600, 0) }
+	if p, _ := bd.TopDepositors(CurrentWeek, 1, 0, 10); p.Window.Name != "2024-W14" || p.Total != 1 {
+		t.Errorf("current week: %+v", p)
+	}
+}
+
+func TestIncrementalMatchesRebuild(t *testing.T) {
+	cfg := Config{Weekly: true}
+	evs := events()
+	s := indexer.NewStore()
+	inc := New(cfg)
+	for i, e := range evs {
+		if err := s.Append(e.Block, evs[i]); err != nil {
+			t.Fatal(err)
+		}
+		inc.Sync(s)
+		inc.Sync(s)
+	}
+	full := Build(s, cfg)
+	for _, w := range full.Windows() {
+		x, _ := inc.TopDepositors(w.Name, 1, 0, 100)
+		y, _ := full.TopDepositors(w.Name, 1, 0, 100)
+		if !reflect.DeepEqual(ranks(x), ranks(y)) {
+			t.Errorf("%s: incremental %v, rebuilt %v", w.Name, ranks(x), ranks(y))
+		}
+	}
+	if inc.Head() != s.Head() {
+		t.Errorf("head %d, want %d", inc.Head(), s.Head())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s := store(t)
+	bd := Build(s, Config{})
+	balances := s.Balances(pool, 0)
+	balance := func(_, acct common.Address) (*big.Int, error) {
+		if bal, ok := balances[acct]; ok {
+			return bal, nil
+		}
+		return new(big.Int), nil
+	}
+	if m, err := bd.check(balance); err != nil || len(m) != 0 {
+		t.Fatalf("clean: %v %v", m, err)
+	}
+	balances[b] = big.NewInt(49)
+	m, err := bd.check(balance)
+	if err != nil || len(m) != 1 || m[0].Account != b || m[0].Score.Int64() != 50 {
+		t.Errorf("mismatch: %+v %v", m, err)
+	}
+}
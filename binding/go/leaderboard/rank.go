@@ -0,0 +1,103 @@
+package leaderboard
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ranking is one ranked set. scores holds every non-zero score, including
+// negative ones a window can accumulate from withdrawals of earlier
+// deposits; ranked holds the accounts with a positive score ordered by
+// descending score, then ascending address.
+type ranking struct {
+	scores map[common.Address]*big.Int
+	ranked []common.Address
+}
+
+func newRanking() *ranking {
+	return &ranking{scores: make(map[common.Address]*big.Int)}
+}
+
+// before reports whether (x, a) sorts before (y, b).
+func before(x *big.Int, a common.Address, y *big.Int, b common.Address) bool {
+	if c := x.Cmp(y); c != 0 {
+		return c > 0
+	}
+	return bytes.Compare(a[:], b[:]) < 0
+}
+
+// search returns the position of (score, acct) in ranked.
+func (r *ranking) search(score *big.Int, acct common.Address) int {
+	return sort.Search(len(r.ranked), func(i int) bool {
+		o := r.ranked[i]
+		return !before(r.scores[o], o, score, acct)
+	})
+}
+
+// add changes acct's score by delta and moves it to its new position.
+func (r *ranking) add(acct common.Address, delta *big.Int) {
+	old, ok := r.scores[acct]
+	if ok && old.Sign() > 0 {
+		i := r.search(old, acct)
+		r.ranked = append(r.ranked[:i], r.ranked[i+1:]...)
+	}
+	score := new(big.Int).Add(delta, zeroIfNil(old))
+	if score.Sign() == 0 {
+		delete(r.scores, acct)
+		return
+	}
+	r.scores[acct] = score
+	if score.Sign() > 0 {
+		i := r.search(score, acct)
+		r.ranked = append(r.ranked, common.Address{})
+		copy(r.ranked[i+1:], r.ranked[i:])
+		r.ranked[i] = acct
+	}
+}
+
+// rank returns the 1-based rank of position i. Equal scores share the rank
+// of the first of them (1, 2, 2, 4).
+func (r *ranking) rank(i int) int {
+	score := r.scores[r.ranked[i]]
+	return sort.Search(i, func(j int) bool { return r.scores[r.ranked[j]].Cmp(score) <= 0 }) + 1
+}
+
+// page returns the entries at [offset, offset+limit).
+func (r *ranking) page(offset, limit int) []Entry {
+	if offset >= len(r.ranked) {
+		return []Entry{}
+	}
+	end := min(offset+limit, len(r.ranked))
+	out := make([]Entry, 0, end-offset)
+	for i := offset; i < end; i++ {
+		acct := r.ranked[i]
+		rank := i + 1
+		if i > offset && r.scores[acct].Cmp(out[len(out)-1].Score) == 0 {
+			rank = out[len(out)-1].Rank
+		} else if i > 0 {
+			rank = r.rank(i)
+		}
+		out = append(out, Entry{Rank: rank, Account: acct, Score: new(big.Int).Set(r.scores[acct])})
+	}
+	return out
+}
+
+// entry returns acct's entry, if it is ranked.
+func (r *ranking) entry(acct common.Address) (Entry, bool) {
+	score, ok := r.scores[acct]
+	if !ok || score.Sign() <= 0 {
+		return Entry{}, false
+	}
+	i := r.search(score, acct)
+	return Entry{Rank: r.rank(i), Account: acct, Score: new(big.Int).Set(score)}, true
+}
+
+func zeroIfNil(x *big.Int) *big.Int {
+	if x == nil {
+		return new(big.Int)
+	}
+	return x
+}
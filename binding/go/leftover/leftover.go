@@ -0,0 +1,357 @@
+// Package leftover watches the router for funds left behind between
+// transactions.
+//
+// The router wraps native CROSS, approves pools and forwards withdrawals
+// within a single transaction, so at the end of every block it should hold
+// no native CROSS, no WCROSS and none of any pool's deposit or reward
+// tokens. A Scanner reads those balances at a block and, for each non-zero
+// one, finds the transaction that left it: the last ERC-20 Transfer to or
+// from the router for tokens, or a call to the router in the block where
+// the native balance last changed. A Monitor scans every new final block,
+// reading the native balance at each one and a token's balance at each
+// block with a Transfer of it to or from the router, and alerts when funds
+// appear, change or are cleared.
+package leftover
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/erc20"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+// ErrNoRouter is returned when the factory has no router set.
+var ErrNoRouter = errors.New("leftover: factory has no router")
+
+// DefaultChunkSize is the number of blocks requested per eth_getLogs call.
+const DefaultChunkSize = 5000
+
+var transferID = mustTransferID()
+
+func mustTransferID() common.Hash {
+	parsed, err := binding.WCROSSMetaData.GetAbi()
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	return parsed.Events["Transfer"].ID
+}
+
+// Native is the Token of the router's native CROSS balance.
+var Native = common.Address{}
+
+// Leftover is a non-zero router balance at one block.
+type Leftover struct {
+	Block uint64 `json:"block"`
+	// Token is the ERC-20 held, or Native.
+	Token   common.Address `json:"token"`
+	Balance *big.Int       `json:"balance"`
+	// TxHash is the transaction that left the balance; zero if it could
+	// not be found in the searched range.
+	TxHash  common.Hash    `json:"txHash,omitempty"`
+	TxBlock uint64         `json:"txBlock,omitempty"`
+	Sender  common.Address `json:"sender,omitempty"`
+}
+
+// IsNative reports whether l is a native CROSS balance.
+func (l *Leftover) IsNative() bool {
+	return l.Token == Native
+}
+
+// chain is the node access a Scanner needs. *ethclient.Client satisfies it.
+type chain interface {
+	bind.ContractCaller
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+// Scanner reads and attributes the router's balances.
+type Scanner struct {
+	net    *config.Network
+	chain  chain
+	router common.Address
+
+	// ChunkSize bounds each log query. Zero means DefaultChunkSize.
+	ChunkSize uint64
+}
+
+// NewScanner returns a Scanner for the router of net.
+func NewScanner(net *config.Network) (*Scanner, error) {
+	if net.Router == nil {
+		return nil, ErrNoRouter
+	}
+	return &Scanner{net: net, chain: net.Client, router: net.RouterAddress}, nil
+}
+
+// Router returns the watched router address.
+func (s *Scanner) Router() common.Address {
+	return s.router
+}
+
+// Tokens returns WCROSS, every deposit token the router reports and every
+// active and removed reward token of every pool, sorted and deduplicated.
+func (s *Scanner) Tokens(opts *bind.CallOpts) ([]common.Address, error) {
+	native, err := s.net.Router.NATIVETOKEN(opts)
+	if err != nil {
+		return nil, fmt.Errorf("leftover: NATIVE_TOKEN: %w", err)
+	}
+	seen := map[common.Address]bool{s.net.WCROSSAddress: true}
+	deposits, err := s.net.Router.GetTotalDeposited0(opts)
+	if err != nil {
+		return nil, fmt.Errorf("leftover: deposit tokens: %w", err)
+	}
+	for _, t := range deposits.DepositTokens {
+		if t != native {
+			seen[t] = true
+		}
+	}
+	pools, err := s.net.Pools(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pools {
+		active, err := p.Pool.GetRewardTokens(opts)
+		if err != nil {
+			return nil, fmt.Errorf("leftover: pool %s reward tokens: %w", p.ID, err)
+		}
+		removed, err := p.Pool.GetRemovedRewardTokens(opts)
+		if err != nil {
+			return nil, fmt.Errorf("leftover: pool %s removed reward tokens: %w", p.ID, err)
+		}
+		for _, t := range append(active, removed...) {
+			seen[t] = true
+		}
+	}
+	out := make([]common.Address, 0, len(seen))
+	for t := range seen {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i][:], out[j][:]) < 0 })
+	return out, nil
+}
+
+// Balances returns the router's non-zero balances of native CROSS and of
+// tokens at block.
+func (s *Scanner) Balances(ctx context.Context, block uint64, tokens []common.Address) (map[common.Address]*big.Int, error) {
+	out := make(map[common.Address]*big.Int)
+	for _, t := range append([]common.Address{Native}, tokens...) {
+		bal, err := s.balance(ctx, t, block)
+		if err != nil {
+			return nil, err
+		}
+		if bal.Sign() != 0 {
+			out[t] = bal
+		}
+	}
+	return out, nil
+}
+
+// balance returns the router's balance of token, or of native CROSS, at block.
+func (s *Scanner) balance(ctx context.Context, token common.Address, block uint64) (*big.Int, error) {
+	at := new(big.Int).SetUint64(block)
+	if token == Native {
+		bal, err := s.chain.BalanceAt(ctx, s.router, at)
+		if err != nil {
+			return nil, fmt.Errorf("leftover: native balance at %d: %w", block, err)
+		}
+		return bal, nil
+	}
+	c, err := erc20.NewCaller(token, s.chain)
+	if err != nil {
+		return nil, err
+	}
+	bal, err := c.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: at}, s.router)
+	if err != nil {
+		return nil, fmt.Errorf("leftover: balance of %s at %d: %w", token, block, err)
+	}
+	return bal, nil
+}
+
+// moves returns, for each block in [from, to], the tokens with a Transfer to
+// or from the router in that block. Token balances change only in those
+// blocks, so a scan need not read the others.
+func (s *Scanner) moves(ctx context.Context, from, to uint64, tokens []common.Address) (map[uint64][]common.Address, error) {
+	out := make(map[uint64][]common.Address)
+	if len(tokens) == 0 {
+		// An empty address list would match every contract on chain.
+		return out, nil
+	}
+	chunk := s.ChunkSize
+	if chunk == 0 {
+		chunk = DefaultChunkSize
+	}
+	router := common.BytesToHash(s.router[:])
+	seen := make(map[uint64]map[common.Address]bool)
+	for lo := from; lo <= to; lo += chunk {
+		hi := min(lo+chunk-1, to)
+		for _, topics := range [][][]common.Hash{
+			{{transferID}, nil, {router}},
+			{{transferID}, {router}},
+		} {
+			logs, err := s.chain.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(lo),
+				ToBlock:   new(big.Int).SetUint64(hi),
+				Addresses: tokens,
+				Topics:    topics,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("leftover: transfers %d-%d: %w", lo, hi, err)
+			}
+			for _, l := range logs {
+				if l.Removed || seen[l.BlockNumber][l.Address] {
+					continue
+				}
+				if seen[l.BlockNumber] == nil {
+					seen[l.BlockNumber] = make(map[common.Address]bool)
+				}
+				seen[l.BlockNumber][l.Address] = true
+				out[l.BlockNumber] = append(out[l.BlockNumber], l.Address)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Scan lists the router's leftovers at block, each attributed to the last
+// transaction in (from, block] that moved that balance.
+func (s *Scanner) Scan(ctx context.Context, from, block uint64, tokens []common.Address) ([]Leftover, error) {
+	bals, err := s.Balances(ctx, block, tokens)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Leftover, 0, len(bals))
+	for t, bal := range bals {
+		l := Leftover{Block: block, Token: t, Balance: bal}
+		if err := s.Attribute(ctx, &l, from); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Token[:], out[j].Token[:]) < 0 })
+	return out, nil
+}
+
+// Attribute sets the transaction in (from, l.Block] that left l.
+func (s *Scanner) Attribute(ctx context.Context, l *Leftover, from uint64) error {
+	if from >= l.Block {
+		return nil
+	}
+	var err error
+	if l.IsNative() {
+		err = s.attributeNative(ctx, l, from)
+	} else {
+		err = s.attributeToken(ctx, l, from)
+	}
+	if err != nil || l.TxHash == (common.Hash{}) {
+		return err
+	}
+	tx, _, err := s.chain.TransactionByHash(ctx, l.TxHash)
+	if err != nil {
+		return fmt.Errorf("leftover: transaction %s: %w", l.TxHash, err)
+	}
+	if sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		l.Sender = sender
+	}
+	return nil
+}
+
+// attributeToken picks the last Transfer to or from the router.
+func (s *Scanner) attributeToken(ctx context.Context, l *Leftover, from uint64) error {
+	chunk := s.ChunkSize
+	if chunk == 0 {
+		chunk = DefaultChunkSize
+	}
+	router := common.BytesToHash(s.router[:])
+	// Search backwards so the common case, a leftover from the latest
+	// blocks, needs one query per side.
+	for to := l.Block; to > from; {
+		lo := max(from+1, to-min(to, chunk-1))
+		var last *types.Log
+		for _, topics := range [][][]common.Hash{
+			{{transferID}, nil, {router}},
+			{{transferID}, {router}},
+		} {
+			logs, err := s.chain.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(lo),
+				ToBlock:   new(big.Int).SetUint64(to),
+				Addresses: []common.Address{l.Token},
+				Topics:    topics,
+			})
+			if err != nil {
+				return fmt.Errorf("leftover: transfers of %s %d-%d: %w", l.Token, lo, to, err)
+			}
+			for i := range logs {
+				if lg := &logs[i]; !lg.Removed && (last == nil || after(lg, last)) {
+					last = lg
+				}
+			}
+		}
+		if last != nil {
+			l.TxHash, l.TxBlock = last.TxHash, last.BlockNumber
+			return nil
+		}
+		to = lo - 1
+	}
+	return nil
+}
+
+func after(a, b *types.Log) bool {
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber > b.BlockNumber
+	}
+	return a.Index > b.Index
+}
+
+// attributeNative finds the first block after which the native balance
+// stays at l.Balance and picks the last transaction in it sent to the
+// router. Native transfers into the router are internal calls, so the
+// transaction is the one that called the router, not a log. If the balance
+// was already l.Balance at from, the change predates the range and l is
+// left unattributed.
+func (s *Scanner) attributeNative(ctx context.Context, l *Leftover, from uint64) error {
+	bal, err := s.balance(ctx, Native, from)
+	if err != nil {
+		return err
+	}
+	if bal.Cmp(l.Balance) == 0 {
+		return nil
+	}
+	lo, hi := from, l.Block // balance at lo differs from l.Balance, at hi equals it
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		bal, err := s.balance(ctx, Native, mid)
+		if err != nil {
+			return err
+		}
+		if bal.Cmp(l.Balance) == 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	block, err := s.chain.BlockByNumber(ctx, new(big.Int).SetUint64(hi))
+	if err != nil {
+		return fmt.Errorf("leftover: block %d: %w", hi, err)
+	}
+	l.TxBlock = hi
+	txs := block.Transactions()
+	for i := len(txs) - 1; i >= 0; i-- {
+		if to := txs[i].To(); to != nil && *to == s.router {
+			l.TxHash = txs[i].Hash()
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package leftover
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	router = common.HexToAddress("0x7e")
+	token  = common.HexToAddress("0x70")
+	other  = common.HexToAddress("0x99")
+)
+
+type fakeChain struct {
+	native map[uint64]int64 // balance from that block on
+	tokens map[uint64]int64 // balance of token from that block on
+	logs   []types.Log
+	blocks map[uint64]*types.Block
+	gets   int
+}
+
+func (f *fakeChain) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeChain) CallContract(_ context.Context, _ ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	return common.LeftPadBytes(big.NewInt(at(f.tokens, block)).Bytes(), 32), nil
+}
+
+func (f *fakeChain) BalanceAt(_ context.Context, _ common.Address, block *big.Int) (*big.Int, error) {
+	return big.NewInt(at(f.native, block)), nil
+}
+
+func at(bals map[uint64]int64, block *big.Int) int64 {
+	var bal int64
+	var from uint64
+	for b, v := range bals {
+		if b <= block.Uint64() && b >= from {
+			from, bal = b, v
+		}
+	}
+	return bal
+}
+
+func (f *fakeChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.gets++
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber < q.FromBlock.Uint64() || l.BlockNumber > q.ToBlock.Uint64() || !slices.Contains(q.Addresses, l.Address) {
+			continue
+		}
+		match := true
+		for i, want := range q.Topics {
+			if len(want) > 0 && l.Topics[i] != want[0] {
+				match = false
+			}
+		}
+		if match {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeChain) BlockByNumber(_ context.Context, n *big.Int) (*types.Block, error) {
+	if b, ok := f.blocks[n.Uint64()]; ok {
+		return b, nil
+	}
+	return types.NewBlockWithHeader(&types.Header{Number: n}), nil
+}
+
+func (f *fakeChain) TransactionByHash(context.Context, common.Hash) (*types.Transaction, bool, error) {
+	return types.NewTx(&types.LegacyTx{}), false, nil
+}
+
+func transfer(from, to common.Address, block uint64, index uint, tx byte) types.Log {
+	return types.Log{
+		Address:     token,
+		Topics:      []common.Hash{transferID, common.BytesToHash(from[:]), common.BytesToHash(to[:])},
+		BlockNumber: block,
+		Index:       index,
+		TxHash:      common.Hash{tx},
+	}
+}
+
+func TestAttributeToken(t *testing.T) {
+	c := &fakeChain{logs: []types.Log{
+		transfer(other, router, 3, 0, 1),
+		transfer(router, other, 3, 1, 1),
+		transfer(other, router, 40, 2, 2), // leaves the balance
+		transfer(other, other, 45, 0, 3),
+	}}
+	s := &Scanner{chain: c, router: router, ChunkSize: 10}
+	l := Leftover{Block: 50, Token: token, Balance: big.NewInt(5)}
+	if err := s.Attribute(context.Background(), &l, 0); err != nil {
+		t.Fatal(err)
+	}
+	if l.TxHash != (common.Hash{2}) || l.TxBlock != 40 {
+		t.Fatalf("attributed to %s in %d", l.TxHash, l.TxBlock)
+	}
+	if c.gets != 4 {
+		t.Errorf("%d log queries, want 4 (two chunks, both sides)", c.gets)
+	}
+
+	l = Leftover{Block: 50, Token: token, Balance: big.NewInt(5)}
+	if err := s.Attribute(context.Background(), &l, 40); err != nil || l.TxHash != (common.Hash{}) {
+		t.Errorf("outside range: %s %v", l.TxHash, err)
+	}
+}
+
+func TestAttributeNative(t *testing.T) {
+	tx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &router})
+	miss := types.NewTx(&types.LegacyTx{Nonce: 8, To: &other})
+	c := &fakeChain{
+		native: map[uint64]int64{0: 0, 21: 3, 22: 0, 37: 9},
+		blocks: map[uint64]*types.Block{37: types.NewBlockWithHeader(&types.Header{Number: big.NewInt(37)}).WithBody([]*types.Transaction{tx, miss}, nil)},
+	}
+	s := &Scanner{chain: c, router: router}
+	l := Leftover{Block: 60, Token: Native, Balance: big.NewInt(9)}
+	if err := s.Attribute(context.Background(), &l, 10); err != nil {
+		t.Fatal(err)
+	}
+	if l.TxBlock != 37 || l.TxHash != tx.Hash() {
+		t.Fatalf("attributed to %s in %d", l.TxHash, l.TxBlock)
+	}
+
+	// The balance was already 9 at block 40, so the change is not in range.
+	l = Leftover{Block: 60, Token: Native, Balance: big.NewInt(9)}
+	if err := s.Attribute(context.Background(), &l, 40); err != nil || l.TxHash != (common.Hash{}) || l.TxBlock != 0 {
+		t.Errorf("predates range: %s in %d, %v", l.TxHash, l.TxBlock, err)
+	}
+}
+
+func TestMonitorScansEveryBlock(t *testing.T) {
+	c := &fakeChain{
+		native: map[uint64]int64{0: 0, 12: 4, 14: 0},
+		tokens: map[uint64]int64{0: 0, 13: 5, 15: 0},
+		logs: []types.Log{
+			transfer(other, router, 13, 0, 1),
+			transfer(router, other, 15, 0, 2),
+		},
+	}
+	m := &Monitor{
+		scanner: &Scanner{chain: c, router: router},
+		now:     func() time.Time { return time.Unix(0, 0) },
+		block:   10,
+		held:    map[common.Address]*big.Int{},
+	}
+	alerts, err := m.scan(context.Background(), 20, []common.Address{token})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range alerts {
+		got = append(got, fmt.Sprintf("%s %s@%d", a.Name, a.Details["token"], a.Block))
+	}
+	// Both leftovers were swept before block 20 and are still reported.
+	want := []string{
+		"LeftoverFunds native@12",
+		"LeftoverFunds " + token.Hex() + "@13",
+		"LeftoverCleared native@14",
+		"LeftoverCleared " + token.Hex() + "@15",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("alerts %v, want %v", got, want)
+	}
+	if m.block != 20 || len(m.held) != 0 {
+		t.Errorf("at %d holding %v", m.block, m.held)
+	}
+}
+
+func TestMonitorDiff(t *testing.T) {
+	m := &Monitor{
+		scanner: &Scanner{chain: &fakeChain{}, router: router},
+		now:     func() time.Time { return time.Unix(0, 0) },
+		held:    map[common.Address]*big.Int{token: big.NewInt(5), Native: big.NewInt(1)},
+	}
+	alerts, err := m.diff(context.Background(), 10, 10, map[common.Address]*big.Int{
+		token: big.NewInt(5), // unchanged
+		other: big.NewInt(2), // new
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, a := range alerts {
+		got[a.Details["token"]] = a.Name
+	}
+	want := map[string]string{other.Hex(): "LeftoverFunds", "native": "LeftoverCleared"}
+	if len(got) != len(want) || got[other.Hex()] != want[other.Hex()] || got["native"] != want["native"] {
+		t.Errorf("alerts %v, want %v", got, want)
+	}
+}
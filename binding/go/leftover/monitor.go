@@ -0,0 +1,217 @@
+package leftover
+
+import (
+	"context"
+	"maps"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+)
+
+// Monitor defaults.
+const (
+	DefaultInterval = 15 * time.Second
+	// DefaultLookback is how far back the first scan searches for the
+	// transaction behind a leftover that is already there.
+	DefaultLookback = 10000
+	// DefaultMaxBlocks bounds the blocks one step scans, so a monitor that
+	// falls behind catches up over several steps.
+	DefaultMaxBlocks = 1000
+)
+
+// Monitor scans the router at every new block and alerts on changes.
+type Monitor struct {
+	scanner *Scanner
+	policy  finality.Policy
+	sinks   []alert.Sink
+	now     func() time.Time
+
+	// Interval is the time between polls. Zero means DefaultInterval.
+	Interval time.Duration
+	// Lookback bounds the first scan's attribution search. Zero means
+	// DefaultLookback.
+	Lookback uint64
+	// MaxBlocks bounds the blocks scanned per step. Zero means
+	// DefaultMaxBlocks.
+	MaxBlocks uint64
+	// OnError receives scan and delivery failures; nil drops them.
+	OnError func(error)
+
+	block uint64
+	held  map[common.Address]*big.Int
+}
+
+// NewMonitor returns a Monitor scanning blocks that are final under
+// policy and delivering to sinks.
+func NewMonitor(s *Scanner, policy finality.Policy, sinks ...alert.Sink) *Monitor {
+	return &Monitor{scanner: s, policy: policy, sinks: sinks, now: time.Now, held: make(map[common.Address]*big.Int)}
+}
+
+// Run polls until ctx is done.
+func (m *Monitor) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := m.Step(ctx); err != nil {
+			m.report(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step scans every final block since the last step and returns the alerts
+// sent. The first step scans only the newest final block. The token list is
+// refreshed every step so new pools and reward tokens are picked up.
+func (m *Monitor) Step(ctx context.Context) ([]*alert.Alert, error) {
+	final, err := m.policy.FinalBlock(ctx, m.scanner.net.Client)
+	if err != nil || final <= m.block {
+		return nil, err
+	}
+	to := final
+	if m.block != 0 {
+		maxBlocks := m.MaxBlocks
+		if maxBlocks == 0 {
+			maxBlocks = DefaultMaxBlocks
+		}
+		to = min(final, m.block+maxBlocks)
+	}
+	tokens, err := m.scanner.Tokens(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(to)})
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := m.scan(ctx, to, tokens)
+	for _, a := range alerts {
+		for _, s := range m.sinks {
+			if err := s.Send(ctx, a); err != nil {
+				m.report(err)
+			}
+		}
+	}
+	return alerts, err
+}
+
+// scan advances the monitor block by block up to to. A token's balance is
+// only read at blocks with a Transfer of it to or from the router; the
+// native balance is read at every block. At to every balance is read, which
+// also picks up tokens new to the list. On error the blocks already scanned
+// stay scanned and their alerts are returned.
+func (m *Monitor) scan(ctx context.Context, to uint64, tokens []common.Address) ([]*alert.Alert, error) {
+	if m.block == 0 {
+		bals, err := m.scanner.Balances(ctx, to, tokens)
+		if err != nil {
+			return nil, err
+		}
+		lookback := m.Lookback
+		if lookback == 0 {
+			lookback = DefaultLookback
+		}
+		alerts, err := m.diff(ctx, to-min(to, lookback), to, bals)
+		if err != nil {
+			return nil, err
+		}
+		m.block, m.held = to, bals
+		return alerts, nil
+	}
+	moved, err := m.scanner.moves(ctx, m.block+1, to, tokens)
+	if err != nil {
+		return nil, err
+	}
+	var out []*alert.Alert
+	for block := m.block + 1; block <= to; block++ {
+		var bals map[common.Address]*big.Int
+		if block == to {
+			if bals, err = m.scanner.Balances(ctx, block, tokens); err != nil {
+				return out, err
+			}
+		} else {
+			bals = maps.Clone(m.held)
+			for _, t := range append([]common.Address{Native}, moved[block]...) {
+				bal, err := m.scanner.balance(ctx, t, block)
+				if err != nil {
+					return out, err
+				}
+				if bal.Sign() == 0 {
+					delete(bals, t)
+				} else {
+					bals[t] = bal
+				}
+			}
+		}
+		alerts, err := m.diff(ctx, block-1, block, bals)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, alerts...)
+		m.block, m.held = block, bals
+	}
+	return out, nil
+}
+
+// diff compares bals at block with the balances held at the last scan.
+func (m *Monitor) diff(ctx context.Context, from, block uint64, bals map[common.Address]*big.Int) ([]*alert.Alert, error) {
+	var out []*alert.Alert
+	for t, bal := range bals {
+		if prev, ok := m.held[t]; ok && prev.Cmp(bal) == 0 {
+			continue
+		}
+		l := Leftover{Block: block, Token: t, Balance: bal}
+		if err := m.scanner.Attribute(ctx, &l, from); err != nil {
+			return nil, err
+		}
+		out = append(out, m.alert(&l, "LeftoverFunds", "critical"))
+	}
+	for t := range m.held {
+		if _, ok := bals[t]; !ok {
+			out = append(out, m.alert(&Leftover{Block: block, Token: t, Balance: new(big.Int)}, "LeftoverCleared", "info"))
+		}
+	}
+	return out, nil
+}
+
+func (m *Monitor) alert(l *Leftover, name, severity string) *alert.Alert {
+	details := map[string]string{"token": "native"}
+	if !l.IsNative() {
+		details["token"] = l.Token.Hex()
+	}
+	block := l.Block
+	if l.TxBlock != 0 {
+		// Report the transaction's block; the scan block goes in the details.
+		block = l.TxBlock
+		details["scannedBlock"] = strconv.FormatUint(l.Block, 10)
+	}
+	return &alert.Alert{
+		Rule:     "router-leftover",
+		Severity: severity,
+		At:       m.now(),
+		Event: alert.Event{
+			Name:     name,
+			Source:   alert.SourceRouter,
+			Contract: m.scanner.router,
+			Actor:    l.Sender,
+			Amount:   l.Balance,
+			Details:  details,
+			Block:    block,
+			TxHash:   l.TxHash,
+		},
+	}
+}
+
+func (m *Monitor) report(err error) {
+	if m.OnError != nil {
+		m.OnError(err)
+	}
+}
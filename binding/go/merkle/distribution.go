@@ -0,0 +1,103 @@
+package merkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Claim is one account's entry in a Distribution.
+type Claim struct {
+	Amount *big.Int      `json:"amount"`
+	Proof  []common.Hash `json:"proof"`
+}
+
+// Distribution is the published form of a tree: the root and every
+// account's amount and proof.
+type Distribution struct {
+	Root   common.Hash              `json:"root"`
+	Total  *big.Int                 `json:"total"`
+	Claims map[common.Address]Claim `json:"claims"`
+}
+
+// Distribution returns the root and the proof of every leaf.
+func (t *Tree) Distribution() *Distribution {
+	d := &Distribution{Root: t.Root(), Total: new(big.Int), Claims: make(map[common.Address]Claim, len(t.Values))}
+	for _, v := range t.Values {
+		proof, _ := t.Proof(v.Account)
+		d.Claims[v.Account] = Claim{Amount: v.Amount, Proof: proof}
+		d.Total.Add(d.Total, v.Amount)
+	}
+	return d
+}
+
+// Verify checks every proof against the root and the total against the
+// claims, returning the first failure.
+func (d *Distribution) Verify() error {
+	total := new(big.Int)
+	for acct, c := range d.Claims {
+		if !Verify(d.Root, acct, c.Amount, c.Proof) {
+			return fmt.Errorf("merkle: invalid proof for %s", acct.Hex())
+		}
+		total.Add(total, c.Amount)
+	}
+	if d.Total != nil && d.Total.Cmp(total) != 0 {
+		return fmt.Errorf("merkle: total %s does not match claims sum %s", d.Total, total)
+	}
+	return nil
+}
+
+// WriteFile saves d as indented JSON.
+func (d *Distribution) WriteFile(path string) error {
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// ReadDistribution loads a file written by WriteFile.
+func ReadDistribution(path string) (*Distribution, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d := new(Distribution)
+	if err := json.Unmarshal(raw, d); err != nil {
+		return nil, fmt.Errorf("merkle: parse %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// dump is OpenZeppelin's StandardMerkleTree.dump() format.
+type dump struct {
+	Format       string      `json:"format"`
+	LeafEncoding []string    `json:"leafEncoding"`
+	Tree         []string    `json:"tree"`
+	Values       []dumpValue `json:"values"`
+}
+
+type dumpValue struct {
+	Value     [2]string `json:"value"`
+	TreeIndex int       `json:"treeIndex"`
+}
+
+// WriteDump saves t in the format of OpenZeppelin's StandardMerkleTree.dump,
+// so it can be loaded with StandardMerkleTree.load.
+func (t *Tree) WriteDump(path string) error {
+	d := dump{Format: "standard-v1", LeafEncoding: []string{"address", "uint256"}}
+	for _, n := range t.Nodes {
+		d.Tree = append(d.Tree, n.Hex())
+	}
+	for _, v := range t.Values {
+		d.Values = append(d.Values, dumpValue{Value: [2]string{v.Account.Hex(), v.Amount.String()}, TreeIndex: v.TreeIndex})
+	}
+	raw, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
@@ -0,0 +1,147 @@
+// Package merkle builds claim distributions as OpenZeppelin-compatible
+// Merkle trees.
+//
+// Leaves are (address, uint256) pairs hashed like OpenZeppelin's
+// StandardMerkleTree: keccak256(keccak256(abi.encode(account, amount))).
+// Pairs are hashed in sorted order, so proofs verify with
+// MerkleProof.verify, and the tree layout matches StandardMerkleTree.of with
+// sorted leaves, so roots agree with the JavaScript library for the same
+// allocations.
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrEmpty is returned when building a tree without allocations.
+	ErrEmpty = errors.New("merkle: no allocations")
+	// ErrNotFound is returned for accounts without a leaf.
+	ErrNotFound = errors.New("merkle: account not in tree")
+)
+
+// Allocations maps accounts to claimable amounts.
+type Allocations map[common.Address]*big.Int
+
+// Add credits amount to acct, merging with any existing allocation.
+func (a Allocations) Add(acct common.Address, amount *big.Int) {
+	if cur, ok := a[acct]; ok {
+		a[acct] = new(big.Int).Add(cur, amount)
+		return
+	}
+	a[acct] = new(big.Int).Set(amount)
+}
+
+// Total returns the sum of all allocations.
+func (a Allocations) Total() *big.Int {
+	total := new(big.Int)
+	for _, v := range a {
+		total.Add(total, v)
+	}
+	return total
+}
+
+// Leaf returns the leaf hash of (acct, amount).
+func Leaf(acct common.Address, amount *big.Int) common.Hash {
+	enc := make([]byte, 64)
+	copy(enc[12:32], acct.Bytes())
+	math.ReadBits(amount, enc[32:])
+	inner := crypto.Keccak256(enc)
+	return crypto.Keccak256Hash(inner)
+}
+
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// Value is one leaf of a Tree.
+type Value struct {
+	Account common.Address
+	Amount  *big.Int
+	// TreeIndex is the position of the leaf in Tree.Nodes.
+	TreeIndex int
+}
+
+// Tree is a complete binary tree stored as an array, root first, with the
+// leaves at the end in reverse order of their sorted hashes.
+type Tree struct {
+	Nodes  []common.Hash
+	Values []Value
+	index  map[common.Address]int
+}
+
+// Build returns the tree of allocs. Zero and negative amounts are rejected
+// because they could never be claimed.
+func Build(allocs Allocations) (*Tree, error) {
+	if len(allocs) == 0 {
+		return nil, ErrEmpty
+	}
+	type leaf struct {
+		hash common.Hash
+		v    Value
+	}
+	leaves := make([]leaf, 0, len(allocs))
+	for acct, amount := range allocs {
+		if amount.Sign() <= 0 || amount.BitLen() > 256 {
+			return nil, fmt.Errorf("merkle: invalid amount %s for %s", amount, acct.Hex())
+		}
+		leaves = append(leaves, leaf{Leaf(acct, amount), Value{Account: acct, Amount: amount}})
+	}
+	sort.Slice(leaves, func(i, j int) bool { return bytes.Compare(leaves[i].hash[:], leaves[j].hash[:]) < 0 })
+
+	t := &Tree{Nodes: make([]common.Hash, 2*len(leaves)-1), index: make(map[common.Address]int, len(leaves))}
+	for i, l := range leaves {
+		pos := len(t.Nodes) - 1 - i
+		t.Nodes[pos] = l.hash
+		l.v.TreeIndex = pos
+		t.Values = append(t.Values, l.v)
+		t.index[l.v.Account] = len(t.Values) - 1
+	}
+	for i := len(t.Nodes) - 1 - len(leaves); i >= 0; i-- {
+		t.Nodes[i] = hashPair(t.Nodes[2*i+1], t.Nodes[2*i+2])
+	}
+	return t, nil
+}
+
+// Root returns the Merkle root.
+func (t *Tree) Root() common.Hash {
+	return t.Nodes[0]
+}
+
+// Proof returns the proof of acct's leaf, ordered from the leaf upwards.
+func (t *Tree) Proof(acct common.Address) ([]common.Hash, error) {
+	i, ok := t.index[acct]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, acct.Hex())
+	}
+	var proof []common.Hash
+	for pos := t.Values[i].TreeIndex; pos > 0; pos = (pos - 1) / 2 {
+		sibling := pos + 1
+		if pos%2 == 0 {
+			sibling = pos - 1
+		}
+		proof = append(proof, t.Nodes[sibling])
+	}
+	return proof, nil
+}
+
+// Verify reports whether proof shows (acct, amount) is a leaf under root,
+// as MerkleProof.verify does on chain.
+func Verify(root common.Hash, acct common.Address, amount *big.Int, proof []common.Hash) bool {
+	h := Leaf(acct, amount)
+	for _, p := range proof {
+		h = hashPair(h, p)
+	}
+	return h == root
+}
@@ -0,0 +1,105 @@
+package merkle
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func amount(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 10)
+	return v
+}
+
+// TestOpenZeppelinVector is the example from the
+// @openzeppelin/merkle-tree README.
+func TestOpenZeppelinVector(t *testing.T) {
+	tree, err := Build(Allocations{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"): amount("5000000000000000000"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"): amount("2500000000000000000"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.HexToHash("0xd4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77")
+	if tree.Root() != want {
+		t.Fatalf("root %s, want %s", tree.Root().Hex(), want.Hex())
+	}
+}
+
+func TestProofs(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		allocs := make(Allocations)
+		for i := 1; i <= n; i++ {
+			allocs.Add(common.BigToAddress(big.NewInt(int64(i))), big.NewInt(int64(100*i)))
+		}
+		tree, err := Build(allocs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for acct, amt := range allocs {
+			proof, err := tree.Proof(acct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(tree.Root(), acct, amt, proof) {
+				t.Errorf("%d leaves: proof for %s does not verify", n, acct.Hex())
+			}
+			if Verify(tree.Root(), acct, new(big.Int).Add(amt, big.NewInt(1)), proof) {
+				t.Errorf("%d leaves: tampered amount for %s verifies", n, acct.Hex())
+			}
+		}
+		if _, err := tree.Proof(common.HexToAddress("0xdead")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("unknown account: %v", err)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	if _, err := Build(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("empty: %v", err)
+	}
+	if _, err := Build(Allocations{common.HexToAddress("0x01"): new(big.Int)}); err == nil {
+		t.Error("zero amount accepted")
+	}
+}
+
+func TestDistributionRoundTrip(t *testing.T) {
+	const (
+		a1 = "0x1111111111111111111111111111111111111111"
+		a2 = "0x2222222222222222222222222222222222222222"
+	)
+	allocs, err := ReadCSV(strings.NewReader("account,amount\n" + a1 + ",10\n" + a2 + ",20\n" + a1 + ",5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs.Total().Int64() != 35 {
+		t.Fatalf("total %s", allocs.Total())
+	}
+	tree, err := Build(allocs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "dist.json")
+	if err := tree.Distribution().WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	d, err := ReadDistribution(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Root != tree.Root() || d.Claims[common.HexToAddress(a1)].Amount.Int64() != 15 {
+		t.Fatalf("round trip: %+v", d)
+	}
+	if err := d.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	d.Total = big.NewInt(36)
+	if err := d.Verify(); err == nil {
+		t.Error("wrong total accepted")
+	}
+}
@@ -0,0 +1,72 @@
+package merkle
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/twab"
+)
+
+// ReadCSV reads allocations from account,amount rows. A header row is
+// skipped and repeated accounts are summed.
+func ReadCSV(r io.Reader) (Allocations, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	allocs := make(Allocations)
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return allocs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("merkle: line %d: want account,amount", line)
+		}
+		acct, amt := strings.TrimSpace(rec[0]), strings.TrimSpace(rec[1])
+		if !common.IsHexAddress(acct) {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("merkle: line %d: invalid account %q", line, acct)
+		}
+		amount, ok := new(big.Int).SetString(amt, 10)
+		if !ok {
+			return nil, fmt.Errorf("merkle: line %d: invalid amount %q", line, amt)
+		}
+		allocs.Add(common.HexToAddress(acct), amount)
+	}
+}
+
+// FromTWAB allocates each account its average balance summed over the
+// snapshot's pools. Accounts with a zero average are left out.
+func FromTWAB(snap *twab.Snapshot) Allocations {
+	allocs := make(Allocations)
+	for _, e := range snap.Entries {
+		if e.Average.Sign() > 0 {
+			allocs.Add(e.Account, e.Average)
+		}
+	}
+	return allocs
+}
+
+// FromBalances allocates each account its balance, as returned by
+// indexer.Store.Balances.
+func FromBalances(balances ...map[common.Address]*big.Int) Allocations {
+	allocs := make(Allocations)
+	for _, m := range balances {
+		for acct, bal := range m {
+			if bal.Sign() > 0 {
+				allocs.Add(acct, bal)
+			}
+		}
+	}
+	return allocs
+}
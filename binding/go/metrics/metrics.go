@@ -0,0 +1,396 @@
+// Package metrics exports protocol state and activity to Prometheus.
+//
+// Gauges are read from the contracts at the latest block and served from an
+// immutable snapshot, so a scrape never sees a half-updated pool. Counters
+// are driven by the events logged since the exporter started, counted only
+// once their block is final under the profile's finality policy so a reorg
+// never counts an event that did not happen. Pools are
+// rediscovered through the factory on every refresh.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/finality"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+const namespace = "cgr"
+
+// DefaultChunkSize is the number of blocks requested per eth_getLogs call.
+const DefaultChunkSize = 5000
+
+var (
+	poolLabels  = []string{"pool_id", "pool"}
+	tokenLabels = []string{"pool_id", "pool", "token"}
+
+	descTotalDeposited  = prometheus.NewDesc(namespace+"_pool_total_deposited", "totalDeposited of the pool (wei).", append(poolLabels, "deposit_token"), nil)
+	descPoolStatus      = prometheus.NewDesc(namespace+"_pool_status", "Pool status: 0 Active, 1 Inactive, 2 Paused.", poolLabels, nil)
+	descPaused          = prometheus.NewDesc(namespace+"_pool_paused", "1 if the pool is paused.", poolLabels, nil)
+	descMinDeposit      = prometheus.NewDesc(namespace+"_pool_min_deposit_amount", "minDepositAmount of the pool (wei).", poolLabels, nil)
+	descRewardTokens    = prometheus.NewDesc(namespace+"_pool_reward_tokens", "Number of reward tokens by state.", append(poolLabels, "state"), nil)
+	descLastBalance     = prometheus.NewDesc(namespace+"_reward_last_balance", "lastBalance of an active reward token (wei).", tokenLabels, nil)
+	descReclaimable     = prometheus.NewDesc(namespace+"_reward_reclaimable_amount", "getReclaimableAmount of a reward token (wei).", tokenLabels, nil)
+	descDistributed     = prometheus.NewDesc(namespace+"_reward_distributed_amount", "distributedAmount of an active reward token (wei).", tokenLabels, nil)
+	descRewardPerToken  = prometheus.NewDesc(namespace+"_reward_per_token_stored", "rewardPerTokenStored of an active reward token.", tokenLabels, nil)
+	descWCROSSSupply    = prometheus.NewDesc(namespace+"_wcross_total_supply", "WCROSS totalSupply (wei).", nil, nil)
+	descBlock           = prometheus.NewDesc(namespace+"_block_number", "Block the gauges were read at.", nil, nil)
+	descLastRefreshTime = prometheus.NewDesc(namespace+"_last_refresh_timestamp_seconds", "Unix time of the last successful refresh.", nil, nil)
+)
+
+// chain is the node access event counting needs. *ethclient.Client
+// satisfies it.
+type chain interface {
+	finality.HeadReader
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Exporter collects gauges and counters for one network.
+type Exporter struct {
+	net    *config.Network
+	chain  chain
+	policy finality.Policy
+
+	// ChunkSize bounds each log query. Zero means DefaultChunkSize.
+	ChunkSize uint64
+
+	mu   sync.RWMutex
+	snap *snapshot
+
+	events     *eventCounters
+	lastLogged uint64
+
+	poolParser   *binding.CrossGameRewardPoolFilterer
+	routerParser *binding.CrossGameRewardRouterFilterer
+}
+
+type snapshot struct {
+	block   uint64
+	at      time.Time
+	supply  *big.Int
+	metrics []prometheus.Metric
+}
+
+// New returns an Exporter and registers it, with its event counters, in reg.
+func New(net *config.Network, reg prometheus.Registerer) (*Exporter, error) {
+	poolParser, err := binding.NewCrossGameRewardPoolFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	routerParser, err := binding.NewCrossGameRewardRouterFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{
+		net:          net,
+		chain:        net.Client,
+		policy:       finality.ForProfile(net.Profile),
+		events:       newEventCounters(),
+		poolParser:   poolParser,
+		routerParser: routerParser,
+	}
+	if err := reg.Register(e); err != nil {
+		return nil, err
+	}
+	if err := e.events.register(reg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		descTotalDeposited, descPoolStatus, descPaused, descMinDeposit, descRewardTokens,
+		descLastBalance, descReclaimable, descDistributed, descRewardPerToken,
+		descWCROSSSupply, descBlock, descLastRefreshTime,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	snap := e.snap
+	e.mu.RUnlock()
+	if snap == nil {
+		return
+	}
+	for _, m := range snap.metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(descWCROSSSupply, prometheus.GaugeValue, toFloat(snap.supply))
+	ch <- prometheus.MustNewConstMetric(descBlock, prometheus.GaugeValue, float64(snap.block))
+	ch <- prometheus.MustNewConstMetric(descLastRefreshTime, prometheus.GaugeValue, float64(snap.at.Unix()))
+}
+
+// Run refreshes on every new block, polling the head every interval, until
+// ctx is done. Refresh errors are passed to onError and retried on the next
+// tick.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last uint64
+	for {
+		head, err := e.net.Client.BlockNumber(ctx)
+		if err == nil && head != last {
+			if err = e.Refresh(ctx, head); err == nil {
+				last = head
+			}
+		}
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads all gauges at block and counts the events logged in blocks
+// that became final since the previous refresh. The first refresh only sets
+// the event starting point.
+func (e *Exporter) Refresh(ctx context.Context, block uint64) error {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)}
+	pools, err := e.net.Pools(opts)
+	if err != nil {
+		return err
+	}
+	snap := &snapshot{block: block, at: time.Now()}
+	for _, p := range pools {
+		ms, err := poolMetrics(opts, p)
+		if err != nil {
+			return fmt.Errorf("metrics: pool %s: %w", p.ID, err)
+		}
+		snap.metrics = append(snap.metrics, ms...)
+	}
+	if snap.supply, err = e.net.WCROSS.TotalSupply(opts); err != nil {
+		return fmt.Errorf("metrics: wcross supply: %w", err)
+	}
+	if err := e.countEvents(ctx, pools); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.snap = snap
+	e.mu.Unlock()
+	return nil
+}
+
+func poolMetrics(opts *bind.CallOpts, p config.PoolRef) ([]prometheus.Metric, error) {
+	id, addr := p.ID.String(), p.Address.Hex()
+	total, err := p.Pool.TotalDeposited(opts)
+	if err != nil {
+		return nil, err
+	}
+	depositToken, err := p.Pool.DepositToken(opts)
+	if err != nil {
+		return nil, err
+	}
+	status, err := p.Pool.PoolStatus(opts)
+	if err != nil {
+		return nil, err
+	}
+	paused, err := p.Pool.Paused(opts)
+	if err != nil {
+		return nil, err
+	}
+	minDeposit, err := p.Pool.MinDepositAmount(opts)
+	if err != nil {
+		return nil, err
+	}
+	active, err := p.Pool.GetRewardTokens(opts)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := p.Pool.GetRemovedRewardTokens(opts)
+	if err != nil {
+		return nil, err
+	}
+	ms := []prometheus.Metric{
+		prometheus.MustNewConstMetric(descTotalDeposited, prometheus.GaugeValue, toFloat(total), id, addr, depositToken.Hex()),
+		prometheus.MustNewConstMetric(descPoolStatus, prometheus.GaugeValue, float64(status), id, addr),
+		prometheus.MustNewConstMetric(descPaused, prometheus.GaugeValue, boolFloat(paused), id, addr),
+		prometheus.MustNewConstMetric(descMinDeposit, prometheus.GaugeValue, toFloat(minDeposit), id, addr),
+		prometheus.MustNewConstMetric(descRewardTokens, prometheus.GaugeValue, float64(len(active)), id, addr, "active"),
+		prometheus.MustNewConstMetric(descRewardTokens, prometheus.GaugeValue, float64(len(removed)), id, addr, "removed"),
+	}
+	for _, token := range active {
+		rt, err := p.Pool.GetRewardToken(opts, token)
+		if err != nil {
+			return nil, err
+		}
+		reclaimable, err := p.Pool.GetReclaimableAmount(opts, token)
+		if err != nil {
+			return nil, err
+		}
+		t := token.Hex()
+		ms = append(ms,
+			prometheus.MustNewConstMetric(descLastBalance, prometheus.GaugeValue, toFloat(rt.LastBalance), id, addr, t),
+			prometheus.MustNewConstMetric(descReclaimable, prometheus.GaugeValue, toFloat(reclaimable), id, addr, t),
+			prometheus.MustNewConstMetric(descDistributed, prometheus.GaugeValue, toFloat(rt.DistributedAmount), id, addr, t),
+			prometheus.MustNewConstMetric(descRewardPerToken, prometheus.GaugeValue, toFloat(rt.RewardPerTokenStored), id, addr, t),
+		)
+	}
+	// getRewardToken reverts for removed tokens; only the reclaimable amount
+	// is observable for them.
+	for _, token := range removed {
+		reclaimable, err := p.Pool.GetReclaimableAmount(opts, token)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, prometheus.MustNewConstMetric(descReclaimable, prometheus.GaugeValue, toFloat(reclaimable), id, addr, token.Hex()))
+	}
+	return ms, nil
+}
+
+func (e *Exporter) countEvents(ctx context.Context, pools []config.PoolRef) error {
+	final, err := e.policy.FinalBlock(ctx, e.chain)
+	if err != nil {
+		return fmt.Errorf("metrics: %w", err)
+	}
+	if e.lastLogged == 0 {
+		e.lastLogged = final
+		return nil
+	}
+	if final <= e.lastLogged {
+		return nil
+	}
+	ids := make(map[common.Address]string, len(pools))
+	addrs := make([]common.Address, 0, len(pools)+1)
+	for _, p := range pools {
+		ids[p.Address] = p.ID.String()
+		addrs = append(addrs, p.Address)
+	}
+	if e.net.Router != nil {
+		addrs = append(addrs, e.net.RouterAddress)
+	}
+	if len(addrs) == 0 {
+		// An empty address list would match every contract on chain.
+		e.lastLogged = final
+		return nil
+	}
+	chunk := e.ChunkSize
+	if chunk == 0 {
+		chunk = DefaultChunkSize
+	}
+	for e.lastLogged < final {
+		from := e.lastLogged + 1
+		to := min(from+chunk-1, final)
+		logs, err := e.chain.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: addrs,
+		})
+		if err != nil {
+			return fmt.Errorf("metrics: logs %d-%d: %w", from, to, err)
+		}
+		for _, l := range logs {
+			e.count(l, ids)
+		}
+		e.lastLogged = to
+	}
+	return nil
+}
+
+func (e *Exporter) count(l types.Log, ids map[common.Address]string) {
+	if l.Removed {
+		return
+	}
+	if l.Address == e.net.RouterAddress {
+		if ev, err := e.routerParser.ParseDepositedNative(l); err == nil {
+			e.events.nativeDeposits.WithLabelValues(ev.PoolId.String()).Inc()
+			e.events.nativeDeposited.WithLabelValues(ev.PoolId.String()).Add(toFloat(ev.Amount))
+		} else if ev, err := e.routerParser.ParseWithdrawnNative(l); err == nil {
+			e.events.nativeWithdrawals.WithLabelValues(ev.PoolId.String()).Inc()
+			e.events.nativeWithdrawn.WithLabelValues(ev.PoolId.String()).Add(toFloat(ev.Amount))
+		}
+		return
+	}
+	id, ok := ids[l.Address]
+	if !ok {
+		return
+	}
+	pool := l.Address.Hex()
+	if ev, err := e.poolParser.ParseDeposited(l); err == nil {
+		e.events.deposits.WithLabelValues(id, pool).Inc()
+		e.events.deposited.WithLabelValues(id, pool).Add(toFloat(ev.Amount))
+	} else if ev, err := e.poolParser.ParseWithdrawn(l); err == nil {
+		e.events.withdrawals.WithLabelValues(id, pool).Inc()
+		e.events.withdrawn.WithLabelValues(id, pool).Add(toFloat(ev.Amount))
+	} else if ev, err := e.poolParser.ParseRewardClaimed(l); err == nil {
+		e.events.claims.WithLabelValues(id, pool, ev.Token.Hex()).Inc()
+		e.events.claimed.WithLabelValues(id, pool, ev.Token.Hex()).Add(toFloat(ev.Amount))
+	} else if ev, err := e.poolParser.ParseRewardClaimFailed(l); err == nil {
+		e.events.claimFailures.WithLabelValues(id, pool, ev.Token.Hex()).Inc()
+	}
+}
+
+type eventCounters struct {
+	deposits, withdrawals             *prometheus.CounterVec
+	deposited, withdrawn              *prometheus.CounterVec
+	claims, claimed, claimFailures    *prometheus.CounterVec
+	nativeDeposits, nativeWithdrawals *prometheus.CounterVec
+	nativeDeposited, nativeWithdrawn  *prometheus.CounterVec
+}
+
+func newEventCounters() *eventCounters {
+	counter := func(name, help string, labels []string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	}
+	routerLabels := []string{"pool_id"}
+	return &eventCounters{
+		deposits:          counter("pool_deposits_total", "Deposited events.", poolLabels),
+		deposited:         counter("pool_deposited_amount_total", "Sum of Deposited amounts (wei).", poolLabels),
+		withdrawals:       counter("pool_withdrawals_total", "Withdrawn events.", poolLabels),
+		withdrawn:         counter("pool_withdrawn_amount_total", "Sum of Withdrawn amounts (wei).", poolLabels),
+		claims:            counter("reward_claims_total", "RewardClaimed events.", tokenLabels),
+		claimed:           counter("reward_claimed_amount_total", "Sum of RewardClaimed amounts (wei).", tokenLabels),
+		claimFailures:     counter("reward_claim_failures_total", "RewardClaimFailed events.", tokenLabels),
+		nativeDeposits:    counter("router_native_deposits_total", "DepositedNative events.", routerLabels),
+		nativeDeposited:   counter("router_native_deposited_amount_total", "Sum of DepositedNative amounts (wei).", routerLabels),
+		nativeWithdrawals: counter("router_native_withdrawals_total", "WithdrawnNative events.", routerLabels),
+		nativeWithdrawn:   counter("router_native_withdrawn_amount_total", "Sum of WithdrawnNative amounts (wei).", routerLabels),
+	}
+}
+
+func (c *eventCounters) register(reg prometheus.Registerer) error {
+	for _, col := range []prometheus.Collector{
+		c.deposits, c.deposited, c.withdrawals, c.withdrawn,
+		c.claims, c.claimed, c.claimFailures,
+		c.nativeDeposits, c.nativeDeposited, c.nativeWithdrawals, c.nativeWithdrawn,
+	} {
+		if err := reg.Register(col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toFloat(x *big.Int) float64 {
+	if x == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(x).Float64()
+	return f
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/testlog"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	poolAddr   = common.HexToAddress("0xb0")
+	routerAddr = common.HexToAddress("0xc0")
+	userAddr   = common.HexToAddress("0xa0")
+	tokenAddr  = common.HexToAddress("0x70")
+)
+
+func TestCountEvents(t *testing.T) {
+	e, err := New(&config.Network{Profile: &config.Profile{}, RouterAddress: routerAddr}, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[common.Address]string{poolAddr: "1"}
+	pool, router := binding.CrossGameRewardPoolMetaData, binding.CrossGameRewardRouterMetaData
+
+	deposit := testlog.Event(t, pool, poolAddr, "Deposited", []common.Hash{testlog.Topic(userAddr)}, big.NewInt(5))
+	e.count(deposit, ids)
+	e.count(deposit, ids)
+	e.count(testlog.Event(t, pool, poolAddr, "RewardClaimed",
+		[]common.Hash{testlog.Topic(userAddr), testlog.Topic(tokenAddr)}, big.NewInt(3)), ids)
+	e.count(testlog.Event(t, router, routerAddr, "DepositedNative",
+		[]common.Hash{testlog.Topic(userAddr), common.BigToHash(big.NewInt(1))}, big.NewInt(9)), ids)
+
+	removed := deposit
+	removed.Removed = true
+	e.count(removed, ids)
+	unknown := deposit
+	unknown.Address = common.HexToAddress("0xdead")
+	e.count(unknown, ids)
+
+	for _, tc := range []struct {
+		name string
+		c    prometheus.Collector
+		want float64
+	}{
+		{"deposits", e.events.deposits.WithLabelValues("1", poolAddr.Hex()), 2},
+		{"deposited", e.events.deposited.WithLabelValues("1", poolAddr.Hex()), 10},
+		{"claimed", e.events.claimed.WithLabelValues("1", poolAddr.Hex(), tokenAddr.Hex()), 3},
+		{"nativeDeposited", e.events.nativeDeposited.WithLabelValues("1"), 9},
+	} {
+		if got := testutil.ToFloat64(tc.c); got != tc.want {
+			t.Errorf("%s = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+type fakeChain struct {
+	head    uint64
+	logs    []types.Log
+	queries [][2]uint64
+}
+
+func (f *fakeChain) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(f.head)}, nil
+}
+
+func (f *fakeChain) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	f.queries = append(f.queries, [2]uint64{from, to})
+	var out []types.Log
+	for _, l := range f.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func TestCountEventsFinalOnly(t *testing.T) {
+	net := &config.Network{Profile: &config.Profile{Confirmations: 5}}
+	e, err := New(net, prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &fakeChain{head: 105}
+	e.chain, e.ChunkSize = c, 10
+	pools := []config.PoolRef{{ID: big.NewInt(1), Address: poolAddr}}
+	deposit := func(block uint64) types.Log {
+		l := testlog.Event(t, binding.CrossGameRewardPoolMetaData, poolAddr, "Deposited", []common.Hash{testlog.Topic(userAddr)}, big.NewInt(1))
+		l.BlockNumber = block
+		return l
+	}
+	c.logs = []types.Log{deposit(101), deposit(125), deposit(126)}
+
+	if err := e.countEvents(context.Background(), pools); err != nil || e.lastLogged != 100 {
+		t.Fatalf("start at %d: %v", e.lastLogged, err)
+	}
+	c.head = 130 // final block 125
+	if err := e.countEvents(context.Background(), pools); err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]uint64{{101, 110}, {111, 120}, {121, 125}}
+	if len(c.queries) != len(want) || c.queries[0] != want[0] || c.queries[1] != want[1] || c.queries[2] != want[2] {
+		t.Errorf("queries %v, want %v", c.queries, want)
+	}
+	if got := testutil.ToFloat64(e.events.deposits.WithLabelValues("1", poolAddr.Hex())); got != 2 {
+		t.Errorf("deposits = %v, want 2 (block 126 is not final)", got)
+	}
+}
@@ -0,0 +1,230 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/erc20"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// RewardClaim is a pool with rewards to claim.
+type RewardClaim struct {
+	PoolID *big.Int
+	Pool   common.Address
+	Status config.PoolStatus
+	// Rewards lists the non-zero pending rewards of active and removed
+	// tokens, as getAllPendingRewards reports them.
+	Rewards []Amount
+	// Value is the worth of Rewards under the Player's Prices.
+	Value float64
+	// Skipped says why the pool is not claimed; paused pools revert.
+	Skipped string
+}
+
+// ClaimPlan lists the pools ClaimEverything would claim from.
+type ClaimPlan struct {
+	User  common.Address
+	Block uint64
+	// Pools lists claimable pools by descending value, then skipped ones.
+	Pools []RewardClaim
+}
+
+// TokenClaim is the outcome of ClaimEverything for one reward token.
+type TokenClaim struct {
+	Token common.Address
+	// Pending is what the plan expected from the pools that were claimed.
+	Pending *big.Int
+	// Claimed is the total of RewardClaimed events for the user.
+	Claimed *big.Int
+	// Failed is the total of RewardClaimFailed events: the pool could not
+	// transfer the token and kept the reward for a later claim.
+	Failed *big.Int
+	// Skipped is the pending amount in paused pools and in pools whose
+	// claim transaction failed.
+	Skipped *big.Int
+	// Pools lists the pools the token was claimed from.
+	Pools  []*big.Int
+	Errors []string
+}
+
+// PlanClaims finds every pool where user has pending rewards at the head
+// block and orders them by value.
+func (p *Player) PlanClaims(ctx context.Context, user common.Address) (*ClaimPlan, error) {
+	head, err := p.net.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("player: head: %w", err)
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)}
+	pools, err := p.net.Pools(opts)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ClaimPlan{User: user, Block: head, Pools: []RewardClaim{}}
+	for _, ref := range pools {
+		pending, err := p.net.Router.GetAllPendingRewards(opts, ref.ID, user)
+		if err != nil {
+			return nil, fmt.Errorf("player: pool %s pending rewards: %w", ref.ID, err)
+		}
+		c := RewardClaim{PoolID: ref.ID, Pool: ref.Address}
+		for i, t := range pending.RewardTokens {
+			if pending.PendingRewards[i].Sign() > 0 {
+				c.Rewards = append(c.Rewards, Amount{Token: t, Amount: pending.PendingRewards[i]})
+			}
+		}
+		if len(c.Rewards) == 0 {
+			continue
+		}
+		status, err := ref.Pool.PoolStatus(opts)
+		if err != nil {
+			return nil, fmt.Errorf("player: pool %s status: %w", ref.ID, err)
+		}
+		c.Status = config.PoolStatus(status)
+		if c.Status == config.PoolPaused {
+			c.Skipped = "paused"
+		}
+		if c.Value, err = p.value(opts, c.Rewards); err != nil {
+			return nil, err
+		}
+		plan.Pools = append(plan.Pools, c)
+	}
+	orderClaims(plan.Pools)
+	return plan, nil
+}
+
+// orderClaims puts claimable pools first, most valuable first.
+func orderClaims(pools []RewardClaim) {
+	sort.SliceStable(pools, func(i, j int) bool {
+		a, b := &pools[i], &pools[j]
+		if (a.Skipped == "") != (b.Skipped == "") {
+			return a.Skipped == ""
+		}
+		return a.Value > b.Value
+	})
+}
+
+// value prices rewards per whole token. Without Prices every whole token
+// counts as one; with Prices, unlisted tokens count as nothing.
+func (p *Player) value(opts *bind.CallOpts, rewards []Amount) (float64, error) {
+	var total float64
+	for _, r := range rewards {
+		price := 1.0
+		if p.Prices != nil {
+			price = p.Prices[r.Token]
+		}
+		if price == 0 {
+			continue
+		}
+		dec, err := p.decimals(opts, r.Token)
+		if err != nil {
+			return 0, err
+		}
+		whole, _ := new(big.Float).Quo(new(big.Float).SetInt(r.Amount), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dec)), nil))).Float64()
+		total += whole * price
+	}
+	return total, nil
+}
+
+func (p *Player) decimals(opts *bind.CallOpts, token common.Address) (uint8, error) {
+	if dec, ok := p.decimalsCache[token]; ok {
+		return dec, nil
+	}
+	c, err := erc20.NewCaller(token, p.net.Client)
+	if err != nil {
+		return 0, err
+	}
+	dec, err := c.Decimals(opts)
+	if err != nil {
+		return 0, fmt.Errorf("player: decimals of %s: %w", token, err)
+	}
+	p.decimalsCache[token] = dec
+	return dec, nil
+}
+
+// ClaimEverything plans and claims every pool where user has rewards; tm
+// must sign for user.
+func (p *Player) ClaimEverything(ctx context.Context, tm *txmgr.Manager, user common.Address) ([]TokenClaim, error) {
+	plan, err := p.PlanClaims(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return p.Claim(ctx, tm, plan)
+}
+
+// Claim claims every pool in plan that is not skipped, most valuable
+// first, with one claimRewards per pool from tm's account. A failing pool
+// does not stop the rest. The result has one entry per reward token, sorted
+// by token.
+func (p *Player) Claim(ctx context.Context, tm *txmgr.Manager, plan *ClaimPlan) ([]TokenClaim, error) {
+	if tm.From() != plan.User {
+		return nil, fmt.Errorf("%w: %s signs, plan is for %s", ErrWrongSender, tm.From(), plan.User)
+	}
+	tally := make(map[common.Address]*TokenClaim)
+	for i := range plan.Pools {
+		c := &plan.Pools[i]
+		if c.Skipped != "" {
+			tallyClaim(tally, plan.User, c, nil, nil)
+			continue
+		}
+		receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return p.net.Router.ClaimRewards(o, c.PoolID)
+		})
+		tallyClaim(tally, plan.User, c, receipt, err)
+	}
+	out := make([]TokenClaim, 0, len(tally))
+	for _, tc := range tally {
+		out = append(out, *tc)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Token[:], out[j].Token[:]) < 0 })
+	return out, nil
+}
+
+// tallyClaim adds the outcome of claiming c to tally. A nil receipt and
+// error mean the pool was skipped.
+func tallyClaim(tally map[common.Address]*TokenClaim, user common.Address, c *RewardClaim, receipt *types.Receipt, err error) {
+	get := func(token common.Address) *TokenClaim {
+		tc := tally[token]
+		if tc == nil {
+			tc = &TokenClaim{Token: token, Pending: new(big.Int), Claimed: new(big.Int), Failed: new(big.Int), Skipped: new(big.Int)}
+			tally[token] = tc
+		}
+		return tc
+	}
+	if receipt == nil || err != nil {
+		for _, r := range c.Rewards {
+			tc := get(r.Token)
+			tc.Skipped.Add(tc.Skipped, r.Amount)
+			if err != nil {
+				tc.Errors = append(tc.Errors, fmt.Sprintf("pool %s: %v", c.PoolID, err))
+			}
+		}
+		return
+	}
+	for _, r := range c.Rewards {
+		tc := get(r.Token)
+		tc.Pending.Add(tc.Pending, r.Amount)
+	}
+	pool, _ := binding.NewCrossGameRewardPoolFilterer(c.Pool, nil)
+	for _, l := range receipt.Logs {
+		if l.Address != c.Pool {
+			continue
+		}
+		if ev, err := pool.ParseRewardClaimed(*l); err == nil && ev.Account == user {
+			tc := get(ev.Token)
+			tc.Claimed.Add(tc.Claimed, ev.Amount)
+			tc.Pools = append(tc.Pools, c.PoolID)
+		} else if ev, err := pool.ParseRewardClaimFailed(*l); err == nil && ev.Account == user {
+			tc := get(ev.Token)
+			tc.Failed.Add(tc.Failed, ev.Amount)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package player
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/testlog"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	user     = common.HexToAddress("0x05e1")
+	poolAddr = common.HexToAddress("0xb001")
+)
+
+func TestOrderClaims(t *testing.T) {
+	pools := []RewardClaim{
+		{PoolID: big.NewInt(1), Value: 5},
+		{PoolID: big.NewInt(2), Value: 50, Skipped: "paused"},
+		{PoolID: big.NewInt(3), Value: 9},
+		{PoolID: big.NewInt(4), Value: 5},
+	}
+	orderClaims(pools)
+	var got []int64
+	for _, c := range pools {
+		got = append(got, c.PoolID.Int64())
+	}
+	if want := []int64{3, 1, 4, 2}; !equal(got, want) {
+		t.Errorf("order %v, want %v", got, want)
+	}
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func claimLog(t *testing.T, event string, account, token common.Address, amount int64) *types.Log {
+	l := testlog.Event(t, binding.CrossGameRewardPoolMetaData, poolAddr, event,
+		[]common.Hash{testlog.Topic(account), testlog.Topic(token)}, big.NewInt(amount))
+	return &l
+}
+
+func TestTallyClaim(t *testing.T) {
+	tally := make(map[common.Address]*TokenClaim)
+	claimed := &RewardClaim{PoolID: big.NewInt(1), Pool: poolAddr, Rewards: []Amount{
+		{tokenA, big.NewInt(100)}, {tokenB, big.NewInt(40)},
+	}}
+	tallyClaim(tally, user, claimed, &types.Receipt{Logs: []*types.Log{
+		claimLog(t, "RewardClaimed", user, tokenA, 101),
+		claimLog(t, "RewardClaimFailed", user, tokenB, 40),
+		claimLog(t, "RewardClaimed", common.HexToAddress("0x07"), tokenA, 9),
+	}}, nil)
+	reverted := &RewardClaim{PoolID: big.NewInt(2), Pool: poolAddr, Rewards: []Amount{{tokenA, big.NewInt(7)}}}
+	tallyClaim(tally, user, reverted, nil, errors.New("reverted"))
+	paused := &RewardClaim{PoolID: big.NewInt(3), Pool: poolAddr, Rewards: []Amount{{tokenB, big.NewInt(3)}}, Skipped: "paused"}
+	tallyClaim(tally, user, paused, nil, nil)
+
+	a, b := tally[tokenA], tally[tokenB]
+	if a.Pending.Int64() != 100 || a.Claimed.Int64() != 101 || a.Skipped.Int64() != 7 || len(a.Pools) != 1 || len(a.Errors) != 1 {
+		t.Errorf("token A %+v", a)
+	}
+	if b.Pending.Int64() != 40 || b.Claimed.Sign() != 0 || b.Failed.Int64() != 40 || b.Skipped.Int64() != 3 || len(b.Errors) != 0 {
+		t.Errorf("token B %+v", b)
+	}
+}
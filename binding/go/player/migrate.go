@@ -0,0 +1,337 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/erc20"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// ErrDepositFailed is returned for a migration whose withdrawal went
+// through but whose deposit did not; the funds are in the user's wallet.
+var ErrDepositFailed = errors.New("player: withdrawn but not redeposited")
+
+// PermitTTL is how long a deposit permit signature stays valid.
+const PermitTTL = time.Hour
+
+// permitABI is the EIP-2612 surface the generated bindings lack.
+var permitABI = mustPermitABI()
+
+func mustPermitABI() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"DOMAIN_SEPARATOR","stateMutability":"view","inputs":[],"outputs":[{"type":"bytes32"}]},
+		{"type":"function","name":"nonces","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"type":"uint256"}]}
+	]`))
+	if err != nil {
+		panic(err) // only fails on a malformed ABI literal
+	}
+	return parsed
+}
+
+var permitTypeHash = crypto.Keccak256Hash([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+
+// Migration moves a position out of an Inactive pool into the Active pool
+// for the same deposit token.
+type Migration struct {
+	From     *big.Int
+	FromPool common.Address
+	// To is the successor pool; nil when there is none.
+	To           *big.Int
+	ToPool       common.Address
+	DepositToken common.Address
+	Native       bool
+	Amount       *big.Int
+	// Rewards are paid out by the withdrawal.
+	Rewards []query.Reward
+	// MinDeposit is the successor's minimum deposit.
+	MinDeposit *big.Int
+	// Permit is set when the deposit token supports EIP-2612, so the
+	// deposit needs no separate approval.
+	Permit bool
+	// Skipped says why the position cannot be migrated.
+	Skipped string
+}
+
+// MigrationPlan is the dry run of migrating User's retired positions.
+type MigrationPlan struct {
+	User       common.Address
+	Block      uint64
+	Migrations []Migration
+}
+
+// MigrationResult is the outcome of one migration.
+type MigrationResult struct {
+	Migration
+	// Withdrawn is the amount the router reported withdrawing.
+	Withdrawn  *big.Int
+	WithdrawTx common.Hash
+	// ApproveTx is set when the router needed a fresh allowance.
+	ApproveTx common.Hash
+	DepositTx common.Hash
+	Err       error
+}
+
+// PlanMigrations finds user's deposits in Inactive pools at the head block
+// and, for each, the Active pool with the same deposit token. When several
+// are Active the newest, by pool ID, is chosen.
+func (p *Player) PlanMigrations(ctx context.Context, user common.Address) (*MigrationPlan, error) {
+	head, err := p.net.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("player: head: %w", err)
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)}
+	positions, err := p.reader.Positions(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	plan := &MigrationPlan{User: user, Block: head, Migrations: []Migration{}}
+	for _, pos := range positions {
+		if pos.Deposited.Sign() == 0 {
+			continue
+		}
+		pool, err := p.net.Pool(pos.Pool)
+		if err != nil {
+			return nil, err
+		}
+		status, err := pool.PoolStatus(opts)
+		if err != nil {
+			return nil, fmt.Errorf("player: pool %s status: %w", pos.PoolID, err)
+		}
+		if config.PoolStatus(status) != config.PoolInactive {
+			continue
+		}
+		m := Migration{
+			From:         pos.PoolID,
+			FromPool:     pos.Pool,
+			DepositToken: pos.DepositToken,
+			Native:       pos.DepositToken == p.net.WCROSSAddress,
+			Amount:       pos.Deposited,
+			Rewards:      pos.Pending,
+		}
+		if err := p.successor(opts, &m); err != nil {
+			return nil, err
+		}
+		if m.To != nil && !m.Native {
+			m.Permit = p.supportsPermit(opts, m.DepositToken, user)
+		}
+		plan.Migrations = append(plan.Migrations, m)
+	}
+	return plan, nil
+}
+
+// successor sets the target of m, or why there is none.
+func (p *Player) successor(opts *bind.CallOpts, m *Migration) error {
+	ids, err := p.net.Factory.GetPoolIdsByDepositToken(opts, m.DepositToken)
+	if err != nil {
+		return fmt.Errorf("player: pools for %s: %w", m.DepositToken, err)
+	}
+	statuses := make([]config.PoolStatus, len(ids))
+	for i, id := range ids {
+		_, pool, err := p.net.PoolByID(opts, id)
+		if err != nil {
+			return err
+		}
+		status, err := pool.PoolStatus(opts)
+		if err != nil {
+			return fmt.Errorf("player: pool %s status: %w", id, err)
+		}
+		statuses[i] = config.PoolStatus(status)
+	}
+	to := newestActive(ids, statuses)
+	if to == nil {
+		m.Skipped = fmt.Sprintf("no active pool for deposit token %s", m.DepositToken.Hex())
+		return nil
+	}
+	addr, pool, err := p.net.PoolByID(opts, to)
+	if err != nil {
+		return err
+	}
+	if m.MinDeposit, err = pool.MinDepositAmount(opts); err != nil {
+		return fmt.Errorf("player: pool %s minimum deposit: %w", to, err)
+	}
+	m.To, m.ToPool = to, addr
+	if m.Amount.Cmp(m.MinDeposit) < 0 {
+		m.Skipped = fmt.Sprintf("%s is below pool %s's minimum deposit of %s", m.Amount, to, m.MinDeposit)
+	}
+	return nil
+}
+
+// newestActive returns the highest Active pool ID, or nil.
+func newestActive(ids []*big.Int, statuses []config.PoolStatus) *big.Int {
+	var out *big.Int
+	for i, id := range ids {
+		if statuses[i] == config.PoolActive && (out == nil || id.Cmp(out) > 0) {
+			out = id
+		}
+	}
+	return out
+}
+
+func (p *Player) supportsPermit(opts *bind.CallOpts, token, owner common.Address) bool {
+	c := bind.NewBoundContract(token, permitABI, p.net.Client, nil, nil)
+	var out []any
+	if err := c.Call(opts, &out, "DOMAIN_SEPARATOR"); err != nil || len(out) != 1 {
+		return false
+	}
+	out = nil
+	return c.Call(opts, &out, "nonces", owner) == nil && len(out) == 1
+}
+
+// permitDigest is the EIP-712 hash an EIP-2612 permit signs.
+func permitDigest(domain common.Hash, owner, spender common.Address, value, nonce, deadline *big.Int) common.Hash {
+	word := func(v *big.Int) []byte { return common.BigToHash(v).Bytes() }
+	structHash := crypto.Keccak256(
+		permitTypeHash[:],
+		common.BytesToHash(owner[:]).Bytes(),
+		common.BytesToHash(spender[:]).Bytes(),
+		word(value), word(nonce), word(deadline),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain[:], structHash)
+}
+
+// Migrate executes plan from tm's account. Each migration withdraws from
+// the retired pool, collecting its rewards, then deposits the withdrawn
+// amount into the successor through the native or ERC-20 router path,
+// with a permit when the token supports one. Skipped migrations are
+// reported without sending anything; a failing migration does not stop
+// the rest. Each result is passed to progress, if set, as it finishes.
+func (p *Player) Migrate(ctx context.Context, tm *txmgr.Manager, plan *MigrationPlan, progress func(done, total int, r MigrationResult)) ([]MigrationResult, error) {
+	if tm.From() != plan.User {
+		return nil, fmt.Errorf("%w: %s signs, plan is for %s", ErrWrongSender, tm.From(), plan.User)
+	}
+	out := make([]MigrationResult, 0, len(plan.Migrations))
+	for _, m := range plan.Migrations {
+		r := MigrationResult{Migration: m}
+		if m.Skipped == "" {
+			r.Err = p.migrate(ctx, tm, &r)
+		}
+		out = append(out, r)
+		if progress != nil {
+			progress(len(out), len(plan.Migrations), r)
+		}
+	}
+	return out, nil
+}
+
+func (p *Player) migrate(ctx context.Context, tm *txmgr.Manager, r *MigrationResult) error {
+	receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		if r.Native {
+			return p.net.Router.WithdrawNative(o, r.From)
+		}
+		return p.net.Router.WithdrawERC20(o, r.From)
+	})
+	if receipt != nil {
+		r.WithdrawTx = receipt.TxHash
+	}
+	if err != nil {
+		return fmt.Errorf("player: withdraw from pool %s: %w", r.From, err)
+	}
+	if r.Withdrawn = p.withdrawn(receipt, r.From, tm.From()); r.Withdrawn == nil {
+		return fmt.Errorf("%w: no withdrawal event in %s", ErrDepositFailed, receipt.TxHash)
+	}
+	if r.Withdrawn.Cmp(r.MinDeposit) < 0 {
+		return fmt.Errorf("%w: withdrew %s, below pool %s's minimum deposit of %s", ErrDepositFailed, r.Withdrawn, r.To, r.MinDeposit)
+	}
+	if err := p.deposit(ctx, tm, r); err != nil {
+		return fmt.Errorf("%w: %s of %s into pool %s: %v", ErrDepositFailed, r.Withdrawn, r.DepositToken.Hex(), r.To, err)
+	}
+	return nil
+}
+
+// withdrawn reads the amount from the router's withdrawal event.
+func (p *Player) withdrawn(receipt *types.Receipt, poolID *big.Int, user common.Address) *big.Int {
+	for _, l := range receipt.Logs {
+		if l.Address != p.router {
+			continue
+		}
+		if ev, err := p.net.Router.ParseWithdrawnNative(*l); err == nil && ev.User == user && ev.PoolId.Cmp(poolID) == 0 {
+			return ev.Amount
+		}
+		if ev, err := p.net.Router.ParseWithdrawnERC20(*l); err == nil && ev.User == user && ev.PoolId.Cmp(poolID) == 0 {
+			return ev.Amount
+		}
+	}
+	return nil
+}
+
+func (p *Player) deposit(ctx context.Context, tm *txmgr.Manager, r *MigrationResult) error {
+	var send txmgr.SendFunc
+	switch {
+	case r.Native:
+		send = func(o *bind.TransactOpts) (*types.Transaction, error) {
+			o.Value = r.Withdrawn
+			return p.net.Router.DepositNative(o, r.To)
+		}
+	case r.Permit:
+		v, rs, ss, deadline, err := p.signPermit(ctx, tm, r.DepositToken, r.Withdrawn)
+		if err != nil {
+			return err
+		}
+		send = func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return p.net.Router.DepositERC20WithPermit(o, r.To, r.Withdrawn, deadline, v, rs, ss)
+		}
+	default:
+		if err := p.approve(ctx, tm, r); err != nil {
+			return err
+		}
+		send = func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return p.net.Router.DepositERC20(o, r.To, r.Withdrawn)
+		}
+	}
+	receipt, err := tm.Send(ctx, send)
+	if receipt != nil {
+		r.DepositTx = receipt.TxHash
+	}
+	return err
+}
+
+func (p *Player) signPermit(ctx context.Context, tm *txmgr.Manager, token common.Address, value *big.Int) (uint8, [32]byte, [32]byte, *big.Int, error) {
+	opts := tm.CallOpts(ctx)
+	c := bind.NewBoundContract(token, permitABI, p.net.Client, nil, nil)
+	var domain, nonce []any
+	if err := c.Call(opts, &domain, "DOMAIN_SEPARATOR"); err != nil {
+		return 0, [32]byte{}, [32]byte{}, nil, fmt.Errorf("player: DOMAIN_SEPARATOR of %s: %w", token, err)
+	}
+	if err := c.Call(opts, &nonce, "nonces", tm.From()); err != nil {
+		return 0, [32]byte{}, [32]byte{}, nil, fmt.Errorf("player: permit nonce of %s: %w", token, err)
+	}
+	deadline := big.NewInt(time.Now().Add(PermitTTL).Unix())
+	digest := permitDigest(domain[0].([32]byte), tm.From(), p.router, value, nonce[0].(*big.Int), deadline)
+	v, r, s, err := tm.SignHash(digest)
+	return v, r, s, deadline, err
+}
+
+// approve raises the router's allowance to the withdrawn amount if needed.
+func (p *Player) approve(ctx context.Context, tm *txmgr.Manager, r *MigrationResult) error {
+	token, err := erc20.New(r.DepositToken, p.net.Client)
+	if err != nil {
+		return err
+	}
+	allowance, err := token.Allowance(tm.CallOpts(ctx), tm.From(), p.router)
+	if err != nil {
+		return fmt.Errorf("player: allowance of %s: %w", r.DepositToken, err)
+	}
+	if allowance.Cmp(r.Withdrawn) >= 0 {
+		return nil
+	}
+	receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return token.Approve(o, p.router, r.Withdrawn)
+	})
+	if receipt != nil {
+		r.ApproveTx = receipt.TxHash
+	}
+	return err
+}
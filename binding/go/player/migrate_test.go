@@ -0,0 +1,70 @@
+package player
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+func TestNewestActive(t *testing.T) {
+	ids := []*big.Int{big.NewInt(1), big.NewInt(4), big.NewInt(7), big.NewInt(9)}
+	if got := newestActive(ids, []config.PoolStatus{config.PoolActive, config.PoolActive, config.PoolActive, config.PoolPaused}); got.Int64() != 7 {
+		t.Errorf("newest active %v, want 7", got)
+	}
+	if got := newestActive(ids, []config.PoolStatus{config.PoolInactive, config.PoolPaused, config.PoolInactive, config.PoolInactive}); got != nil {
+		t.Errorf("no active pool, got %v", got)
+	}
+}
+
+func TestPermitDigest(t *testing.T) {
+	owner, spender := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+	token := common.HexToAddress("0x70")
+	value, nonce, deadline := big.NewInt(1e18), big.NewInt(3), big.NewInt(1_700_000_000)
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Token",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(612044),
+			VerifyingContract: token.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    owner.Hex(),
+			"spender":  spender.Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": deadline.String(),
+		},
+	}
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain, err := typed.HashStruct("EIP712Domain", typed.Domain.Map())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := permitDigest(common.BytesToHash(domain), owner, spender, value, nonce, deadline); got != common.BytesToHash(want) {
+		t.Errorf("digest %s, want %x", got, want)
+	}
+}
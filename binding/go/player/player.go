@@ -0,0 +1,74 @@
+// Package player runs multi-pool actions for one account through the
+// router.
+//
+// The router acts on one pool per call, apart from withdrawAll, which loops
+// over every pool in a single transaction and can run out of gas as pools
+// and reward tokens grow. PlanWithdrawAll lists what withdrawAll would pay
+// out and estimates its gas; above a limit, or when a paused pool would
+// make it revert, the plan falls back to one withdrawal per pool.
+// ClaimEverything claims rewards from every pool that has any, most
+// valuable first, and reports the outcome per reward token. Migrate moves
+// deposits out of Inactive pools into the Active pool for the same deposit
+// token.
+package player
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	// ErrNoRouter is returned when the factory has no router set.
+	ErrNoRouter = errors.New("player: factory has no router")
+	// ErrWrongSender is returned when the transaction manager signs for a
+	// different account than the plan was made for.
+	ErrWrongSender = errors.New("player: sender is not the planned user")
+)
+
+var routerABI = mustRouterABI()
+
+func mustRouterABI() *abi.ABI {
+	parsed, err := binding.CrossGameRewardRouterMetaData.GetAbi()
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	return parsed
+}
+
+// Player plans and sends router actions on one network.
+type Player struct {
+	net    *config.Network
+	reader *query.Reader
+	router common.Address
+
+	// Prices values reward tokens per whole token when ordering claims.
+	// Nil counts every whole token as one.
+	Prices map[common.Address]float64
+
+	decimalsCache map[common.Address]uint8
+}
+
+// New returns a Player for the router of net.
+func New(net *config.Network) (*Player, error) {
+	if net.Router == nil {
+		return nil, ErrNoRouter
+	}
+	return &Player{net: net, reader: query.New(net), router: net.RouterAddress, decimalsCache: make(map[common.Address]uint8)}, nil
+}
+
+// pack encodes a router call; the methods and arguments are fixed by this
+// package.
+func pack(method string, args ...any) []byte {
+	input, err := routerABI.Pack(method, args...)
+	if err != nil {
+		panic(fmt.Sprintf("player: pack %s: %v", method, err))
+	}
+	return input
+}
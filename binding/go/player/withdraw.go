@@ -0,0 +1,254 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// DefaultGasLimit is the withdrawAll estimate above which a plan is split
+// into per-pool withdrawals.
+const DefaultGasLimit = 8_000_000
+
+// Native is the Token under which native CROSS appears in a payout.
+var Native = common.Address{}
+
+// Withdrawal is one pool withdrawAll would empty.
+type Withdrawal struct {
+	*query.Position
+	Status config.PoolStatus
+	// Native is set for WCROSS pools, which pay the deposit out as native
+	// CROSS.
+	Native bool
+	// Blocked says why the pool's withdrawal would revert: it is paused or
+	// its estimate failed. Empty for pools that can be withdrawn.
+	Blocked string
+	// Gas is the estimate of the pool's own withdrawal; zero if blocked.
+	Gas uint64
+}
+
+// Amount is a quantity of one token.
+type Amount struct {
+	Token  common.Address
+	Amount *big.Int
+}
+
+// WithdrawPlan is what withdrawing everything for User would do.
+type WithdrawPlan struct {
+	User  common.Address
+	Block uint64
+	// Pools lists every pool with a deposit, in factory order.
+	Pools []Withdrawal
+	// Receive totals deposits and rewards paid out by the unblocked pools,
+	// sorted by token, with native CROSS under Native.
+	Receive []Amount
+	// Gas is the withdrawAll estimate; zero if it would revert.
+	Gas uint64
+	// Limit is the estimate above which the plan is chunked.
+	Limit uint64
+	// Chunked is set when the plan withdraws pool by pool; Reason says why.
+	Chunked bool
+	Reason  string
+}
+
+// PlanWithdrawAll reads user's deposits at the head block and estimates
+// withdrawAll against limit. Zero limit means DefaultGasLimit.
+func (p *Player) PlanWithdrawAll(ctx context.Context, user common.Address, limit uint64) (*WithdrawPlan, error) {
+	if limit == 0 {
+		limit = DefaultGasLimit
+	}
+	head, err := p.net.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("player: head: %w", err)
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(head)}
+	positions, err := p.reader.Positions(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	plan := &WithdrawPlan{User: user, Block: head, Pools: []Withdrawal{}, Limit: limit}
+	for _, pos := range positions {
+		if pos.Deposited.Sign() == 0 {
+			continue // withdrawAll skips pools with only rewards left
+		}
+		pool, err := p.net.Pool(pos.Pool)
+		if err != nil {
+			return nil, err
+		}
+		w := Withdrawal{Position: pos, Native: pos.DepositToken == p.net.WCROSSAddress}
+		status, err := pool.PoolStatus(opts)
+		if err != nil {
+			return nil, fmt.Errorf("player: pool %s status: %w", pos.PoolID, err)
+		}
+		w.Status = config.PoolStatus(status)
+		if w.Status == config.PoolPaused {
+			w.Blocked = "paused"
+		} else if w.Gas, err = p.estimate(ctx, user, withdrawInput(&w)); err != nil {
+			w.Blocked = fmt.Sprintf("estimate failed: %v", err)
+		}
+		plan.Pools = append(plan.Pools, w)
+	}
+	plan.Receive = payout(plan.Pools)
+	if len(plan.Pools) == 0 {
+		return plan, nil
+	}
+	gas, err := p.estimate(ctx, user, pack("withdrawAll"))
+	if err == nil {
+		plan.Gas = gas
+	}
+	decide(plan, err)
+	return plan, nil
+}
+
+// decide sets whether plan is chunked; estErr is the withdrawAll estimate
+// failure, if any.
+func decide(plan *WithdrawPlan, estErr error) {
+	for _, w := range plan.Pools {
+		if w.Blocked != "" {
+			plan.Chunked, plan.Reason = true, fmt.Sprintf("pool %s is blocked (%s); withdrawAll would revert", w.PoolID, w.Blocked)
+			return
+		}
+	}
+	switch {
+	case estErr != nil:
+		plan.Chunked, plan.Reason = true, fmt.Sprintf("withdrawAll estimate failed: %v", estErr)
+	case plan.Gas > plan.Limit:
+		plan.Chunked, plan.Reason = true, fmt.Sprintf("withdrawAll needs %d gas, above the %d limit", plan.Gas, plan.Limit)
+	}
+}
+
+// payout totals what the unblocked pools pay out.
+func payout(pools []Withdrawal) []Amount {
+	sums := make(map[common.Address]*big.Int)
+	add := func(token common.Address, v *big.Int) {
+		if v.Sign() == 0 {
+			return
+		}
+		if sums[token] == nil {
+			sums[token] = new(big.Int)
+		}
+		sums[token].Add(sums[token], v)
+	}
+	for _, w := range pools {
+		if w.Blocked != "" {
+			continue
+		}
+		if w.Native {
+			add(Native, w.Deposited)
+		} else {
+			add(w.DepositToken, w.Deposited)
+		}
+		for _, r := range w.Pending {
+			add(r.Token, r.Amount)
+		}
+	}
+	out := make([]Amount, 0, len(sums))
+	for t, v := range sums {
+		out = append(out, Amount{Token: t, Amount: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Token[:], out[j].Token[:]) < 0 })
+	return out
+}
+
+func withdrawInput(w *Withdrawal) []byte {
+	if w.Native {
+		return pack("withdrawNative", w.PoolID)
+	}
+	return pack("withdrawERC20", w.PoolID)
+}
+
+func (p *Player) estimate(ctx context.Context, from common.Address, input []byte) (uint64, error) {
+	return p.net.Client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &p.router, Data: input})
+}
+
+// Step is the outcome of one withdrawal transaction.
+type Step struct {
+	// PoolID is nil for the single withdrawAll.
+	PoolID *big.Int
+	TxHash common.Hash
+	Block  uint64
+	// Skipped says why a pool was not withdrawn: it is blocked or already
+	// empty.
+	Skipped string
+	Err     error
+}
+
+// WithdrawAll executes plan from tm's account: one withdrawAll, or one
+// withdrawal per pool when the plan is chunked. Each step is passed to
+// progress, if set, as it finishes. A failing pool does not stop the rest;
+// its error is kept in its step. Pools emptied since the plan was made are
+// skipped, so a plan can be executed again after an interruption.
+func (p *Player) WithdrawAll(ctx context.Context, tm *txmgr.Manager, plan *WithdrawPlan, progress func(done, total int, s Step)) ([]Step, error) {
+	if tm.From() != plan.User {
+		return nil, fmt.Errorf("%w: %s signs, plan is for %s", ErrWrongSender, tm.From(), plan.User)
+	}
+	report := func(steps []Step, total int) {
+		if progress != nil {
+			progress(len(steps), total, steps[len(steps)-1])
+		}
+	}
+	if !plan.Chunked {
+		s := p.send(ctx, tm, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return p.net.Router.WithdrawAll(o)
+		})
+		steps := []Step{s}
+		report(steps, 1)
+		return steps, nil
+	}
+	steps := make([]Step, 0, len(plan.Pools))
+	for i := range plan.Pools {
+		w := &plan.Pools[i]
+		s := Step{PoolID: w.PoolID}
+		if w.Blocked != "" {
+			s.Skipped = w.Blocked
+		} else if bal, err := p.balance(ctx, w, plan.User); err != nil {
+			s.Err = err
+		} else if bal.Sign() == 0 {
+			s.Skipped = "already withdrawn"
+		} else {
+			s = p.send(ctx, tm, func(o *bind.TransactOpts) (*types.Transaction, error) {
+				if w.Native {
+					return p.net.Router.WithdrawNative(o, w.PoolID)
+				}
+				return p.net.Router.WithdrawERC20(o, w.PoolID)
+			})
+			s.PoolID = w.PoolID
+		}
+		steps = append(steps, s)
+		report(steps, len(plan.Pools))
+	}
+	return steps, nil
+}
+
+func (p *Player) send(ctx context.Context, tm *txmgr.Manager, fn txmgr.SendFunc) Step {
+	var s Step
+	receipt, err := tm.Send(ctx, fn)
+	if receipt != nil {
+		s.TxHash, s.Block = receipt.TxHash, receipt.BlockNumber.Uint64()
+	}
+	s.Err = err
+	return s
+}
+
+func (p *Player) balance(ctx context.Context, w *Withdrawal, user common.Address) (*big.Int, error) {
+	pool, err := p.net.Pool(w.Pool)
+	if err != nil {
+		return nil, err
+	}
+	bal, err := pool.Balances(&bind.CallOpts{Context: ctx}, user)
+	if err != nil {
+		return nil, fmt.Errorf("player: pool %s balance: %w", w.PoolID, err)
+	}
+	return bal, nil
+}
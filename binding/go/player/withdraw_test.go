@@ -0,0 +1,78 @@
+package player
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/query"
+)
+
+var (
+	tokenA  = common.HexToAddress("0xa0")
+	tokenB  = common.HexToAddress("0xb0")
+	rewardR = common.HexToAddress("0xc0")
+)
+
+func withdrawal(id int64, token common.Address, deposited int64, native bool, rewards ...int64) Withdrawal {
+	pos := &query.Position{PoolID: big.NewInt(id), DepositToken: token, Deposited: big.NewInt(deposited)}
+	for _, r := range rewards {
+		pos.Pending = append(pos.Pending, query.Reward{Token: rewardR, Amount: big.NewInt(r)})
+	}
+	return Withdrawal{Position: pos, Native: native}
+}
+
+func TestPayout(t *testing.T) {
+	paused := withdrawal(3, tokenB, 500, false, 70)
+	paused.Blocked = "paused"
+	got := payout([]Withdrawal{
+		withdrawal(1, tokenA, 100, true, 5, 0),
+		withdrawal(2, tokenB, 200, false, 7),
+		paused,
+	})
+	want := []Amount{{Native, big.NewInt(100)}, {tokenB, big.NewInt(200)}, {rewardR, big.NewInt(12)}}
+	if len(got) != len(want) {
+		t.Fatalf("payout %v", got)
+	}
+	for i := range want {
+		if got[i].Token != want[i].Token || got[i].Amount.Cmp(want[i].Amount) != 0 {
+			t.Errorf("payout[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	paused := withdrawal(2, tokenB, 1, false)
+	paused.Blocked = "paused"
+	for _, tc := range []struct {
+		name    string
+		pools   []Withdrawal
+		gas     uint64
+		err     error
+		chunked string
+	}{
+		{"fits", []Withdrawal{withdrawal(1, tokenA, 1, false)}, 300_000, nil, ""},
+		{"over limit", []Withdrawal{withdrawal(1, tokenA, 1, false)}, 2_000_000, nil, "above the 1000000 limit"},
+		{"estimate failed", []Withdrawal{withdrawal(1, tokenA, 1, false)}, 0, errors.New("out of gas"), "out of gas"},
+		{"paused pool", []Withdrawal{withdrawal(1, tokenA, 1, false), paused}, 300_000, nil, "pool 2 is blocked"},
+	} {
+		plan := &WithdrawPlan{Pools: tc.pools, Gas: tc.gas, Limit: 1_000_000}
+		decide(plan, tc.err)
+		if plan.Chunked != (tc.chunked != "") || !strings.Contains(plan.Reason, tc.chunked) {
+			t.Errorf("%s: chunked %v reason %q", tc.name, plan.Chunked, plan.Reason)
+		}
+	}
+}
+
+func TestWithdrawInput(t *testing.T) {
+	native, erc20 := withdrawal(1, tokenA, 1, true), withdrawal(1, tokenA, 1, false)
+	if m, err := routerABI.MethodById(withdrawInput(&native)); err != nil || m.RawName != "withdrawNative" || len(m.Inputs) != 1 {
+		t.Errorf("native pool calls %v (%v)", m, err)
+	}
+	if m, err := routerABI.MethodById(withdrawInput(&erc20)); err != nil || m.RawName != "withdrawERC20" || len(m.Inputs) != 1 {
+		t.Errorf("ERC-20 pool calls %v (%v)", m, err)
+	}
+}
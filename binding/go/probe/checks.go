@@ -0,0 +1,215 @@
+package probe
+
+import (
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// MaxDecimals is the largest decimals() value accepted without a warning.
+const MaxDecimals = 18
+
+func checkMetadata(r *Report, res []callResult) {
+	dec, decOK := uint256(res[0].ReturnData)
+	switch {
+	case !res[0].ok():
+		r.add("decimals", Fail, "decimals() reverted: %s", res[0].failure())
+	case !decOK || !dec.IsUint64() || dec.Uint64() > 255:
+		r.add("decimals", Fail, "decimals() returned %s, not a uint8", hexutil.Encode(res[0].ReturnData))
+	default:
+		d := uint8(dec.Uint64())
+		r.Decimals = &d
+		if d > MaxDecimals {
+			r.add("decimals", Warn, "decimals() is %d, above %d", d, MaxDecimals)
+		}
+	}
+	r.Symbol = metadataString(r, "symbol", res[1])
+	r.Name = metadataString(r, "name", res[2])
+}
+
+// metadataString decodes a string-returning getter, accepting the bytes32
+// form of early tokens with a warning.
+func metadataString(r *Report, method string, res callResult) string {
+	if !res.ok() {
+		r.add(method, Warn, "%s() reverted: %s", method, res.failure())
+		return ""
+	}
+	out, err := erc20ABI.Unpack(method, res.ReturnData)
+	if err == nil && len(out) == 1 {
+		if s, ok := out[0].(string); ok && utf8.ValidString(s) {
+			return s
+		}
+	}
+	if len(res.ReturnData) == 32 {
+		s := strings.TrimRight(string(res.ReturnData), "\x00")
+		if utf8.ValidString(s) {
+			r.add(method, Warn, "%s() returns bytes32, not string", method)
+			return s
+		}
+	}
+	r.add(method, Warn, "%s() returned %s, not a string", method, hexutil.Encode(res.ReturnData))
+	return ""
+}
+
+// Positions of the plan's calls in the first block, after any funding
+// calls, and in the second.
+const (
+	callSupply0 = iota
+	callBalanceA0
+	callTransfer
+	callBalanceA1
+	callBalanceB1
+	callApprove
+	callTransferFrom
+	callBalanceB2
+	callBalanceC2
+	callZeroTransfer
+	callSupply1
+)
+
+const (
+	laterBalanceA = iota
+	laterBalanceB
+	laterBalanceC
+	laterSupply
+)
+
+// Drift is how far the second block of the plan is moved ahead.
+const Drift = 24 * 60 * 60
+
+// transferPlan returns the simulated blocks: fund A, move half the amount
+// A to B by transfer and half of that B to C by approve/transferFrom, then
+// read everything again a day later. Each balance is read between steps.
+func transferPlan(token common.Address, amount *big.Int, fund simBlock, headTime uint64) []simBlock {
+	x := new(big.Int).Rsh(amount, 1)
+	y := new(big.Int).Rsh(x, 1)
+	first := fund
+	first.Calls = append(append([]simCall{}, fund.Calls...),
+		call(token, common.Address{}, "totalSupply"),
+		call(token, common.Address{}, "balanceOf", scratchA),
+		call(token, scratchA, "transfer", scratchB, x),
+		call(token, common.Address{}, "balanceOf", scratchA),
+		call(token, common.Address{}, "balanceOf", scratchB),
+		call(token, scratchB, "approve", scratchC, x),
+		call(token, scratchC, "transferFrom", scratchB, scratchC, y),
+		call(token, common.Address{}, "balanceOf", scratchB),
+		call(token, common.Address{}, "balanceOf", scratchC),
+		call(token, scratchC, "transfer", scratchA, new(big.Int)),
+		call(token, common.Address{}, "totalSupply"),
+	)
+	later := hexutil.Uint64(headTime + Drift)
+	return []simBlock{first, {
+		BlockOverrides: &blockOverrides{Time: &later},
+		Calls: []simCall{
+			call(token, common.Address{}, "balanceOf", scratchA),
+			call(token, common.Address{}, "balanceOf", scratchB),
+			call(token, common.Address{}, "balanceOf", scratchC),
+			call(token, common.Address{}, "totalSupply"),
+		},
+	}}
+}
+
+// checkTransfers evaluates the results of transferPlan; funded is the
+// number of funding calls in front of the first block.
+func checkTransfers(r *Report, amount *big.Int, funded int, res [][]callResult) {
+	first, later := res[0][funded:], res[1]
+	if funded > 0 && !res[0][0].ok() {
+		r.add("fund", Fail, "funding transfer from holder reverted: %s", res[0][0].failure())
+		return
+	}
+	read := func(calls []callResult, i int) *big.Int {
+		if v, ok := uint256(calls[i].ReturnData); ok && calls[i].ok() {
+			return v
+		}
+		return nil
+	}
+	x := new(big.Int).Rsh(amount, 1)
+	y := new(big.Int).Rsh(x, 1)
+
+	a0 := read(first, callBalanceA0)
+	if a0 == nil {
+		r.add("balanceOf", Fail, "balanceOf() reverted or returned no uint256")
+		return
+	}
+	if a0.Cmp(amount) != 0 {
+		r.add("transfer-fee", Fail, "funding with %s credited %s", amount, a0)
+	}
+	if !transferred(r, "transfer", first[callTransfer]) {
+		return
+	}
+	a1, b1 := read(first, callBalanceA1), read(first, callBalanceB1)
+	if a1 == nil || new(big.Int).Sub(a0, a1).Cmp(x) != 0 {
+		r.add("transfer-debit", Fail, "transfer of %s debited the sender %s", x, diff(a0, a1))
+	}
+	if b1 == nil || b1.Cmp(x) != 0 {
+		r.add("transfer-fee", Fail, "transfer of %s credited the recipient %s (fee on transfer)", x, b1)
+	}
+	if !first[callApprove].ok() {
+		r.add("approve", Fail, "approve() reverted: %s", first[callApprove].failure())
+		return
+	}
+	if !transferred(r, "transferFrom", first[callTransferFrom]) {
+		return
+	}
+	b2, c2 := read(first, callBalanceB2), read(first, callBalanceC2)
+	if b1 != nil && (b2 == nil || new(big.Int).Sub(b1, b2).Cmp(y) != 0) {
+		r.add("transferFrom-debit", Fail, "transferFrom of %s debited the owner %s", y, diff(b1, b2))
+	}
+	if c2 == nil || c2.Cmp(y) != 0 {
+		r.add("transferFrom-fee", Fail, "transferFrom of %s credited the spender %s (fee on transfer)", y, c2)
+	}
+	if !first[callZeroTransfer].ok() {
+		r.add("zero-transfer", Warn, "zero-amount transfer reverts: %s", first[callZeroTransfer].failure())
+	}
+	s0, s1 := read(first, callSupply0), read(first, callSupply1)
+	if s0 == nil || s1 == nil {
+		r.add("totalSupply", Fail, "totalSupply() reverted or returned no uint256")
+	} else if s0.Cmp(s1) != 0 {
+		r.add("supply", Fail, "totalSupply changed from %s to %s during transfers (mint or burn on transfer)", s0, s1)
+	}
+
+	for _, c := range []struct {
+		name   string
+		before *big.Int
+		i      int
+	}{
+		{"sender", a1, laterBalanceA},
+		{"recipient", b2, laterBalanceB},
+		{"spender", c2, laterBalanceC},
+		{"totalSupply", s1, laterSupply},
+	} {
+		after := read(later, c.i)
+		if c.before != nil && (after == nil || after.Cmp(c.before) != 0) {
+			r.add("drift", Fail, "%s balance went from %s to %s a day later without transfers (rebasing)", c.name, c.before, after)
+		}
+	}
+}
+
+// transferred checks a transfer or transferFrom result. SafeERC20 accepts
+// an empty return, so that is only a warning.
+func transferred(r *Report, method string, res callResult) bool {
+	if !res.ok() {
+		r.add(method, Fail, "%s() reverted: %s", method, res.failure())
+		return false
+	}
+	switch v, ok := uint256(res.ReturnData); {
+	case len(res.ReturnData) == 0:
+		r.add("return-value", Warn, "%s() returns no value", method)
+	case !ok:
+		r.add("return-value", Fail, "%s() returned %s, not a bool", method, hexutil.Encode(res.ReturnData))
+	case v.Sign() == 0:
+		r.add("return-value", Fail, "%s() returned false", method)
+		return false
+	}
+	return true
+}
+
+func diff(a, b *big.Int) *big.Int {
+	if a == nil || b == nil {
+		return nil
+	}
+	return new(big.Int).Sub(a, b)
+}
@@ -0,0 +1,278 @@
+// Package probe checks whether a token behaves like the plain ERC-20 the
+// pools assume, before it is used in CreatePool or AddRewardToken.
+//
+// A pool credits the full amount of a deposit after safeTransferFrom and
+// infers rewards from balanceOf deltas, so fee-on-transfer, rebasing and
+// similar tokens corrupt its accounting. The probe never sends a
+// transaction: with eth_simulateV1 it funds a scratch account, either by
+// overriding the token's balance storage or by moving funds from a given
+// holder, then moves tokens through transfer and approve/transferFrom and
+// reads every balance in between. A second simulated block a day later
+// catches balances that drift with time. It also reads decimals, name and
+// symbol and looks for blocklist, pause and proxy hooks.
+//
+// The node must support eth_simulateV1; for one that does not, run the
+// probe against a local fork.
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	// ErrIncompatible is returned by Require for tokens that failed a check.
+	ErrIncompatible = errors.New("probe: token is not compatible")
+	// ErrNoBalanceSlot is returned when the token's balance storage could
+	// not be found; pass a Holder instead.
+	ErrNoBalanceSlot = errors.New("probe: balance storage slot not found")
+)
+
+// Severity grades an Issue.
+type Severity string
+
+// Issue severities.
+const (
+	// Fail issues break pool accounting.
+	Fail Severity = "fail"
+	// Warn issues need a human decision.
+	Warn Severity = "warn"
+)
+
+// Verdict summarises a Report.
+type Verdict string
+
+// Verdicts.
+const (
+	Compatible   Verdict = "compatible"
+	Warnings     Verdict = "warnings"
+	Incompatible Verdict = "incompatible"
+)
+
+// Issue is one failed or suspicious check.
+type Issue struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Report is the outcome of probing one token.
+type Report struct {
+	Token    common.Address `json:"token"`
+	Block    uint64         `json:"block"`
+	Name     string         `json:"name,omitempty"`
+	Symbol   string         `json:"symbol,omitempty"`
+	Decimals *uint8         `json:"decimals,omitempty"`
+	// Amount is the number of base units moved through the scratch
+	// accounts.
+	Amount  *big.Int `json:"amount"`
+	Verdict Verdict  `json:"verdict"`
+	Issues  []Issue  `json:"issues"`
+}
+
+func (r *Report) add(check string, sev Severity, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Check: check, Severity: sev, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) finish() {
+	r.Verdict = Compatible
+	for _, is := range r.Issues {
+		if is.Severity == Fail {
+			r.Verdict = Incompatible
+			return
+		}
+		r.Verdict = Warnings
+	}
+	if r.Issues == nil {
+		r.Issues = []Issue{}
+	}
+}
+
+// Require returns nil if r allows the token to be used: it must be
+// compatible, or have only warnings when allowWarnings is set.
+func Require(r *Report, allowWarnings bool) error {
+	switch {
+	case r.Verdict == Compatible, r.Verdict == Warnings && allowWarnings:
+		return nil
+	case r.Verdict == Warnings:
+		return fmt.Errorf("%w: %s has %d warning(s) to review", ErrIncompatible, r.Token.Hex(), len(r.Issues))
+	}
+	return fmt.Errorf("%w: %s: %s", ErrIncompatible, r.Token.Hex(), r.Issues[0].Message)
+}
+
+// Options tune a probe.
+type Options struct {
+	// Block is the state to probe on; nil means latest.
+	Block *big.Int
+	// Amount is how many base units to move; nil means 1000 whole tokens.
+	Amount *big.Int
+	// Holder, if set, funds the scratch account by a real transfer instead
+	// of a storage override. It must hold at least Amount.
+	Holder common.Address
+}
+
+// Scratch accounts. They hold no code, like depositors and, for
+// transferFrom, a spender standing in for the pool.
+var (
+	scratchA = scratch("a")
+	scratchB = scratch("b")
+	scratchC = scratch("spender")
+)
+
+func scratch(name string) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte("cgr-probe/" + name))[12:])
+}
+
+var erc20ABI = mustERC20ABI()
+
+func mustERC20ABI() *abi.ABI {
+	parsed, err := binding.WCROSSMetaData.GetAbi()
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	return parsed
+}
+
+// Prober probes tokens on one network.
+type Prober struct {
+	rpc     rpcCaller
+	headers interface {
+		HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	}
+}
+
+// New returns a Prober using net's node.
+func New(net *config.Network) *Prober {
+	return &Prober{rpc: net.Client.Client(), headers: net.Client}
+}
+
+// Probe runs every check on token.
+func (p *Prober) Probe(ctx context.Context, token common.Address, opts Options) (*Report, error) {
+	head, err := p.headers.HeaderByNumber(ctx, opts.Block)
+	if err != nil {
+		return nil, fmt.Errorf("probe: header: %w", err)
+	}
+	block := head.Number
+	r := &Report{Token: token, Block: block.Uint64()}
+
+	meta, err := simulate(ctx, p.rpc, block, []simBlock{{Calls: []simCall{
+		call(token, common.Address{}, "decimals"),
+		call(token, common.Address{}, "symbol"),
+		call(token, common.Address{}, "name"),
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	checkMetadata(r, meta[0])
+	r.Amount = opts.Amount
+	if r.Amount == nil {
+		dec := uint8(18)
+		if r.Decimals != nil {
+			dec = *r.Decimals
+		}
+		r.Amount = new(big.Int).Mul(big.NewInt(1000), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dec)), nil))
+	}
+
+	var fund simBlock
+	if opts.Holder != (common.Address{}) {
+		fund.Calls = []simCall{call(token, opts.Holder, "transfer", scratchA, r.Amount)}
+	} else {
+		diff, err := p.balanceOverride(ctx, block, token, scratchA, r.Amount)
+		if err != nil {
+			return nil, err
+		}
+		fund.StateOverrides = map[common.Address]accountOverride{token: {StateDiff: diff}}
+	}
+	blocks := transferPlan(token, r.Amount, fund, head.Time)
+	res, err := simulate(ctx, p.rpc, block, blocks)
+	if err != nil {
+		return nil, err
+	}
+	checkTransfers(r, r.Amount, len(fund.Calls), res)
+	p.checkHooks(ctx, r, block)
+	r.finish()
+	return r, nil
+}
+
+// call builds the call of an ERC-20 method.
+func call(token, from common.Address, method string, args ...any) simCall {
+	input, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		panic(err) // arguments are fixed by this package
+	}
+	return simCall{From: from, To: token, Input: input}
+}
+
+// balanceOverride finds the storage slot of acct's balance and returns the
+// override setting it to amount.
+func (p *Prober) balanceOverride(ctx context.Context, block *big.Int, token, acct common.Address, amount *big.Int) (map[common.Hash]common.Hash, error) {
+	balanceOf := call(token, common.Address{}, "balanceOf", acct)
+	want := common.BigToHash(amount)
+	for _, slot := range balanceSlots(acct) {
+		diff := map[common.Hash]common.Hash{slot: want}
+		out, err := callWithOverride(ctx, p.rpc, block, balanceOf, token, diff)
+		if err != nil {
+			continue
+		}
+		if bal, ok := uint256(out); ok && bal.Cmp(amount) == 0 {
+			return diff, nil
+		}
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoBalanceSlot, token.Hex())
+}
+
+// Selectors of optional hooks that let an issuer freeze or redirect funds.
+var hooks = []struct {
+	check, sig string
+	arg        bool
+}{
+	{"blocklist", "isBlacklisted(address)", true},
+	{"blocklist", "isBlackListed(address)", true},
+	{"blocklist", "isBlocklisted(address)", true},
+	{"blocklist", "blacklisted(address)", true},
+	{"blocklist", "isFrozen(address)", true},
+	{"pausable", "paused()", false},
+}
+
+// eip1967Implementation is the EIP-1967 implementation slot.
+var eip1967Implementation = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+func (p *Prober) checkHooks(ctx context.Context, r *Report, block *big.Int) {
+	seen := make(map[string]bool)
+	for _, h := range hooks {
+		if seen[h.check] {
+			continue
+		}
+		input := crypto.Keccak256([]byte(h.sig))[:4]
+		if h.arg {
+			input = append(input, make([]byte, 32)...)
+		}
+		out, err := callWithOverride(ctx, p.rpc, block, simCall{To: r.Token, Input: input}, r.Token, nil)
+		if err != nil || len(out) != 32 {
+			continue
+		}
+		seen[h.check] = true
+		r.add(h.check, Warn, "token implements %s; the issuer can block transfers to or from pools", h.sig)
+	}
+	var impl common.Hash
+	if err := p.rpc.CallContext(ctx, &impl, "eth_getStorageAt", r.Token, eip1967Implementation, blockArg(block)); err == nil && impl != (common.Hash{}) {
+		r.add("upgradeable", Warn, "token is an EIP-1967 proxy (implementation %s); its behaviour can change after the probe", common.BytesToAddress(impl[12:]).Hex())
+	}
+}
+
+func uint256(out []byte) (*big.Int, bool) {
+	if len(out) != 32 {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(out), true
+}
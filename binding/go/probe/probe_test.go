@@ -0,0 +1,229 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var token = common.HexToAddress("0x70")
+
+// fakeToken answers the probe's RPC calls for an in-memory ERC-20.
+type fakeToken struct {
+	feeBps   int64 // charged on every transfer and burned
+	rebase   bool  // doubles balances when time moves on
+	noReturn bool  // transfer and transferFrom return nothing
+	pausable bool
+	layout   int // index into balanceSlots
+}
+
+type tokenState struct {
+	bal    map[common.Address]*big.Int
+	allow  map[[2]common.Address]*big.Int
+	supply *big.Int
+}
+
+func (f *fakeToken) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(100), Time: 1000}, nil
+}
+
+func (f *fakeToken) CallContext(_ context.Context, result any, method string, args ...any) error {
+	var out any
+	switch method {
+	case "eth_simulateV1":
+		out = f.simulate(args[0].(simRequest))
+	case "eth_call":
+		call := args[0].(map[string]any)
+		input := []byte(call["input"].(hexutil.Bytes))
+		m, err := erc20ABI.MethodById(input)
+		if err != nil {
+			if f.pausable && len(input) == 4 {
+				out = "0x" + common.Bytes2Hex(common.Hash{}.Bytes())
+				break
+			}
+			return errors.New("execution reverted")
+		}
+		vals, _ := m.Inputs.Unpack(input[4:])
+		acct := vals[0].(common.Address)
+		bal := new(big.Int)
+		for slot, v := range args[2].(map[common.Address]accountOverride)[token].StateDiff {
+			if f.layout >= 0 && slot == balanceSlots(acct)[f.layout] {
+				bal = v.Big()
+			}
+		}
+		out = "0x" + common.Bytes2Hex(common.BigToHash(bal).Bytes())
+	case "eth_getStorageAt":
+		out = common.Hash{}
+	}
+	raw, _ := json.Marshal(out)
+	return json.Unmarshal(raw, result)
+}
+
+func (f *fakeToken) simulate(req simRequest) []simResult {
+	st := &tokenState{bal: make(map[common.Address]*big.Int), allow: make(map[[2]common.Address]*big.Int), supply: big.NewInt(1e18)}
+	holder := common.HexToAddress("0x401d")
+	st.bal[holder] = new(big.Int).Set(st.supply)
+	var out []simResult
+	for _, b := range req.BlockStateCalls {
+		for slot, v := range b.StateOverrides[token].StateDiff {
+			for _, acct := range []common.Address{scratchA, scratchB, scratchC} {
+				if f.layout >= 0 && balanceSlots(acct)[f.layout] == slot {
+					st.bal[acct] = v.Big()
+				}
+			}
+		}
+		if b.BlockOverrides != nil && f.rebase {
+			for k, v := range st.bal {
+				st.bal[k] = new(big.Int).Mul(v, big.NewInt(2))
+			}
+			st.supply.Mul(st.supply, big.NewInt(2))
+		}
+		var res simResult
+		for _, c := range b.Calls {
+			res.Calls = append(res.Calls, f.exec(st, c))
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func (f *fakeToken) exec(st *tokenState, c simCall) callResult {
+	m, _ := erc20ABI.MethodById(c.Input)
+	vals, _ := m.Inputs.Unpack(c.Input[4:])
+	get := func(a common.Address) *big.Int {
+		if v, ok := st.bal[a]; ok {
+			return v
+		}
+		return new(big.Int)
+	}
+	move := func(from, to common.Address, amt *big.Int) bool {
+		if get(from).Cmp(amt) < 0 {
+			return false
+		}
+		fee := new(big.Int).Div(new(big.Int).Mul(amt, big.NewInt(f.feeBps)), big.NewInt(10000))
+		st.bal[from] = new(big.Int).Sub(get(from), amt)
+		st.bal[to] = new(big.Int).Add(get(to), new(big.Int).Sub(amt, fee))
+		st.supply.Sub(st.supply, fee)
+		return true
+	}
+	ok := callResult{Status: 1}
+	ret, _ := m.Outputs.Pack(true)
+	if !f.noReturn {
+		ok.ReturnData = ret
+	}
+	switch m.Name {
+	case "decimals":
+		ret, _ := m.Outputs.Pack(uint8(18))
+		return callResult{Status: 1, ReturnData: ret}
+	case "symbol", "name":
+		ret, _ := m.Outputs.Pack("TKN")
+		return callResult{Status: 1, ReturnData: ret}
+	case "totalSupply":
+		ret, _ := m.Outputs.Pack(new(big.Int).Set(st.supply))
+		return callResult{Status: 1, ReturnData: ret}
+	case "balanceOf":
+		ret, _ := m.Outputs.Pack(new(big.Int).Set(get(vals[0].(common.Address))))
+		return callResult{Status: 1, ReturnData: ret}
+	case "approve":
+		st.allow[[2]common.Address{c.From, vals[0].(common.Address)}] = vals[1].(*big.Int)
+		ret, _ := m.Outputs.Pack(true)
+		return callResult{Status: 1, ReturnData: ret}
+	case "transfer":
+		if move(c.From, vals[0].(common.Address), vals[1].(*big.Int)) {
+			return ok
+		}
+	case "transferFrom":
+		key := [2]common.Address{vals[0].(common.Address), c.From}
+		amt := vals[2].(*big.Int)
+		if a := st.allow[key]; a != nil && a.Cmp(amt) >= 0 && move(key[0], vals[1].(common.Address), amt) {
+			st.allow[key] = new(big.Int).Sub(a, amt)
+			return ok
+		}
+	}
+	return callResult{Error: &callError{Message: "execution reverted"}}
+}
+
+func probe(t *testing.T, f *fakeToken, opts Options) *Report {
+	t.Helper()
+	r, err := (&Prober{rpc: f, headers: f}).Probe(context.Background(), token, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func checks(r *Report) map[string]Severity {
+	out := make(map[string]Severity)
+	for _, is := range r.Issues {
+		out[is.Check] = is.Severity
+	}
+	return out
+}
+
+func TestPlainTokenIsCompatible(t *testing.T) {
+	for _, layout := range []int{0, 1, 2, 40} {
+		r := probe(t, &fakeToken{layout: layout}, Options{})
+		if r.Verdict != Compatible || r.Symbol != "TKN" || *r.Decimals != 18 {
+			t.Errorf("layout %d: %+v", layout, r)
+		}
+		if err := Require(r, false); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestHolderFunding(t *testing.T) {
+	r := probe(t, &fakeToken{}, Options{Holder: common.HexToAddress("0x401d"), Amount: big.NewInt(1000)})
+	if r.Verdict != Compatible {
+		t.Errorf("holder: %+v", r.Issues)
+	}
+	r = probe(t, &fakeToken{}, Options{Holder: common.HexToAddress("0xdead"), Amount: big.NewInt(1000)})
+	if checks(r)["fund"] != Fail {
+		t.Errorf("empty holder: %+v", r.Issues)
+	}
+}
+
+func TestFeeOnTransfer(t *testing.T) {
+	r := probe(t, &fakeToken{feeBps: 100}, Options{})
+	got := checks(r)
+	if r.Verdict != Incompatible || got["transfer-fee"] != Fail || got["transferFrom-fee"] != Fail || got["supply"] != Fail {
+		t.Errorf("fee token: %v", got)
+	}
+	if err := Require(r, true); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("require: %v", err)
+	}
+}
+
+func TestRebasing(t *testing.T) {
+	r := probe(t, &fakeToken{rebase: true}, Options{})
+	if r.Verdict != Incompatible || checks(r)["drift"] != Fail {
+		t.Errorf("rebasing token: %+v", r.Issues)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	r := probe(t, &fakeToken{noReturn: true, pausable: true}, Options{})
+	got := checks(r)
+	if r.Verdict != Warnings || got["return-value"] != Warn || got["pausable"] != Warn {
+		t.Errorf("warnings: %+v", r.Issues)
+	}
+	if err := Require(r, false); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("require without allowWarnings: %v", err)
+	}
+	if err := Require(r, true); err != nil {
+		t.Errorf("require with allowWarnings: %v", err)
+	}
+}
+
+func TestNoBalanceSlot(t *testing.T) {
+	_, err := (&Prober{rpc: &fakeToken{layout: -1}, headers: &fakeToken{}}).Probe(context.Background(), token, Options{})
+	if !errors.Is(err, ErrNoBalanceSlot) {
+		t.Errorf("err %v", err)
+	}
+}
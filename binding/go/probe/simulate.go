@@ -0,0 +1,134 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrSimulateUnsupported is returned when the node lacks eth_simulateV1.
+// Point -rpc at a local fork that has it, such as anvil --fork-url.
+var ErrSimulateUnsupported = errors.New("probe: node does not support eth_simulateV1")
+
+// rpcCaller is the raw JSON-RPC access the probe needs. *rpc.Client
+// satisfies it.
+type rpcCaller interface {
+	CallContext(ctx context.Context, result any, method string, args ...any) error
+}
+
+type simCall struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Input hexutil.Bytes  `json:"input"`
+}
+
+type blockOverrides struct {
+	Time *hexutil.Uint64 `json:"time,omitempty"`
+}
+
+type accountOverride struct {
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+type simBlock struct {
+	BlockOverrides *blockOverrides                    `json:"blockOverrides,omitempty"`
+	StateOverrides map[common.Address]accountOverride `json:"stateOverrides,omitempty"`
+	Calls          []simCall                          `json:"calls"`
+}
+
+type simRequest struct {
+	BlockStateCalls []simBlock `json:"blockStateCalls"`
+	Validation      bool       `json:"validation"`
+}
+
+type callError struct {
+	Message string `json:"message"`
+}
+
+type callResult struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Status     hexutil.Uint64 `json:"status"`
+	Error      *callError     `json:"error,omitempty"`
+}
+
+func (r *callResult) ok() bool {
+	return r.Status == 1 && r.Error == nil
+}
+
+func (r *callResult) failure() string {
+	if r.Error != nil && r.Error.Message != "" {
+		return r.Error.Message
+	}
+	return "reverted"
+}
+
+type simResult struct {
+	Calls []callResult `json:"calls"`
+}
+
+// simulate runs blocks on top of block, each seeing the state the previous
+// calls left, and returns one result per call.
+func simulate(ctx context.Context, c rpcCaller, block *big.Int, blocks []simBlock) ([][]callResult, error) {
+	var res []simResult
+	err := c.CallContext(ctx, &res, "eth_simulateV1", simRequest{BlockStateCalls: blocks}, blockArg(block))
+	if err != nil {
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 || strings.Contains(err.Error(), "does not exist") {
+			return nil, fmt.Errorf("%w: %v", ErrSimulateUnsupported, err)
+		}
+		return nil, fmt.Errorf("probe: eth_simulateV1: %w", err)
+	}
+	if len(res) != len(blocks) {
+		return nil, fmt.Errorf("probe: eth_simulateV1 returned %d blocks, want %d", len(res), len(blocks))
+	}
+	out := make([][]callResult, len(res))
+	for i, b := range res {
+		if len(b.Calls) != len(blocks[i].Calls) {
+			return nil, fmt.Errorf("probe: eth_simulateV1 block %d returned %d calls, want %d", i, len(b.Calls), len(blocks[i].Calls))
+		}
+		out[i] = b.Calls
+	}
+	return out, nil
+}
+
+// callWithOverride runs one eth_call with token storage overridden.
+func callWithOverride(ctx context.Context, c rpcCaller, block *big.Int, call simCall, token common.Address, diff map[common.Hash]common.Hash) ([]byte, error) {
+	var out hexutil.Bytes
+	args := map[string]any{"from": call.From, "to": call.To, "input": call.Input}
+	overrides := map[common.Address]accountOverride{token: {StateDiff: diff}}
+	if err := c.CallContext(ctx, &out, "eth_call", args, blockArg(block), overrides); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func blockArg(block *big.Int) string {
+	if block == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(block)
+}
+
+// ozERC20Storage is the ERC-7201 namespace of OpenZeppelin v5 upgradeable
+// ERC20, whose first field is the balances mapping.
+var ozERC20Storage = common.HexToHash("0x52c63247e1f47db19d5ce0460030c497f067ca4cebf71ba98eeadabe20bace00")
+
+// balanceSlots returns candidate storage slots of acct's balance: Solidity
+// mappings at slots 0-19 (keccak(key . slot)), Vyper HashMaps at slots 0-19
+// (keccak(slot . key)) and the OpenZeppelin v5 namespaced layout.
+func balanceSlots(acct common.Address) []common.Hash {
+	key := common.BytesToHash(acct[:])
+	out := []common.Hash{crypto.Keccak256Hash(key[:], ozERC20Storage[:])}
+	for i := int64(0); i < 20; i++ {
+		slot := common.BigToHash(big.NewInt(i))
+		out = append(out, crypto.Keccak256Hash(key[:], slot[:]), crypto.Keccak256Hash(slot[:], key[:]))
+	}
+	return out
+}
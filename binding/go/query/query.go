@@ -0,0 +1,302 @@
+// Package query reads pool state and user positions at a given block.
+//
+// It is the read model shared by the HTTP and gRPC services: every method
+// takes CallOpts so callers can pin all reads of one response to the same
+// block.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	// ErrPoolNotFound is returned for pool IDs the factory has not assigned.
+	ErrPoolNotFound = errors.New("query: pool not found")
+	// ErrNoRouter is returned by router-backed reads while the factory has
+	// no router set.
+	ErrNoRouter = errors.New("query: factory has no router")
+)
+
+// Pool is the state of one pool.
+type Pool struct {
+	ID               *big.Int
+	Address          common.Address
+	Name             string
+	DepositToken     common.Address
+	CreatedAt        *big.Int
+	Status           uint8
+	Paused           bool
+	TotalDeposited   *big.Int
+	MinDepositAmount *big.Int
+	RewardTokens     []common.Address
+	RemovedTokens    []common.Address
+}
+
+// RewardToken is the accounting of one reward token in a pool. The
+// accumulator fields are nil for removed tokens, which the pool no longer
+// reports through getRewardToken.
+type RewardToken struct {
+	Token                common.Address
+	Removed              bool
+	RewardPerTokenStored *big.Int
+	LastBalance          *big.Int
+	DistributedAmount    *big.Int
+	ReclaimableAmount    *big.Int
+}
+
+// Reward is an amount of one reward token claimable by a user.
+type Reward struct {
+	Token   common.Address
+	Amount  *big.Int
+	Removed bool
+}
+
+// Position is a user's deposit and claimable rewards in one pool.
+type Position struct {
+	PoolID       *big.Int
+	Pool         common.Address
+	DepositToken common.Address
+	Deposited    *big.Int
+	// Pending lists every active reward token and the removed tokens with
+	// a non-zero claimable amount.
+	Pending []Reward
+}
+
+// Empty reports whether the user has neither a deposit nor anything to claim.
+func (p *Position) Empty() bool {
+	if p.Deposited.Sign() > 0 {
+		return false
+	}
+	for _, r := range p.Pending {
+		if r.Amount.Sign() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reader reads protocol state from one network.
+type Reader struct {
+	net *config.Network
+}
+
+// New returns a Reader for net.
+func New(net *config.Network) *Reader {
+	return &Reader{net: net}
+}
+
+// PoolIDs lists all pool IDs, or only those with Active status.
+func (r *Reader) PoolIDs(opts *bind.CallOpts, activeOnly bool) ([]*big.Int, error) {
+	if activeOnly {
+		return r.net.Factory.GetActivePoolIds(opts)
+	}
+	return r.net.Factory.GetAllPoolIds(opts)
+}
+
+// Pools reads every pool, or only those with Active status.
+func (r *Reader) Pools(opts *bind.CallOpts, activeOnly bool) ([]*Pool, error) {
+	ids, err := r.PoolIDs(opts, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("query: list pools: %w", err)
+	}
+	out := make([]*Pool, 0, len(ids))
+	for _, id := range ids {
+		p, err := r.Pool(opts, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Pool reads the pool with the given ID.
+func (r *Reader) Pool(opts *bind.CallOpts, id *big.Int) (*Pool, error) {
+	if err := r.CheckPool(opts, id); err != nil {
+		return nil, err
+	}
+	info, err := r.net.Factory.GetPoolInfo(opts, id)
+	if err != nil {
+		return nil, fmt.Errorf("query: pool %s: %w", id, err)
+	}
+	pool, err := r.net.Pool(info.Pool)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pool{
+		ID:           id,
+		Address:      info.Pool,
+		Name:         info.Name,
+		DepositToken: info.DepositToken,
+		CreatedAt:    info.CreatedAt,
+	}
+	if err := r.fillPool(opts, pool, p); err != nil {
+		return nil, fmt.Errorf("query: pool %s: %w", id, err)
+	}
+	return p, nil
+}
+
+func (r *Reader) fillPool(opts *bind.CallOpts, pool *binding.CrossGameRewardPool, p *Pool) error {
+	var err error
+	if p.Status, err = pool.PoolStatus(opts); err != nil {
+		return err
+	}
+	if p.Paused, err = pool.Paused(opts); err != nil {
+		return err
+	}
+	if p.TotalDeposited, err = pool.TotalDeposited(opts); err != nil {
+		return err
+	}
+	if p.MinDepositAmount, err = pool.MinDepositAmount(opts); err != nil {
+		return err
+	}
+	if p.RewardTokens, err = pool.GetRewardTokens(opts); err != nil {
+		return err
+	}
+	p.RemovedTokens, err = pool.GetRemovedRewardTokens(opts)
+	return err
+}
+
+// RewardTokens reads the active and removed reward tokens of a pool.
+func (r *Reader) RewardTokens(opts *bind.CallOpts, id *big.Int) ([]RewardToken, error) {
+	pool, err := r.pool(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	active, err := pool.GetRewardTokens(opts)
+	if err != nil {
+		return nil, fmt.Errorf("query: pool %s: %w", id, err)
+	}
+	removed, err := pool.GetRemovedRewardTokens(opts)
+	if err != nil {
+		return nil, fmt.Errorf("query: pool %s: %w", id, err)
+	}
+	out := make([]RewardToken, 0, len(active)+len(removed))
+	for _, token := range active {
+		rt, err := pool.GetRewardToken(opts, token)
+		if err != nil {
+			return nil, fmt.Errorf("query: pool %s token %s: %w", id, token, err)
+		}
+		out = append(out, RewardToken{
+			Token:                token,
+			RewardPerTokenStored: rt.RewardPerTokenStored,
+			LastBalance:          rt.LastBalance,
+			DistributedAmount:    rt.DistributedAmount,
+			ReclaimableAmount:    rt.ReclaimableAmount,
+		})
+	}
+	for _, token := range removed {
+		reclaimable, err := pool.GetReclaimableAmount(opts, token)
+		if err != nil {
+			return nil, fmt.Errorf("query: pool %s token %s: %w", id, token, err)
+		}
+		out = append(out, RewardToken{Token: token, Removed: true, ReclaimableAmount: reclaimable})
+	}
+	return out, nil
+}
+
+// Position reads a user's position in one pool.
+func (r *Reader) Position(opts *bind.CallOpts, id *big.Int, user common.Address) (*Position, error) {
+	if err := r.CheckPool(opts, id); err != nil {
+		return nil, err
+	}
+	addr, pool, err := r.net.PoolByID(opts, id)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := position(opts, id, addr, pool, user)
+	if err != nil {
+		return nil, fmt.Errorf("query: pool %s user %s: %w", id, user, err)
+	}
+	return pos, nil
+}
+
+// Positions reads the user's non-empty positions across all pools.
+func (r *Reader) Positions(opts *bind.CallOpts, user common.Address) ([]*Position, error) {
+	pools, err := r.net.Pools(opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Position
+	for _, p := range pools {
+		pos, err := position(opts, p.ID, p.Address, p.Pool, user)
+		if err != nil {
+			return nil, fmt.Errorf("query: pool %s user %s: %w", p.ID, user, err)
+		}
+		if !pos.Empty() {
+			out = append(out, pos)
+		}
+	}
+	return out, nil
+}
+
+func position(opts *bind.CallOpts, id *big.Int, addr common.Address, pool *binding.CrossGameRewardPool, user common.Address) (*Position, error) {
+	pos := &Position{PoolID: id, Pool: addr}
+	var err error
+	if pos.DepositToken, err = pool.DepositToken(opts); err != nil {
+		return nil, err
+	}
+	if pos.Deposited, err = pool.Balances(opts, user); err != nil {
+		return nil, err
+	}
+	active, err := pool.PendingRewards(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range active.Tokens {
+		pos.Pending = append(pos.Pending, Reward{Token: token, Amount: active.Rewards[i]})
+	}
+	removed, err := pool.GetRemovedTokenRewards(opts, user)
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range removed.Tokens {
+		if removed.Rewards[i].Sign() > 0 {
+			pos.Pending = append(pos.Pending, Reward{Token: token, Amount: removed.Rewards[i], Removed: true})
+		}
+	}
+	return pos, nil
+}
+
+// TotalDeposited returns the amount of token deposited across all pools, as
+// reported by the router. The router's NATIVE_TOKEN address counts WCROSS
+// pools.
+func (r *Reader) TotalDeposited(opts *bind.CallOpts, token common.Address) (*big.Int, error) {
+	if r.net.Router == nil {
+		return nil, ErrNoRouter
+	}
+	total, err := r.net.Router.GetTotalDeposited(opts, token)
+	if err != nil {
+		return nil, fmt.Errorf("query: total deposited %s: %w", token, err)
+	}
+	return total, nil
+}
+
+func (r *Reader) pool(opts *bind.CallOpts, id *big.Int) (*binding.CrossGameRewardPool, error) {
+	if err := r.CheckPool(opts, id); err != nil {
+		return nil, err
+	}
+	_, pool, err := r.net.PoolByID(opts, id)
+	return pool, err
+}
+
+// CheckPool maps unassigned IDs to ErrPoolNotFound instead of the revert
+// the factory would return. IDs are assigned from 1 and never reused.
+func (r *Reader) CheckPool(opts *bind.CallOpts, id *big.Int) error {
+	next, err := r.net.Factory.NextPoolId(opts)
+	if err != nil {
+		return fmt.Errorf("query: next pool id: %w", err)
+	}
+	if id.Sign() <= 0 || id.Cmp(next) >= 0 {
+		return fmt.Errorf("%w: %s", ErrPoolNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+// Package removal wraps removeRewardToken with a before/after impact check.
+//
+// Removing a reward token freezes the pool's distributable balance as
+// distributedAmount; from then on users can only collect their share through
+// the removed-token path. The workflow snapshots every known depositor's
+// pendingReward(user, token) before removal, then compares it with
+// getRemovedTokenRewards(user) afterwards and reports differences and
+// unattributed dust.
+package removal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/erc20"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+var (
+	// ErrNotRewardToken is returned when snapshotting a token the pool does not distribute.
+	ErrNotRewardToken = errors.New("removal: token is not an active reward token")
+	// ErrNotRemoved is returned when verifying before the token was removed.
+	ErrNotRemoved = errors.New("removal: token has not been removed")
+)
+
+// Holding is one depositor's position at snapshot time.
+type Holding struct {
+	Account common.Address `json:"account"`
+	Balance *big.Int       `json:"balance"`
+	Pending *big.Int       `json:"pending"`
+}
+
+// Snapshot records pending rewards of one token before its removal.
+type Snapshot struct {
+	PoolID               *big.Int       `json:"poolId"`
+	Pool                 common.Address `json:"pool"`
+	Token                common.Address `json:"token"`
+	Block                uint64         `json:"block"`
+	TotalDeposited       *big.Int       `json:"totalDeposited"`
+	RewardPerTokenStored *big.Int       `json:"rewardPerTokenStored"`
+	ReclaimableAmount    *big.Int       `json:"reclaimableAmount"`
+	Holdings             []Holding      `json:"holdings"`
+}
+
+// TakeSnapshot reads pendingReward for every depositor the store knows of
+// at block. Accounts with neither balance nor pending reward are omitted.
+func TakeSnapshot(ctx context.Context, net *config.Network, store *indexer.Store, poolID *big.Int, token common.Address, block uint64) (*Snapshot, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)}
+	addr, pool, err := net.PoolByID(opts, poolID)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := pool.GetRewardToken(opts, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: pool %s token %s: %v", ErrNotRewardToken, poolID, token, err)
+	}
+	total, err := pool.TotalDeposited(opts)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{
+		PoolID:               poolID,
+		Pool:                 addr,
+		Token:                token,
+		Block:                block,
+		TotalDeposited:       total,
+		RewardPerTokenStored: rt.RewardPerTokenStored,
+		ReclaimableAmount:    rt.ReclaimableAmount,
+	}
+	for _, acct := range store.Depositors(addr) {
+		bal, err := pool.Balances(opts, acct)
+		if err != nil {
+			return nil, fmt.Errorf("removal: balance of %s: %w", acct, err)
+		}
+		pending, err := pool.PendingReward(opts, acct, token)
+		if err != nil {
+			return nil, fmt.Errorf("removal: pending reward of %s: %w", acct, err)
+		}
+		if bal.Sign() == 0 && pending.Sign() == 0 {
+			continue
+		}
+		snap.Holdings = append(snap.Holdings, Holding{Account: acct, Balance: bal, Pending: pending})
+	}
+	return snap, nil
+}
+
+// Status classifies one depositor in the report.
+type Status string
+
+// Report statuses.
+const (
+	StatusOK        Status = "ok"
+	StatusDust      Status = "dust"      // differs by no more than the tolerance
+	StatusIncreased Status = "increased" // claimable grew between snapshot and check
+	StatusDecreased Status = "decreased" // claimable shrank, e.g. the user claimed in between
+)
+
+// Line is one depositor's before/after comparison.
+type Line struct {
+	Account common.Address `json:"account"`
+	Before  *big.Int       `json:"before"`
+	After   *big.Int       `json:"after"`
+	Diff    *big.Int       `json:"diff"`
+	Status  Status         `json:"status"`
+}
+
+// Report is the outcome of verifying a removal.
+type Report struct {
+	PoolID         *big.Int       `json:"poolId"`
+	Pool           common.Address `json:"pool"`
+	Token          common.Address `json:"token"`
+	SnapshotBlock  uint64         `json:"snapshotBlock"`
+	VerifiedBlock  uint64         `json:"verifiedBlock"`
+	RemovalTx      common.Hash    `json:"removalTx,omitempty"`
+	PoolBalance    *big.Int       `json:"poolBalance"`
+	Reclaimable    *big.Int       `json:"reclaimable"`
+	Distributed    *big.Int       `json:"distributed"` // poolBalance - reclaimable
+	TotalBefore    *big.Int       `json:"totalBefore"`
+	TotalAfter     *big.Int       `json:"totalAfter"`
+	Unattributed   *big.Int       `json:"unattributed"` // distributed - totalAfter
+	Lines          []Line         `json:"lines"`
+	Flagged        int            `json:"flagged"`
+	UnattributedOK bool           `json:"unattributedOk"`
+}
+
+// Verify compares snap with the removed-token claimables at block. Per-user
+// differences up to tolerance are reported as dust rather than flagged; the
+// same tolerance per holder bounds the acceptable unattributed remainder.
+func Verify(ctx context.Context, net *config.Network, snap *Snapshot, block uint64, tolerance *big.Int) (*Report, error) {
+	if tolerance == nil {
+		tolerance = new(big.Int)
+	}
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)}
+	pool, err := net.Pool(snap.Pool)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := pool.IsRemovedRewardToken(opts, snap.Token)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, fmt.Errorf("%w: pool %s token %s at block %d", ErrNotRemoved, snap.PoolID, snap.Token, block)
+	}
+	token, err := erc20.NewCaller(snap.Token, net.Client)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := token.BalanceOf(opts, snap.Pool)
+	if err != nil {
+		return nil, fmt.Errorf("removal: pool token balance: %w", err)
+	}
+	reclaimable, err := pool.GetReclaimableAmount(opts, snap.Token)
+	if err != nil {
+		return nil, err
+	}
+	after := make(map[common.Address]*big.Int, len(snap.Holdings))
+	for _, h := range snap.Holdings {
+		if after[h.Account], err = removedReward(opts, pool, h.Account, snap.Token); err != nil {
+			return nil, err
+		}
+	}
+	return newReport(snap, block, balance, reclaimable, after, tolerance), nil
+}
+
+func newReport(snap *Snapshot, block uint64, balance, reclaimable *big.Int, after map[common.Address]*big.Int, tolerance *big.Int) *Report {
+	r := &Report{
+		PoolID:        snap.PoolID,
+		Pool:          snap.Pool,
+		Token:         snap.Token,
+		SnapshotBlock: snap.Block,
+		VerifiedBlock: block,
+		PoolBalance:   balance,
+		Reclaimable:   reclaimable,
+		Distributed:   new(big.Int).Sub(balance, reclaimable),
+		TotalBefore:   new(big.Int),
+		TotalAfter:    new(big.Int),
+	}
+	for _, h := range snap.Holdings {
+		a := after[h.Account]
+		line := Line{Account: h.Account, Before: h.Pending, After: a, Diff: new(big.Int).Sub(a, h.Pending)}
+		switch {
+		case line.Diff.Sign() == 0:
+			line.Status = StatusOK
+		case new(big.Int).Abs(line.Diff).Cmp(tolerance) <= 0:
+			line.Status = StatusDust
+		case line.Diff.Sign() > 0:
+			line.Status = StatusIncreased
+		default:
+			line.Status = StatusDecreased
+		}
+		if line.Status != StatusOK && line.Status != StatusDust {
+			r.Flagged++
+		}
+		r.TotalBefore.Add(r.TotalBefore, h.Pending)
+		r.TotalAfter.Add(r.TotalAfter, a)
+		r.Lines = append(r.Lines, line)
+	}
+	r.Unattributed = new(big.Int).Sub(r.Distributed, r.TotalAfter)
+	limit := new(big.Int).Mul(tolerance, big.NewInt(int64(len(snap.Holdings))))
+	r.UnattributedOK = r.Unattributed.Sign() >= 0 && r.Unattributed.Cmp(limit) <= 0
+	return r
+}
+
+type removedRewardsCaller interface {
+	GetRemovedTokenRewards(opts *bind.CallOpts, user common.Address) (struct {
+		Tokens  []common.Address
+		Rewards []*big.Int
+	}, error)
+}
+
+func removedReward(opts *bind.CallOpts, pool removedRewardsCaller, acct, token common.Address) (*big.Int, error) {
+	res, err := pool.GetRemovedTokenRewards(opts, acct)
+	if err != nil {
+		return nil, fmt.Errorf("removal: removed rewards of %s: %w", acct, err)
+	}
+	for i, t := range res.Tokens {
+		if t == token {
+			return res.Rewards[i], nil
+		}
+	}
+	return new(big.Int), nil
+}
+
+// Run snapshots, removes the token through the factory and verifies the
+// result. The comparison uses a second snapshot at the block before the
+// removal so activity between the first snapshot and the transaction does
+// not show up as a difference; if that state is unavailable the first
+// snapshot is used.
+func Run(ctx context.Context, net *config.Network, store *indexer.Store, tm *txmgr.Manager, poolID *big.Int, token common.Address, tolerance *big.Int) (*Report, error) {
+	head, err := net.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := TakeSnapshot(ctx, net, store, poolID, token, head)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return net.Factory.RemoveRewardToken(o, poolID, token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("removal: removeRewardToken: %w", err)
+	}
+	removedAt := receipt.BlockNumber.Uint64()
+	if exact, err := TakeSnapshot(ctx, net, store, poolID, token, removedAt-1); err == nil {
+		snap = exact
+	}
+	r, err := Verify(ctx, net, snap, removedAt, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	r.RemovalTx = receipt.TxHash
+	return r, nil
+}
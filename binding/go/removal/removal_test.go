@@ -0,0 +1,87 @@
+package removal
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	alice = common.HexToAddress("0xa11ce")
+	bob   = common.HexToAddress("0xb0b")
+	carol = common.HexToAddress("0xca201")
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		PoolID: big.NewInt(3),
+		Pool:   common.HexToAddress("0xb0"),
+		Token:  common.HexToAddress("0x70"),
+		Block:  99,
+		Holdings: []Holding{
+			{Account: alice, Balance: big.NewInt(10), Pending: big.NewInt(500)},
+			{Account: bob, Balance: big.NewInt(20), Pending: big.NewInt(1000)},
+			{Account: carol, Balance: big.NewInt(0), Pending: big.NewInt(7)},
+		},
+	}
+}
+
+func TestReportClassifiesDifferences(t *testing.T) {
+	after := map[common.Address]*big.Int{
+		alice: big.NewInt(500),
+		bob:   big.NewInt(999),
+		carol: big.NewInt(0),
+	}
+	r := newReport(testSnapshot(), 100, big.NewInt(1510), big.NewInt(10), after, big.NewInt(1))
+
+	want := []Status{StatusOK, StatusDust, StatusDecreased}
+	for i, l := range r.Lines {
+		if l.Status != want[i] {
+			t.Errorf("%s: status %s, want %s", l.Account, l.Status, want[i])
+		}
+	}
+	if r.Flagged != 1 {
+		t.Errorf("flagged %d, want 1", r.Flagged)
+	}
+	if r.Distributed.Int64() != 1500 || r.TotalBefore.Int64() != 1507 || r.TotalAfter.Int64() != 1499 {
+		t.Errorf("totals: distributed %s before %s after %s", r.Distributed, r.TotalBefore, r.TotalAfter)
+	}
+	if r.Unattributed.Int64() != 1 || !r.UnattributedOK {
+		t.Errorf("unattributed %s ok=%t, want 1 within tolerance", r.Unattributed, r.UnattributedOK)
+	}
+}
+
+func TestReportOverClaimableIsFlagged(t *testing.T) {
+	after := map[common.Address]*big.Int{alice: big.NewInt(600), bob: big.NewInt(1000), carol: big.NewInt(7)}
+	r := newReport(testSnapshot(), 100, big.NewInt(1510), big.NewInt(10), after, new(big.Int))
+	if r.Lines[0].Status != StatusIncreased || r.UnattributedOK {
+		t.Fatalf("claimables above distributed balance must be flagged: %+v unattributed %s", r.Lines[0], r.Unattributed)
+	}
+	if !strings.HasPrefix(r.Summary(), "attention") {
+		t.Fatalf("summary %q", r.Summary())
+	}
+}
+
+func TestReportWriters(t *testing.T) {
+	after := map[common.Address]*big.Int{alice: big.NewInt(500), bob: big.NewInt(1000), carol: big.NewInt(7)}
+	r := newReport(testSnapshot(), 100, big.NewInt(1507), big.NewInt(0), after, new(big.Int))
+
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| Flagged accounts | 0 |") || strings.Count(md.String(), "| ok |") != 3 {
+		t.Fatalf("unexpected markdown:\n%s", md.String())
+	}
+
+	var csv bytes.Buffer
+	if err := r.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 4 {
+		t.Fatalf("want header and 3 rows, got %d", len(lines))
+	}
+}
@@ -0,0 +1,97 @@
+package removal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WriteSnapshot saves snap as indented JSON.
+func WriteSnapshot(path string, snap *Snapshot) error {
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// ReadSnapshot loads a snapshot saved with WriteSnapshot.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(raw, snap); err != nil {
+		return nil, fmt.Errorf("removal: parse %s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// WriteCSV writes one row per depositor.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"account", "before", "after", "diff", "status"})
+	for _, l := range r.Lines {
+		cw.Write([]string{l.Account.Hex(), l.Before.String(), l.After.String(), l.Diff.String(), string(l.Status)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes a summary and per-depositor table suitable for
+// sharing with players.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("# Reward token removal: pool %s\n\n", r.PoolID)
+	ew.printf("| | |\n|---|---|\n")
+	ew.printf("| Pool | `%s` |\n", r.Pool.Hex())
+	ew.printf("| Token | `%s` |\n", r.Token.Hex())
+	if r.RemovalTx != (common.Hash{}) {
+		ew.printf("| Removal tx | `%s` |\n", r.RemovalTx.Hex())
+	}
+	ew.printf("| Snapshot block | %d |\n", r.SnapshotBlock)
+	ew.printf("| Verified block | %d |\n", r.VerifiedBlock)
+	ew.printf("| Claimable by players | %s |\n", r.Distributed)
+	ew.printf("| Sum before removal | %s |\n", r.TotalBefore)
+	ew.printf("| Sum after removal | %s |\n", r.TotalAfter)
+	ew.printf("| Unattributed | %s |\n", r.Unattributed)
+	ew.printf("| Reclaimable by operator | %s |\n", r.Reclaimable)
+	ew.printf("| Flagged accounts | %d |\n\n", r.Flagged)
+
+	ew.printf("| Account | Before | After | Diff | Status |\n|---|---:|---:|---:|---|\n")
+	for _, l := range r.Lines {
+		diff := l.Diff.String()
+		if l.Diff.Sign() > 0 {
+			diff = "+" + diff
+		}
+		ew.printf("| `%s` | %s | %s | %s | %s |\n", l.Account.Hex(), l.Before, l.After, diff, l.Status)
+	}
+	return ew.err
+}
+
+// Summary returns a one-line verdict.
+func (r *Report) Summary() string {
+	verdict := "ok"
+	if r.Flagged > 0 || !r.UnattributedOK {
+		verdict = "attention"
+	}
+	return verdict + ": " + strconv.Itoa(len(r.Lines)) + " accounts, " + strconv.Itoa(r.Flagged) +
+		" flagged, unattributed " + r.Unattributed.String()
+}
+
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...any) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, args...)
+	}
+}
@@ -0,0 +1,179 @@
+package schedule
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// ErrInvalidEntry is returned by Add for entries that cannot be scheduled.
+var ErrInvalidEntry = errors.New("schedule: invalid entry")
+
+// Entry is one planned status change. It targets either a time or a block.
+type Entry struct {
+	ID     string            `json:"id"`
+	PoolID uint64            `json:"poolId"`
+	Status config.PoolStatus `json:"status"`
+	// At is the earliest time the change may be mined.
+	At time.Time `json:"at,omitzero"`
+	// Block is the block the change should be mined in.
+	Block uint64 `json:"block,omitempty"`
+	// Done is set once the change is made or found already in place.
+	Done *Execution `json:"done,omitempty"`
+	// LastError is the most recent failed attempt; the entry is retried.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Execution records when a change took effect.
+type Execution struct {
+	// TxHash is zero when the pool already had the status.
+	TxHash common.Hash `json:"txHash,omitzero"`
+	Block  uint64      `json:"block"`
+	Time   time.Time   `json:"time"`
+	// From is the status PoolStatusChanged reported replacing.
+	From config.PoolStatus `json:"from"`
+	// Drift is how late the change took effect: the block time minus At
+	// for time targets, or the block minus Block for block targets.
+	Drift       time.Duration `json:"drift,omitempty"`
+	DriftBlocks int64         `json:"driftBlocks,omitempty"`
+}
+
+// due reports whether e should be sent now: its time has come, or the next
+// block is its target block.
+func (e *Entry) due(now time.Time, head uint64) bool {
+	if e.Done != nil {
+		return false
+	}
+	if e.Block != 0 {
+		return head+1 >= e.Block
+	}
+	return !now.Before(e.At)
+}
+
+// drift fills in the lateness of x relative to e's target.
+func (e *Entry) drift(x *Execution) {
+	if e.Block != 0 {
+		x.DriftBlocks = int64(x.Block) - int64(e.Block)
+		return
+	}
+	x.Drift = x.Time.Sub(e.At)
+}
+
+// Queue is the list of planned changes, kept in a file.
+type Queue struct {
+	Entries []*Entry `json:"entries"`
+}
+
+// LoadQueue reads the queue at path; a missing file is an empty queue.
+func LoadQueue(path string) (*Queue, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Queue{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	q := new(Queue)
+	if err := json.Unmarshal(raw, q); err != nil {
+		return nil, fmt.Errorf("schedule: parse %s: %w", path, err)
+	}
+	return q, nil
+}
+
+// Save writes the queue to path.
+func (q *Queue) Save(path string) error {
+	raw, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// Add appends e, numbering it one past the highest numeric ID in the queue
+// if it has no ID.
+func (q *Queue) Add(e *Entry) error {
+	switch {
+	case e.PoolID == 0:
+		return fmt.Errorf("%w: no pool", ErrInvalidEntry)
+	case !e.Status.Valid():
+		return fmt.Errorf("%w: status %s", ErrInvalidEntry, e.Status)
+	case e.At.IsZero() == (e.Block == 0):
+		return fmt.Errorf("%w: set exactly one of a time and a block", ErrInvalidEntry)
+	}
+	if e.ID == "" {
+		e.ID = strconv.Itoa(q.lastNumber() + 1)
+	}
+	if q.Find(e.ID) != nil {
+		return fmt.Errorf("%w: ID %q is taken", ErrInvalidEntry, e.ID)
+	}
+	q.Entries = append(q.Entries, e)
+	return nil
+}
+
+// lastNumber returns the highest numeric entry ID, or zero.
+func (q *Queue) lastNumber() int {
+	var n int
+	for _, e := range q.Entries {
+		if v, err := strconv.Atoi(e.ID); err == nil {
+			n = max(n, v)
+		}
+	}
+	return n
+}
+
+// Find returns the entry with the given ID, or nil.
+func (q *Queue) Find(id string) *Entry {
+	i := slices.IndexFunc(q.Entries, func(e *Entry) bool { return e.ID == id })
+	if i < 0 {
+		return nil
+	}
+	return q.Entries[i]
+}
+
+// Remove drops the pending entry with the given ID.
+func (q *Queue) Remove(id string) error {
+	e := q.Find(id)
+	switch {
+	case e == nil:
+		return fmt.Errorf("schedule: no entry %q", id)
+	case e.Done != nil:
+		return fmt.Errorf("schedule: entry %q has already run", id)
+	}
+	q.Entries = slices.DeleteFunc(q.Entries, func(x *Entry) bool { return x == e })
+	return nil
+}
+
+// Due returns the entries to send at now with the chain at head, oldest
+// target first. Time and block targets are not comparable, so entries of
+// one kind keep their order relative to each other and time targets come
+// first.
+func (q *Queue) Due(now time.Time, head uint64) []*Entry {
+	var out []*Entry
+	for _, e := range q.Entries {
+		if e.due(now, head) {
+			out = append(out, e)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b *Entry) int {
+		switch {
+		case a.Block == 0 && b.Block == 0:
+			return a.At.Compare(b.At)
+		case a.Block == 0:
+			return -1
+		case b.Block == 0:
+			return 1
+		}
+		return cmp.Compare(a.Block, b.Block)
+	})
+	return out
+}
@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+var t0 = time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAdd(t *testing.T) {
+	q := &Queue{}
+	for name, e := range map[string]*Entry{
+		"no pool":     {Status: config.PoolInactive, At: t0},
+		"no target":   {PoolID: 1, Status: config.PoolInactive},
+		"two targets": {PoolID: 1, Status: config.PoolInactive, At: t0, Block: 10},
+		"bad status":  {PoolID: 1, Status: 9, At: t0},
+	} {
+		if err := q.Add(e); !errors.Is(err, ErrInvalidEntry) {
+			t.Errorf("%s: err %v", name, err)
+		}
+	}
+	if err := q.Add(&Entry{PoolID: 1, Status: config.PoolInactive, At: t0}); err != nil || q.Entries[0].ID != "1" {
+		t.Fatalf("add: %v, %+v", err, q.Entries)
+	}
+	if err := q.Add(&Entry{ID: "1", PoolID: 2, Status: config.PoolActive, Block: 5}); !errors.Is(err, ErrInvalidEntry) {
+		t.Errorf("taken ID: err %v", err)
+	}
+
+	// An add after a cancel must not reuse the ID of a remaining entry.
+	q.Add(&Entry{PoolID: 2, Status: config.PoolActive, Block: 5})
+	if err := q.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(&Entry{PoolID: 3, Status: config.PoolPaused, Block: 6}); err != nil || q.Entries[1].ID != "3" {
+		t.Fatalf("add after cancel: %v, %+v", err, q.Entries)
+	}
+}
+
+func TestDue(t *testing.T) {
+	q := &Queue{Entries: []*Entry{
+		{ID: "late-block", PoolID: 1, Block: 101},
+		{ID: "close", PoolID: 1, Status: config.PoolInactive, At: t0.Add(time.Hour)},
+		{ID: "open", PoolID: 1, At: t0},
+		{ID: "future-block", PoolID: 2, Block: 102},
+		{ID: "done", PoolID: 2, At: t0, Done: &Execution{}},
+	}}
+	ids := func(now time.Time, head uint64) (out []string) {
+		for _, e := range q.Due(now, head) {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	got := ids(t0.Add(time.Hour), 100)
+	want := []string{"open", "close", "late-block"}
+	if len(got) != len(want) {
+		t.Fatalf("due %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("due %v, want %v", got, want)
+		}
+	}
+	if got := ids(t0.Add(-time.Second), 99); len(got) != 0 {
+		t.Errorf("early: %v", got)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	e := &Entry{At: t0}
+	x := &Execution{Time: t0.Add(3 * time.Second)}
+	e.drift(x)
+	if x.Drift != 3*time.Second {
+		t.Errorf("drift %s", x.Drift)
+	}
+	e = &Entry{Block: 100}
+	x = &Execution{Block: 99}
+	e.drift(x)
+	if x.DriftBlocks != -1 {
+		t.Errorf("block drift %d", x.DriftBlocks)
+	}
+}
+
+func TestQueueFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := LoadQueue(path)
+	if err != nil || len(q.Entries) != 0 {
+		t.Fatalf("missing queue: %+v, %v", q, err)
+	}
+	q.Add(&Entry{ID: "close", PoolID: 3, Status: config.PoolInactive, At: t0})
+	q.Add(&Entry{ID: "reopen", PoolID: 3, Status: config.PoolActive, At: t0.Add(time.Hour)})
+	q.Entries[0].Done = &Execution{Block: 7, Time: t0, From: config.PoolActive}
+	if err := q.Remove("close"); err == nil {
+		t.Error("removed an entry that has run")
+	}
+	if err := q.Remove("reopen"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadQueue(path)
+	if err != nil || len(back.Entries) != 1 || back.Entries[0].Status != config.PoolInactive || back.Entries[0].Done.Block != 7 {
+		t.Fatalf("reloaded %+v, %v", back, err)
+	}
+}
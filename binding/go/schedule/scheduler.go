@@ -0,0 +1,305 @@
+// Package schedule sends planned pool status changes at set times or
+// blocks.
+//
+// The queue lives in a file, so the scheduler can be restarted, and the
+// queue can be edited while the scheduler runs. A change is sent once its
+// time has come, or one block before its target block, as the factory's
+// MANAGER_ROLE. It is verified by the pool's PoolStatusChanged event, and
+// its entry records when it took effect and how far that was from the
+// plan. Changes due together are sent together. A pool already in the
+// planned status is recorded without sending, with the PoolStatusChanged
+// event that set it since the entry came due if there is one.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// ErrNotManager is returned when the sending account lacks MANAGER_ROLE.
+// It is the error config.Network.RequireRole wraps.
+var ErrNotManager = config.ErrMissingRole
+
+// Scheduler defaults.
+const (
+	// DefaultInterval is the time between checks in Run.
+	DefaultInterval = time.Second
+	// DefaultChunkSize is the number of blocks requested per eth_getLogs
+	// call.
+	DefaultChunkSize = 5000
+)
+
+// Scheduler runs the queue in one file.
+type Scheduler struct {
+	net  *config.Network
+	tm   *txmgr.Manager
+	path string
+	now  func() time.Time
+
+	// Interval is the time between checks in Run. Zero means
+	// DefaultInterval.
+	Interval time.Duration
+	// OnDone is called for each entry that completes, if set.
+	OnDone func(e *Entry)
+	// OnError receives failed attempts in Run; nil drops them.
+	OnError func(error)
+}
+
+// New returns a Scheduler for the queue file at path, sending as tm's
+// account, which must hold MANAGER_ROLE.
+func New(ctx context.Context, net *config.Network, tm *txmgr.Manager, path string) (*Scheduler, error) {
+	if err := net.RequireRole(tm.CallOpts(ctx), config.RoleManager, tm.From()); err != nil {
+		return nil, err
+	}
+	return &Scheduler{net: net, tm: tm, path: path, now: time.Now}, nil
+}
+
+// Run checks the queue every Interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick reads the queue and runs every due entry. The changes are signed in
+// queue order with consecutive nonces, broadcast together and awaited
+// together, so entries due at the same time take effect in the same few
+// blocks. A failed entry keeps its error and is retried on the next tick;
+// the others still run. The returned error joins the failures.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	q, err := LoadQueue(s.path)
+	if err != nil {
+		return err
+	}
+	head, err := s.net.Client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("schedule: head: %w", err)
+	}
+	due := q.Due(s.now(), head)
+	runs := make([]run, len(due))
+	for i, e := range due {
+		runs[i] = run{entry: e}
+		s.prepare(ctx, &runs[i], head)
+	}
+	s.sign(ctx, runs)
+	txs := make([]*types.Transaction, len(runs))
+	for i := range runs {
+		txs[i] = runs[i].tx
+	}
+	receipts, sendErrs := s.tm.SendAll(ctx, txs)
+
+	var errs []error
+	for i := range runs {
+		r := &runs[i]
+		if r.tx != nil {
+			if r.err = sendErrs[i]; r.err == nil {
+				r.x, r.err = s.verify(ctx, r, receipts[i])
+			}
+		}
+		e := r.entry
+		if r.err != nil {
+			r.err = fmt.Errorf("schedule: entry %s: %w", e.ID, r.err)
+			errs = append(errs, r.err)
+		} else {
+			e.drift(r.x)
+		}
+		// The file may have been edited while the changes were mined;
+		// write back only this entry.
+		if err := s.record(e.ID, r.x, r.err); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if r.x != nil && s.OnDone != nil {
+			e.Done = r.x
+			s.OnDone(e)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run is one due entry on its way through a tick.
+type run struct {
+	entry *Entry
+	id    *big.Int
+	addr  common.Address
+	pool  *binding.CrossGameRewardPool
+	tx    *types.Transaction // nil if nothing is sent
+	x     *Execution
+	err   error
+}
+
+func (s *Scheduler) record(id string, x *Execution, failure error) error {
+	q, err := LoadQueue(s.path)
+	if err != nil {
+		return err
+	}
+	e := q.Find(id)
+	if e == nil {
+		return nil // cancelled meanwhile
+	}
+	if x != nil {
+		e.Done, e.LastError = x, ""
+	} else {
+		e.LastError = failure.Error()
+	}
+	return q.Save(s.path)
+}
+
+// prepare resolves r's pool and, if the pool already has the planned
+// status, fills in how it got there. Otherwise r is left to be signed.
+func (s *Scheduler) prepare(ctx context.Context, r *run, head uint64) {
+	r.id = new(big.Int).SetUint64(r.entry.PoolID)
+	if r.addr, r.pool, r.err = s.net.PoolByID(s.tm.CallOpts(ctx), r.id); r.err != nil {
+		return
+	}
+	status, err := r.pool.PoolStatus(s.tm.CallOpts(ctx))
+	if err != nil {
+		r.err = err
+		return
+	}
+	if config.PoolStatus(status) == r.entry.Status {
+		r.x, r.err = s.inPlace(ctx, r, head)
+	}
+}
+
+// sign signs the change of every prepared run without broadcasting it. A
+// nonce whose transaction fails to sign is reused for the next run, so the
+// signed transactions have no gap.
+func (s *Scheduler) sign(ctx context.Context, runs []run) {
+	var opts *bind.TransactOpts
+	for i := range runs {
+		r := &runs[i]
+		if r.err != nil || r.x != nil {
+			continue
+		}
+		if opts == nil {
+			if opts, r.err = s.tm.Opts(ctx); r.err != nil {
+				opts = nil
+				continue
+			}
+			opts.NoSend = true
+		}
+		if r.tx, r.err = s.net.Factory.SetPoolStatus(opts, r.id, uint8(r.entry.Status)); r.err == nil {
+			opts = nil
+		}
+	}
+	if opts != nil {
+		// Its nonce was never used.
+		s.tm.Reset()
+	}
+}
+
+// verify checks that receipt carries the planned PoolStatusChanged event.
+func (s *Scheduler) verify(ctx context.Context, r *run, receipt *types.Receipt) (*Execution, error) {
+	want := r.entry.Status
+	x := &Execution{TxHash: receipt.TxHash, Block: receipt.BlockNumber.Uint64()}
+	changed := false
+	for _, l := range receipt.Logs {
+		if l.Address != r.addr {
+			continue
+		}
+		if ev, err := r.pool.ParsePoolStatusChanged(*l); err == nil {
+			if config.PoolStatus(ev.NewStatus) != want {
+				return nil, fmt.Errorf("PoolStatusChanged in %s set %s, not %s", receipt.TxHash, config.PoolStatus(ev.NewStatus), want)
+			}
+			x.From, changed = config.PoolStatus(ev.OldStatus), true
+		}
+	}
+	if !changed {
+		return nil, fmt.Errorf("no PoolStatusChanged event in %s", receipt.TxHash)
+	}
+	return x, s.stamp(ctx, x)
+}
+
+// inPlace records a pool that already has the planned status. The change
+// is looked up among the pool's PoolStatusChanged events since the entry
+// came due, in case it was made by an earlier run that stopped before
+// recording it; failing that the current head is recorded.
+func (s *Scheduler) inPlace(ctx context.Context, r *run, head uint64) (*Execution, error) {
+	from, err := s.dueBlock(ctx, r.entry, head)
+	if err != nil {
+		return nil, err
+	}
+	for to := head; to >= from; {
+		lo := max(from, to-min(to, DefaultChunkSize-1))
+		it, err := r.pool.FilterPoolStatusChanged(&bind.FilterOpts{Start: lo, End: &to, Context: ctx})
+		if err != nil {
+			return nil, fmt.Errorf("PoolStatusChanged %d-%d: %w", lo, to, err)
+		}
+		var last *binding.CrossGameRewardPoolPoolStatusChanged
+		for it.Next() {
+			if ev := it.Event; !ev.Raw.Removed && config.PoolStatus(ev.NewStatus) == r.entry.Status {
+				last = ev
+			}
+		}
+		if err := it.Error(); err != nil {
+			return nil, fmt.Errorf("PoolStatusChanged %d-%d: %w", lo, to, err)
+		}
+		if last != nil {
+			x := &Execution{TxHash: last.Raw.TxHash, Block: last.Raw.BlockNumber, From: config.PoolStatus(last.OldStatus)}
+			return x, s.stamp(ctx, x)
+		}
+		if lo == 0 {
+			break
+		}
+		to = lo - 1
+	}
+	x := &Execution{Block: head, From: r.entry.Status}
+	return x, s.stamp(ctx, x)
+}
+
+// dueBlock returns the last block before e came due: the block before its
+// target block, or the last block timed before its target time.
+func (s *Scheduler) dueBlock(ctx context.Context, e *Entry, head uint64) (uint64, error) {
+	if e.Block != 0 {
+		return min(e.Block-1, head), nil
+	}
+	// Find the first block timed at or after e.At.
+	lo, hi := uint64(0), head+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		h, err := s.net.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if int64(h.Time) >= e.At.Unix() {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo - min(lo, 1), nil
+}
+
+// stamp sets x.Time from the header of x.Block.
+func (s *Scheduler) stamp(ctx context.Context, x *Execution) error {
+	h, err := s.net.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(x.Block))
+	if err != nil {
+		return err
+	}
+	x.Time = time.Unix(int64(h.Time), 0).UTC()
+	return nil
+}
@@ -0,0 +1,359 @@
+// Package season runs a game season's pool rollover from a declarative
+// spec.
+//
+// A season opens a pool and adds its reward tokens, then retires the pool
+// it replaces: the old pool is set Inactive, its reclaimable rewards go to
+// the treasury once players have had time to claim, and finally its reward
+// tokens are removed. Each phase starts at its scheduled time. Every step
+// first checks whether its change is already in place, and progress is
+// saved to a state file after each step, so a runbook can be stopped and
+// run again at any point. Summarize reports the retired season from the
+// index.
+package season
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/probe"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// ErrNotManager is returned when the sending account lacks MANAGER_ROLE.
+// It is the error config.Network.RequireRole wraps.
+var ErrNotManager = config.ErrMissingRole
+
+// DefaultInterval is the time between checks in Run.
+const DefaultInterval = time.Minute
+
+// step is one idempotent action of the runbook.
+type step struct {
+	id  string
+	at  time.Time
+	run func(ctx context.Context, rec *StepRecord) error
+}
+
+// Runbook executes one season spec.
+type Runbook struct {
+	net   *config.Network
+	tm    *txmgr.Manager
+	spec  *Spec
+	state *State
+	path  string
+	now   func() time.Time
+
+	// Interval is the time between checks in Run. Zero means
+	// DefaultInterval.
+	Interval time.Duration
+	// OnStep is called after each finished step, if set.
+	OnStep func(id string, rec *StepRecord)
+	// OnError receives step failures in Run; nil drops them.
+	OnError func(error)
+}
+
+// New returns a Runbook for spec that keeps its progress in statePath and
+// sends as tm's account, which must hold MANAGER_ROLE.
+func New(ctx context.Context, net *config.Network, tm *txmgr.Manager, spec *Spec, statePath string) (*Runbook, error) {
+	state, err := LoadState(statePath, spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := net.RequireRole(tm.CallOpts(ctx), config.RoleManager, tm.From()); err != nil {
+		return nil, err
+	}
+	return &Runbook{net: net, tm: tm, spec: spec, state: state, path: statePath, now: time.Now}, nil
+}
+
+// State returns the runbook's progress.
+func (r *Runbook) State() *State {
+	return r.state
+}
+
+// Run executes steps as they fall due until every step is done or ctx is
+// done.
+func (r *Runbook) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done, err := r.Step(ctx)
+		if err != nil && r.OnError != nil {
+			r.OnError(err)
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step runs every step that is due, in order, and reports whether the
+// season is complete. It stops at the first failure; the failed step is
+// retried on the next call.
+func (r *Runbook) Step(ctx context.Context) (bool, error) {
+	steps, err := r.steps(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range due(steps, r.state, r.now()) {
+		rec := new(StepRecord)
+		if err := s.run(ctx, rec); err != nil {
+			return false, fmt.Errorf("season: %s: %w", s.id, err)
+		}
+		rec.Done = r.now().UTC()
+		r.state.Steps[s.id] = rec
+		if err := r.state.Save(r.path); err != nil {
+			return false, err
+		}
+		if r.OnStep != nil {
+			r.OnStep(s.id, rec)
+		}
+	}
+	return complete(steps, r.state), nil
+}
+
+// due returns the steps to run at now: the pending steps in order, up to
+// the first one whose time has not come.
+func due(steps []step, state *State, now time.Time) []step {
+	var out []step
+	for _, s := range steps {
+		if state.Steps[s.id] != nil {
+			continue
+		}
+		if now.Before(s.at) {
+			break
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func complete(steps []step, state *State) bool {
+	for _, s := range steps {
+		if state.Steps[s.id] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// steps lists the runbook in order. The previous pool's reclaim and remove
+// steps are resolved from its current reward tokens.
+func (r *Runbook) steps(ctx context.Context) ([]step, error) {
+	sched := r.spec.Schedule
+	out := []step{{id: "create-pool", at: sched.Open, run: r.createPool}}
+	for _, t := range r.spec.RewardTokens {
+		out = append(out, step{id: "add-reward:" + t.Hex(), at: sched.Open, run: r.addReward(t)})
+	}
+	if r.spec.Previous == 0 {
+		return out, nil
+	}
+	prev := new(big.Int).SetUint64(r.spec.Previous)
+	if !sched.Retire.IsZero() {
+		out = append(out, step{id: "retire", at: sched.Retire, run: r.retire(prev)})
+	}
+	if sched.Reclaim.IsZero() && sched.Remove.IsZero() {
+		return out, nil
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	_, pool, err := r.net.PoolByID(opts, prev)
+	if err != nil {
+		return nil, err
+	}
+	active, err := pool.GetRewardTokens(opts)
+	if err != nil {
+		return nil, fmt.Errorf("season: pool %s reward tokens: %w", prev, err)
+	}
+	removed, err := pool.GetRemovedRewardTokens(opts)
+	if err != nil {
+		return nil, fmt.Errorf("season: pool %s removed reward tokens: %w", prev, err)
+	}
+	if !sched.Reclaim.IsZero() {
+		for _, t := range append(slices.Clone(active), removed...) {
+			out = append(out, step{id: "reclaim:" + t.Hex(), at: sched.Reclaim, run: r.reclaim(prev, t)})
+		}
+	}
+	if !sched.Remove.IsZero() {
+		for _, t := range active {
+			out = append(out, step{id: "remove:" + t.Hex(), at: sched.Remove, run: r.remove(prev, t)})
+		}
+		// Tokens removed by an earlier run no longer show up as active;
+		// keep their records in the list so the season stays complete.
+		for _, t := range removed {
+			if id := "remove:" + t.Hex(); r.state.Steps[id] != nil {
+				out = append(out, step{id: id, at: sched.Remove, run: r.remove(prev, t)})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *Runbook) send(ctx context.Context, rec *StepRecord, fn txmgr.SendFunc) (*types.Receipt, error) {
+	receipt, err := r.tm.Send(ctx, fn)
+	if receipt != nil {
+		rec.TxHash = receipt.TxHash
+	}
+	return receipt, err
+}
+
+// require probes token and refuses it unless compatible.
+func (r *Runbook) require(ctx context.Context, token common.Address) error {
+	rep, err := probe.New(r.net).Probe(ctx, token, probe.Options{})
+	if err != nil {
+		return err
+	}
+	return probe.Require(rep, r.spec.AllowWarnings)
+}
+
+// createPool adopts a pool with the spec's name and deposit token, or
+// creates one.
+func (r *Runbook) createPool(ctx context.Context, rec *StepRecord) error {
+	opts := r.tm.CallOpts(ctx)
+	ids, err := r.net.Factory.GetPoolIdsByDepositToken(opts, r.spec.Pool.DepositToken)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		info, err := r.net.Factory.GetPoolInfo(opts, id)
+		if err != nil {
+			return err
+		}
+		if info.Name == r.spec.Pool.Name {
+			r.state.PoolID, r.state.Pool = id.Uint64(), info.Pool
+			rec.Note = "adopted existing pool " + id.String()
+			return nil
+		}
+	}
+	if err := r.require(ctx, r.spec.Pool.DepositToken); err != nil {
+		return err
+	}
+	receipt, err := r.send(ctx, rec, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return r.net.Factory.CreatePool(o, r.spec.Pool.Name, r.spec.Pool.DepositToken, r.spec.Pool.MinDeposit)
+	})
+	if err != nil {
+		return err
+	}
+	for _, l := range receipt.Logs {
+		if ev, err := r.net.Factory.ParsePoolCreated(*l); err == nil && l.Address == r.net.FactoryAddress {
+			r.state.PoolID, r.state.Pool = ev.PoolId.Uint64(), ev.PoolAddress
+			rec.Note = "created pool " + ev.PoolId.String()
+			return nil
+		}
+	}
+	return fmt.Errorf("no PoolCreated event in %s", receipt.TxHash)
+}
+
+func (r *Runbook) addReward(token common.Address) func(context.Context, *StepRecord) error {
+	return func(ctx context.Context, rec *StepRecord) error {
+		pool, err := r.net.Pool(r.state.Pool)
+		if err != nil {
+			return err
+		}
+		active, err := pool.GetRewardTokens(r.tm.CallOpts(ctx))
+		if err != nil {
+			return err
+		}
+		if slices.Contains(active, token) {
+			rec.Note = "already added"
+			return nil
+		}
+		if err := r.require(ctx, token); err != nil {
+			return err
+		}
+		_, err = r.send(ctx, rec, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return r.net.Factory.AddRewardToken(o, new(big.Int).SetUint64(r.state.PoolID), token)
+		})
+		return err
+	}
+}
+
+func (r *Runbook) retire(prev *big.Int) func(context.Context, *StepRecord) error {
+	return func(ctx context.Context, rec *StepRecord) error {
+		_, pool, err := r.net.PoolByID(r.tm.CallOpts(ctx), prev)
+		if err != nil {
+			return err
+		}
+		status, err := pool.PoolStatus(r.tm.CallOpts(ctx))
+		if err != nil {
+			return err
+		}
+		switch config.PoolStatus(status) {
+		case config.PoolInactive:
+			rec.Note = "already inactive"
+			return nil
+		case config.PoolPaused:
+			// Never lift an emergency pause on a schedule.
+			return fmt.Errorf("pool %s is paused; resume it before retiring", prev)
+		}
+		_, err = r.send(ctx, rec, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return r.net.Factory.SetPoolStatus(o, prev, uint8(config.PoolInactive))
+		})
+		return err
+	}
+}
+
+func (r *Runbook) reclaim(prev *big.Int, token common.Address) func(context.Context, *StepRecord) error {
+	return func(ctx context.Context, rec *StepRecord) error {
+		_, pool, err := r.net.PoolByID(r.tm.CallOpts(ctx), prev)
+		if err != nil {
+			return err
+		}
+		amount, err := pool.GetReclaimableAmount(r.tm.CallOpts(ctx), token)
+		if err != nil {
+			return err
+		}
+		rec.Amount = new(big.Int)
+		if amount.Sign() == 0 {
+			rec.Note = "nothing to reclaim"
+			return nil
+		}
+		receipt, err := r.send(ctx, rec, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return r.net.Factory.ReclaimFromPool(o, prev, token, r.spec.Treasury)
+		})
+		if err != nil {
+			return err
+		}
+		for _, l := range receipt.Logs {
+			if ev, err := r.net.Factory.ParseReclaimedFromPool(*l); err == nil && l.Address == r.net.FactoryAddress {
+				rec.Amount = ev.Amount
+			}
+		}
+		return nil
+	}
+}
+
+func (r *Runbook) remove(prev *big.Int, token common.Address) func(context.Context, *StepRecord) error {
+	return func(ctx context.Context, rec *StepRecord) error {
+		_, pool, err := r.net.PoolByID(r.tm.CallOpts(ctx), prev)
+		if err != nil {
+			return err
+		}
+		active, err := pool.GetRewardTokens(r.tm.CallOpts(ctx))
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(active, token) {
+			rec.Note = "already removed"
+			return nil
+		}
+		_, err = r.send(ctx, rec, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return r.net.Factory.RemoveRewardToken(o, prev, token)
+		})
+		return err
+	}
+}
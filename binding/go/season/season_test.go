@@ -0,0 +1,145 @@
+package season
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+func TestExampleSpec(t *testing.T) {
+	s, err := LoadSpec("season.example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Previous != 3 || len(s.RewardTokens) != 2 || s.Pool.MinDeposit.String() != "1000000000000000000" {
+		t.Errorf("spec %+v", s)
+	}
+}
+
+func TestSpecValidation(t *testing.T) {
+	open := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() *Spec {
+		return &Spec{
+			Name:     "s",
+			Pool:     PoolSpec{Name: "S", DepositToken: common.HexToAddress("0x01"), MinDeposit: big.NewInt(1)},
+			Previous: 1,
+			Treasury: common.HexToAddress("0x7e"),
+			Schedule: Schedule{Open: open, Retire: open, Reclaim: open.AddDate(0, 0, 7)},
+		}
+	}
+	if err := valid().validate(); err != nil {
+		t.Fatal(err)
+	}
+	for name, mutate := range map[string]func(*Spec){
+		"no name":          func(s *Spec) { s.Name = "" },
+		"no min deposit":   func(s *Spec) { s.Pool.MinDeposit = nil },
+		"zero min deposit": func(s *Spec) { s.Pool.MinDeposit = new(big.Int) },
+		"repeated reward":  func(s *Spec) { s.RewardTokens = []common.Address{{1}, {1}} },
+		"reclaim no safe":  func(s *Spec) { s.Treasury = common.Address{} },
+		"out of order":     func(s *Spec) { s.Schedule.Reclaim = open.Add(-time.Hour) },
+		"retire, no prior": func(s *Spec) { s.Previous = 0 },
+	} {
+		s := valid()
+		mutate(s)
+		if err := s.validate(); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("%s: err %v", name, err)
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	t0 := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	noop := func(context.Context, *StepRecord) error { return nil }
+	steps := []step{
+		{id: "create-pool", at: t0, run: noop},
+		{id: "add-reward:a", at: t0, run: noop},
+		{id: "retire", at: t0.Add(time.Hour), run: noop},
+		{id: "reclaim:a", at: t0.Add(2 * time.Hour), run: noop},
+	}
+	state := &State{Steps: map[string]*StepRecord{"create-pool": {Done: t0}}}
+	ids := func(now time.Time) []string {
+		var out []string
+		for _, s := range due(steps, state, now) {
+			out = append(out, s.id)
+		}
+		return out
+	}
+	if got := ids(t0.Add(-time.Minute)); len(got) != 0 {
+		t.Errorf("before open: %v", got)
+	}
+	if got := ids(t0.Add(90 * time.Minute)); len(got) != 2 || got[0] != "add-reward:a" || got[1] != "retire" {
+		t.Errorf("after retire time: %v", got)
+	}
+	if complete(steps, state) {
+		t.Error("complete with pending steps")
+	}
+	for _, s := range steps {
+		state.Steps[s.id] = &StepRecord{Done: t0}
+	}
+	if !complete(steps, state) || len(ids(t0.Add(24*time.Hour))) != 0 {
+		t.Error("finished runbook still has work")
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := LoadState(path, "s4")
+	if err != nil || len(s.Steps) != 0 {
+		t.Fatalf("fresh state %+v, %v", s, err)
+	}
+	s.PoolID = 4
+	s.Steps["retire"] = &StepRecord{Done: time.Unix(1, 0).UTC(), Note: "already inactive"}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := LoadState(path, "s4")
+	if err != nil || back.PoolID != 4 || back.Steps["retire"].Note != "already inactive" {
+		t.Fatalf("reloaded %+v, %v", back, err)
+	}
+	if _, err := LoadState(path, "s5"); err == nil {
+		t.Error("state of another season accepted")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	pool, other := common.HexToAddress("0xb3"), common.HexToAddress("0xb4")
+	reward := common.HexToAddress("0x2001")
+	a, b := common.HexToAddress("0xa1"), common.HexToAddress("0xa2")
+	store := indexer.NewStore()
+	ids := map[common.Address]uint64{pool: 3, other: 4}
+	ev := func(kind indexer.Kind, p, acct, token common.Address, amount int64, block uint64) indexer.Event {
+		return indexer.Event{Kind: kind, PoolID: ids[p], Pool: p, Account: acct, Token: token, Amount: big.NewInt(amount), Block: block, LogIndex: uint(amount)}
+	}
+	if err := store.Append(50,
+		ev(indexer.KindDeposited, pool, a, common.Address{}, 100, 10),
+		ev(indexer.KindDeposited, pool, b, common.Address{}, 50, 11),
+		ev(indexer.KindDeposited, pool, a, common.Address{}, 25, 12),
+		ev(indexer.KindDeposited, other, b, common.Address{}, 999, 12),
+		ev(indexer.KindWithdrawn, pool, a, common.Address{}, 125, 20),
+		ev(indexer.KindRewardClaimed, pool, a, reward, 7, 20),
+		ev(indexer.KindRewardClaimed, pool, b, reward, 3, 21),
+	); err != nil {
+		t.Fatal(err)
+	}
+	state := &State{Season: "s4", Steps: map[string]*StepRecord{
+		"reclaim:" + reward.Hex(): {Amount: big.NewInt(40)},
+		"retire":                  {},
+	}}
+	s := Summarize(store, state, 3)
+	if s == nil || s.Participants != 2 || s.Deposited.Int64() != 175 || s.Withdrawn.Int64() != 125 || s.IndexHead != 50 {
+		t.Fatalf("summary %+v", s)
+	}
+	if len(s.Distributed) != 1 || s.Distributed[0].Amount.Int64() != 10 || len(s.Reclaimed) != 1 || s.Reclaimed[0].Amount.Int64() != 40 {
+		t.Errorf("rewards %+v reclaimed %+v", s.Distributed, s.Reclaimed)
+	}
+	if Summarize(store, state, 9) != nil {
+		t.Error("summary of an unknown pool")
+	}
+}
@@ -0,0 +1,117 @@
+package season
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidSpec is returned for season specs that cannot be run.
+var ErrInvalidSpec = errors.New("season: invalid spec")
+
+// Spec is the season file read by LoadSpec.
+type Spec struct {
+	// Name identifies the season; a state file belongs to one name.
+	Name string `json:"name"`
+	// Pool is the pool opened for the season.
+	Pool PoolSpec `json:"pool"`
+	// RewardTokens are added to the new pool.
+	RewardTokens []common.Address `json:"rewardTokens"`
+	// Previous is the ID of the pool the season replaces; zero for none.
+	Previous uint64 `json:"previous,omitempty"`
+	// Treasury receives the previous pool's reclaimable rewards.
+	Treasury common.Address `json:"treasury,omitzero"`
+	// AllowWarnings accepts deposit and reward tokens whose compatibility
+	// probe has only warnings.
+	AllowWarnings bool `json:"allowWarnings,omitempty"`
+	// Schedule sets when each phase may start.
+	Schedule Schedule `json:"schedule"`
+}
+
+// PoolSpec describes the new pool. A pool with the same name and deposit
+// token that already exists is adopted instead of created.
+type PoolSpec struct {
+	Name         string         `json:"name"`
+	DepositToken common.Address `json:"depositToken"`
+	MinDeposit   *big.Int       `json:"minDeposit"`
+}
+
+// Schedule holds the earliest time of each phase. Phases run in order, so
+// a phase never starts before the previous one has finished.
+type Schedule struct {
+	// Open creates the pool and adds its reward tokens.
+	Open time.Time `json:"open"`
+	// Retire sets the previous pool Inactive.
+	Retire time.Time `json:"retire,omitzero"`
+	// Reclaim sends the previous pool's reclaimable rewards to the
+	// treasury.
+	Reclaim time.Time `json:"reclaim,omitzero"`
+	// Remove removes the previous pool's reward tokens.
+	Remove time.Time `json:"remove,omitzero"`
+}
+
+// LoadSpec reads and validates the season file at path.
+func LoadSpec(path string) (*Spec, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(Spec)
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("season: parse %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Spec) validate() error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("%w: no name", ErrInvalidSpec)
+	case s.Pool.Name == "":
+		return fmt.Errorf("%w: no pool name", ErrInvalidSpec)
+	case s.Pool.DepositToken == (common.Address{}):
+		return fmt.Errorf("%w: no deposit token", ErrInvalidSpec)
+	case s.Pool.MinDeposit == nil || s.Pool.MinDeposit.Sign() <= 0:
+		return fmt.Errorf("%w: pool needs a positive minDeposit", ErrInvalidSpec)
+	case s.Schedule.Open.IsZero():
+		return fmt.Errorf("%w: no open time", ErrInvalidSpec)
+	}
+	seen := make(map[common.Address]bool, len(s.RewardTokens))
+	for _, t := range s.RewardTokens {
+		if t == (common.Address{}) || seen[t] {
+			return fmt.Errorf("%w: reward token %s is zero or repeated", ErrInvalidSpec, t.Hex())
+		}
+		seen[t] = true
+	}
+	if s.Previous == 0 {
+		if !s.Schedule.Retire.IsZero() || !s.Schedule.Reclaim.IsZero() || !s.Schedule.Remove.IsZero() {
+			return fmt.Errorf("%w: retire, reclaim and remove need a previous pool", ErrInvalidSpec)
+		}
+		return nil
+	}
+	if !s.Schedule.Reclaim.IsZero() && s.Treasury == (common.Address{}) {
+		return fmt.Errorf("%w: reclaim needs a treasury", ErrInvalidSpec)
+	}
+	last := s.Schedule.Open
+	for _, p := range []struct {
+		name string
+		at   time.Time
+	}{{"retire", s.Schedule.Retire}, {"reclaim", s.Schedule.Reclaim}, {"remove", s.Schedule.Remove}} {
+		if p.at.IsZero() {
+			continue
+		}
+		if p.at.Before(last) {
+			return fmt.Errorf("%w: %s is scheduled before the previous phase", ErrInvalidSpec, p.name)
+		}
+		last = p.at
+	}
+	return nil
+}
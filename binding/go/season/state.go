@@ -0,0 +1,64 @@
+package season
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// State is the progress of one season, kept in a file between runs.
+type State struct {
+	Season string `json:"season"`
+	// PoolID and Pool are the season's pool once created or adopted.
+	PoolID uint64                 `json:"poolId,omitempty"`
+	Pool   common.Address         `json:"pool,omitzero"`
+	Steps  map[string]*StepRecord `json:"steps"`
+}
+
+// StepRecord is a finished step.
+type StepRecord struct {
+	Done time.Time `json:"done"`
+	// TxHash is zero when the step found its change already in place.
+	TxHash common.Hash `json:"txHash,omitzero"`
+	// Amount is the amount reclaimed by a reclaim step.
+	Amount *big.Int `json:"amount,omitempty"`
+	Note   string   `json:"note,omitempty"`
+}
+
+// LoadState reads the state file at path; a missing file yields a fresh
+// state for season. A file written for another season is rejected.
+func LoadState(path, season string) (*State, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &State{Season: season, Steps: make(map[string]*StepRecord)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := new(State)
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, fmt.Errorf("season: parse %s: %w", path, err)
+	}
+	if s.Season != season {
+		return nil, fmt.Errorf("season: %s holds season %q, not %q", path, s.Season, season)
+	}
+	if s.Steps == nil {
+		s.Steps = make(map[string]*StepRecord)
+	}
+	return s, nil
+}
+
+// Save writes the state to path.
+func (s *State) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
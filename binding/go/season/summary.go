@@ -0,0 +1,95 @@
+package season
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+// TokenAmount is a total of one token.
+type TokenAmount struct {
+	Token  common.Address `json:"token"`
+	Amount *big.Int       `json:"amount"`
+}
+
+// Summary reports a retired season's pool.
+type Summary struct {
+	Season string         `json:"season"`
+	PoolID uint64         `json:"poolId"`
+	Pool   common.Address `json:"pool"`
+	// IndexHead is the last block the figures include.
+	IndexHead uint64 `json:"indexHead"`
+	// Participants is the number of accounts that ever deposited.
+	Participants int      `json:"participants"`
+	Deposited    *big.Int `json:"deposited"`
+	Withdrawn    *big.Int `json:"withdrawn"`
+	// Distributed totals the rewards players claimed, by token.
+	Distributed []TokenAmount `json:"distributed"`
+	// Reclaimed totals what the runbook's reclaim steps sent to the
+	// treasury, by token.
+	Reclaimed []TokenAmount `json:"reclaimed"`
+}
+
+// Summarize reports the pool with ID poolID from the index, with the
+// reclaimed amounts recorded in state. It returns nil if the index does
+// not know the pool.
+func Summarize(store *indexer.Store, state *State, poolID uint64) *Summary {
+	var pool common.Address
+	for addr, id := range store.Pools() {
+		if id == poolID {
+			pool = addr
+		}
+	}
+	if pool == (common.Address{}) {
+		return nil
+	}
+	s := &Summary{
+		Season:       state.Season,
+		PoolID:       poolID,
+		Pool:         pool,
+		IndexHead:    store.Head(),
+		Participants: len(store.Depositors(pool)),
+		Deposited:    new(big.Int),
+		Withdrawn:    new(big.Int),
+	}
+	distributed := make(map[common.Address]*big.Int)
+	for _, ev := range store.Events(indexer.Filter{Pools: []common.Address{pool}}) {
+		switch ev.Kind {
+		case indexer.KindDeposited:
+			s.Deposited.Add(s.Deposited, ev.Amount)
+		case indexer.KindWithdrawn:
+			s.Withdrawn.Add(s.Withdrawn, ev.Amount)
+		case indexer.KindRewardClaimed:
+			addTo(distributed, ev.Token, ev.Amount)
+		}
+	}
+	reclaimed := make(map[common.Address]*big.Int)
+	for id, rec := range state.Steps {
+		if token, ok := strings.CutPrefix(id, "reclaim:"); ok && rec.Amount != nil && rec.Amount.Sign() > 0 {
+			addTo(reclaimed, common.HexToAddress(token), rec.Amount)
+		}
+	}
+	s.Distributed, s.Reclaimed = sorted(distributed), sorted(reclaimed)
+	return s
+}
+
+func addTo(m map[common.Address]*big.Int, token common.Address, v *big.Int) {
+	if m[token] == nil {
+		m[token] = new(big.Int)
+	}
+	m[token].Add(m[token], v)
+}
+
+func sorted(m map[common.Address]*big.Int) []TokenAmount {
+	out := make([]TokenAmount, 0, len(m))
+	for t, v := range m {
+		out = append(out, TokenAmount{Token: t, Amount: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].Token[:], out[j].Token[:]) < 0 })
+	return out
+}
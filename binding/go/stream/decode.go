@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+// parser is the generated Parse* method of one event.
+type parser struct {
+	name  string
+	parse reflect.Value
+}
+
+// decoder maps the signature topic of every event of every contract kind to
+// its generated Parse* method.
+type decoder struct {
+	parsers map[Contract]map[common.Hash]parser
+}
+
+func newDecoder() (*decoder, error) {
+	factory, err := binding.NewCrossGameRewardFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := binding.NewCrossGameRewardPoolFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	router, err := binding.NewCrossGameRewardRouterFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	wcross, err := binding.NewWCROSSFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{parsers: make(map[Contract]map[common.Hash]parser)}
+	for _, c := range []struct {
+		kind     Contract
+		meta     *bind.MetaData
+		filterer any
+	}{
+		{Factory, binding.CrossGameRewardMetaData, factory},
+		{Pool, binding.CrossGameRewardPoolMetaData, pool},
+		{Router, binding.CrossGameRewardRouterMetaData, router},
+		{WCROSS, binding.WCROSSMetaData, wcross},
+	} {
+		if err := d.add(c.kind, c.meta, c.filterer); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (d *decoder) add(kind Contract, meta *bind.MetaData, filterer any) error {
+	parsed, err := meta.GetAbi()
+	if err != nil {
+		return err
+	}
+	d.parsers[kind] = make(map[common.Hash]parser, len(parsed.Events))
+	for _, ev := range parsed.Events {
+		// abigen names the method after the camel-cased, de-overloaded
+		// event name.
+		m := reflect.ValueOf(filterer).MethodByName("Parse" + abi.ToCamelCase(ev.Name))
+		if !m.IsValid() {
+			return fmt.Errorf("stream: %s has no parser for %s", kind, ev.Name)
+		}
+		d.parsers[kind][ev.ID] = parser{name: ev.Name, parse: m}
+	}
+	return nil
+}
+
+// decode parses l as an event of kind. Logs whose signature is not in the
+// contract's ABI decode to a nil value with an empty name.
+func (d *decoder) decode(kind Contract, l types.Log) (string, any, error) {
+	if len(l.Topics) == 0 {
+		return "", nil, nil
+	}
+	p, ok := d.parsers[kind][l.Topics[0]]
+	if !ok {
+		return "", nil, nil
+	}
+	out := p.parse.Call([]reflect.Value{reflect.ValueOf(l)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return p.name, nil, fmt.Errorf("stream: decode %s %s: %w", kind, p.name, err)
+	}
+	return p.name, out[0].Interface(), nil
+}
+
+// poolIDArg returns the poolId argument of a decoded factory or router
+// event, or nil if it has none.
+func poolIDArg(data any) *big.Int {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.Elem().FieldByName("PoolId")
+	if !f.IsValid() {
+		return nil
+	}
+	id, _ := f.Interface().(*big.Int)
+	return id
+}
@@ -0,0 +1,335 @@
+// Package stream merges every protocol event into one ordered stream.
+//
+// A Stream holds a single log subscription whose address filter covers the
+// factory, every pool, the router and WCROSS. Pools are added as PoolCreated
+// fires and routers as RouterSet fires; the subscription is then reopened
+// with the larger address set and the blocks since the triggering event are
+// replayed, so no event of the new contract is missed. Connection failures
+// are retried with backoff and the missed blocks are backfilled the same
+// way, by the loop package subscription uses.
+//
+// Each log is decoded into the generated event struct of its contract and
+// annotated with the pool it concerns, its block time and its confirmation
+// count. A log that fails to decode is reported to Config.OnError and
+// skipped:
+//
+//	s := stream.New(net, stream.Config{})
+//	go s.Run(ctx)
+//	for ev := range s.C() {
+//		switch e := ev.Data.(type) {
+//		case *binding.CrossGameRewardPoolDeposited:
+//			...
+//		}
+//	}
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/follow"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+// Contract is the kind of contract that emitted an event.
+type Contract uint8
+
+const (
+	Factory Contract = iota
+	Pool
+	Router
+	WCROSS
+)
+
+func (c Contract) String() string {
+	switch c {
+	case Factory:
+		return "factory"
+	case Pool:
+		return "pool"
+	case Router:
+		return "router"
+	case WCROSS:
+		return "wcross"
+	}
+	return fmt.Sprintf("contract(%d)", c)
+}
+
+// Event is one decoded protocol log.
+type Event struct {
+	Contract Contract
+	// Name is the ABI event name; empty for logs outside the ABI.
+	Name string
+	// PoolID is the emitting pool for pool events and the poolId argument
+	// of factory and router events; nil otherwise.
+	PoolID *big.Int
+	// Time is the block timestamp. It is zero for a removed log whose
+	// block the node no longer serves.
+	Time uint64
+	// Confirmations counts the log's block and those built on it, against
+	// the newest block the stream had seen when delivering the event.
+	Confirmations uint64
+	// Data is the generated event struct, for example
+	// *binding.CrossGameRewardPoolDeposited, or nil for logs outside the
+	// ABI.
+	Data any
+	// Log is the raw log. Log.Removed marks a retraction after a reorg.
+	Log types.Log
+}
+
+// Client is the node API a Stream needs; *ethclient.Client satisfies it.
+type Client interface {
+	ethereum.LogFilterer
+	ethereum.BlockNumberReader
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// Config tunes a Stream. The zero value starts at the current head.
+type Config struct {
+	// From is the first block to deliver; zero starts at the head.
+	From uint64
+	// MinBackoff and MaxBackoff bound the reconnect delay, which doubles
+	// after every failed attempt. Zero values use the subscription
+	// package defaults.
+	MinBackoff, MaxBackoff time.Duration
+	// ChunkSize bounds the block range of each backfill query.
+	ChunkSize uint64
+	// OnError receives every connection failure before the retry, and
+	// every log that is skipped because it cannot be decoded.
+	OnError func(error)
+}
+
+// errUndecodable marks a log that can never be turned into an Event.
+var errUndecodable = errors.New("stream: undecodable log")
+
+// timeCacheSize bounds the number of block times kept.
+const timeCacheSize = 4096
+
+// Stream delivers the events of all protocol contracts of one network.
+type Stream struct {
+	client  Client
+	dec     *decoder
+	onError func(error)
+	out     chan *Event
+	loop    *follow.Loop[types.Log]
+	// listPools returns the pools known at start, by address.
+	listPools func(ctx context.Context) (map[common.Address]*big.Int, error)
+
+	// The fields below are owned by Run.
+	contracts map[common.Address]Contract
+	pools     map[common.Address]*big.Int
+	addrs     []common.Address
+	head      uint64
+	times     map[common.Hash]uint64
+}
+
+// New returns a Stream over net; call Run to start it.
+func New(net *config.Network, cfg Config) *Stream {
+	contracts := map[common.Address]Contract{net.Profile.Factory: Factory, net.WCROSSAddress: WCROSS}
+	if net.RouterAddress != (common.Address{}) {
+		contracts[net.RouterAddress] = Router
+	}
+	return newStream(net.Client, contracts, func(ctx context.Context) (map[common.Address]*big.Int, error) {
+		refs, err := net.Pools(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		pools := make(map[common.Address]*big.Int, len(refs))
+		for _, p := range refs {
+			pools[p.Address] = p.ID
+		}
+		return pools, nil
+	}, cfg)
+}
+
+func newStream(client Client, contracts map[common.Address]Contract, listPools func(context.Context) (map[common.Address]*big.Int, error), cfg Config) *Stream {
+	dec, err := newDecoder()
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	s := &Stream{
+		client:    client,
+		dec:       dec,
+		onError:   cfg.OnError,
+		out:       make(chan *Event),
+		listPools: listPools,
+		contracts: contracts,
+		pools:     make(map[common.Address]*big.Int),
+		times:     make(map[common.Hash]uint64),
+	}
+	s.loop = follow.New(follow.Source[types.Log]{
+		Head:   headReader{s},
+		Watch:  s.watch,
+		Filter: s.filter,
+		Log:    func(l types.Log) types.Log { return l },
+	}, follow.Config{
+		Name:       "stream",
+		From:       cfg.From,
+		MinBackoff: cfg.MinBackoff,
+		MaxBackoff: cfg.MaxBackoff,
+		ChunkSize:  cfg.ChunkSize,
+		OnError:    cfg.OnError,
+	}, s.emit)
+	return s
+}
+
+// C returns the channel events are delivered on. It is closed when Run
+// returns.
+func (s *Stream) C() <-chan *Event {
+	return s.out
+}
+
+// Cursor returns the block to resume from after a restart: passing it as
+// Config.From redelivers at most the events of that one block.
+func (s *Stream) Cursor() uint64 {
+	return s.loop.Cursor()
+}
+
+// Run delivers events until ctx is done, reconnecting on failure. It fails
+// only if the initial pool list cannot be read.
+func (s *Stream) Run(ctx context.Context) error {
+	defer close(s.out)
+	pools, err := s.listPools(ctx)
+	if err != nil {
+		return fmt.Errorf("stream: list pools: %w", err)
+	}
+	for addr, id := range pools {
+		s.addPool(addr, id)
+	}
+	return s.loop.Run(ctx)
+}
+
+func (s *Stream) addPool(addr common.Address, id *big.Int) {
+	s.contracts[addr] = Pool
+	s.pools[addr] = id
+}
+
+// watch subscribes to the current address set, which the backfill of the
+// same session then queries.
+func (s *Stream) watch(ctx context.Context, sink chan<- types.Log) (ethereum.Subscription, error) {
+	s.addrs = make([]common.Address, 0, len(s.contracts))
+	for addr := range s.contracts {
+		s.addrs = append(s.addrs, addr)
+	}
+	sort.Slice(s.addrs, func(i, j int) bool { return s.addrs[i].Cmp(s.addrs[j]) < 0 })
+	return s.client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: s.addrs}, sink)
+}
+
+func (s *Stream) filter(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	return s.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: s.addrs,
+	})
+}
+
+// headReader records every head a session reads, for confirmation counts.
+type headReader struct{ s *Stream }
+
+func (h headReader) BlockNumber(ctx context.Context) (uint64, error) {
+	head, err := h.s.client.BlockNumber(ctx)
+	if err == nil {
+		h.s.head = max(h.s.head, head)
+	}
+	return head, err
+}
+
+// emit decodes and delivers l. A log that cannot be decoded is reported
+// to OnError and skipped, since replaying it would fail the same way. emit
+// returns follow.ErrResubscribe after delivering an event that adds a
+// contract, so the blocks since are replayed with the new address.
+func (s *Stream) emit(ctx context.Context, l types.Log) error {
+	ev, err := s.event(ctx, l)
+	if errors.Is(err, errUndecodable) {
+		if s.onError != nil {
+			s.onError(err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	select {
+	case s.out <- ev:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if !l.Removed && s.grow(ev) {
+		return follow.ErrResubscribe
+	}
+	return nil
+}
+
+// grow adds the contract announced by ev, reporting whether it is new.
+func (s *Stream) grow(ev *Event) bool {
+	var addr common.Address
+	switch e := ev.Data.(type) {
+	case *binding.CrossGameRewardPoolCreated:
+		if _, ok := s.contracts[e.PoolAddress]; ok {
+			return false
+		}
+		s.addPool(e.PoolAddress, e.PoolId)
+		return true
+	case *binding.CrossGameRewardRouterSet:
+		addr = e.Router
+	default:
+		return false
+	}
+	// Routers that were replaced stay in the set: they may still emit.
+	if _, ok := s.contracts[addr]; ok || addr == (common.Address{}) {
+		return false
+	}
+	s.contracts[addr] = Router
+	return true
+}
+
+func (s *Stream) event(ctx context.Context, l types.Log) (*Event, error) {
+	kind, ok := s.contracts[l.Address]
+	if !ok {
+		return nil, fmt.Errorf("%w: log from unknown address %s", errUndecodable, l.Address.Hex())
+	}
+	name, data, err := s.dec.decode(kind, l)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errUndecodable, err)
+	}
+	ev := &Event{Contract: kind, Name: name, Data: data, Log: l}
+	if kind == Pool {
+		ev.PoolID = s.pools[l.Address]
+	} else {
+		ev.PoolID = poolIDArg(data)
+	}
+	if ev.Time, err = s.blockTime(ctx, l); err != nil {
+		return nil, err
+	}
+	s.head = max(s.head, l.BlockNumber)
+	ev.Confirmations = s.head - l.BlockNumber + 1
+	return ev, nil
+}
+
+func (s *Stream) blockTime(ctx context.Context, l types.Log) (uint64, error) {
+	if t, ok := s.times[l.BlockHash]; ok {
+		return t, nil
+	}
+	h, err := s.client.HeaderByHash(ctx, l.BlockHash)
+	if err != nil {
+		if l.Removed {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("stream: header %s: %w", l.BlockHash.Hex(), err)
+	}
+	if len(s.times) >= timeCacheSize {
+		clear(s.times)
+	}
+	s.times[l.BlockHash] = h.Time
+	return h.Time, nil
+}
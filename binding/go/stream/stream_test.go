@@ -0,0 +1,238 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/testlog"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	factory = common.HexToAddress("0xf0")
+	router  = common.HexToAddress("0xa0")
+	wcross  = common.HexToAddress("0xc0")
+	pool1   = common.HexToAddress("0x01")
+	pool2   = common.HexToAddress("0x02")
+	user    = common.HexToAddress("0xbeef")
+)
+
+type session struct {
+	addrs []common.Address
+	sink  chan<- types.Log
+	fail  chan error
+}
+
+// fakeNode serves FilterLogs from its mined logs and hands every
+// subscription to the test as a session. Block n has hash {n} and time
+// 1000+n.
+type fakeNode struct {
+	mu       sync.Mutex
+	logs     []types.Log
+	head     uint64
+	sessions chan *session
+}
+
+func (n *fakeNode) mine(l types.Log, block uint64, index uint) types.Log {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	l.BlockNumber, l.Index = block, index
+	l.BlockHash = common.Hash{byte(block)}
+	l.TxHash = common.Hash{byte(block), byte(index)}
+	n.logs = append(n.logs, l)
+	n.head = max(n.head, block)
+	return l
+}
+
+func (n *fakeNode) BlockNumber(context.Context) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.head, nil
+}
+
+func (n *fakeNode) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	return &types.Header{Time: 1000 + uint64(hash[0])}, nil
+}
+
+func (n *fakeNode) FilterLogs(_ context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var out []types.Log
+	for _, l := range n.logs {
+		if l.BlockNumber >= q.FromBlock.Uint64() && l.BlockNumber <= q.ToBlock.Uint64() && slices.Contains(q.Addresses, l.Address) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (n *fakeNode) SubscribeFilterLogs(_ context.Context, q ethereum.FilterQuery, sink chan<- types.Log) (ethereum.Subscription, error) {
+	s := &session{addrs: q.Addresses, sink: sink, fail: make(chan error, 1)}
+	n.sessions <- s
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case err := <-s.fail:
+			return err
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
+
+func deposited(t *testing.T, addr common.Address, amount int64) types.Log {
+	return testlog.Event(t, binding.CrossGameRewardPoolMetaData, addr, "Deposited",
+		[]common.Hash{testlog.Topic(user)}, big.NewInt(amount))
+}
+
+func next(t *testing.T, s *Stream) *Event {
+	t.Helper()
+	select {
+	case ev := <-s.C():
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return nil
+	}
+}
+
+func TestStreamFollowsNewPoolsAndReconnects(t *testing.T) {
+	node := &fakeNode{sessions: make(chan *session, 4)}
+	node.mine(deposited(t, pool1, 5), 1, 0)
+	node.mine(testlog.Event(t, binding.CrossGameRewardMetaData, factory, "PoolCreated",
+		[]common.Hash{common.BigToHash(big.NewInt(2)), testlog.Topic(pool2), testlog.Topic(wcross)}, "two"), 2, 0)
+	node.mine(deposited(t, pool2, 6), 2, 1)
+	node.mine(testlog.Event(t, binding.CrossGameRewardRouterMetaData, router, "DepositedNative",
+		[]common.Hash{testlog.Topic(user), common.BigToHash(big.NewInt(2))}, big.NewInt(7)), 3, 0)
+
+	var errs []error
+	s := newStream(node,
+		map[common.Address]Contract{factory: Factory, router: Router, wcross: WCROSS},
+		func(context.Context) (map[common.Address]*big.Int, error) {
+			return map[common.Address]*big.Int{pool1: big.NewInt(1)}, nil
+		},
+		Config{From: 1, MinBackoff: time.Millisecond, OnError: func(err error) { errs = append(errs, err) }})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	ev := next(t, s)
+	if d, ok := ev.Data.(*binding.CrossGameRewardPoolDeposited); !ok || d.Amount.Int64() != 5 {
+		t.Fatalf("first event = %+v", ev)
+	}
+	if ev.Contract != Pool || ev.PoolID.Int64() != 1 || ev.Time != 1001 || ev.Confirmations != 3 {
+		t.Fatalf("annotations = %v pool %v time %d conf %d", ev.Contract, ev.PoolID, ev.Time, ev.Confirmations)
+	}
+	if ev := next(t, s); ev.Name != "PoolCreated" || ev.PoolID.Int64() != 2 {
+		t.Fatalf("second event = %s pool %v", ev.Name, ev.PoolID)
+	}
+
+	// PoolCreated reopens the subscription with the new pool included, and
+	// its deposit in the same block is replayed.
+	<-node.sessions
+	second := <-node.sessions
+	if len(second.addrs) != 5 || !slices.Contains(second.addrs, pool2) {
+		t.Fatalf("resubscribed to %v", second.addrs)
+	}
+	if ev := next(t, s); ev.Name != "Deposited" || ev.PoolID.Int64() != 2 {
+		t.Fatalf("third event = %s pool %v", ev.Name, ev.PoolID)
+	}
+	ev = next(t, s)
+	if _, ok := ev.Data.(*binding.CrossGameRewardRouterDepositedNative); !ok || ev.Contract != Router || ev.PoolID.Int64() != 2 {
+		t.Fatalf("router event = %+v", ev)
+	}
+
+	// A dropped connection is retried and the gap backfilled.
+	node.mine(deposited(t, pool2, 8), 4, 0)
+	second.fail <- errors.New("websocket: close 1006")
+	third := <-node.sessions
+	if ev := next(t, s); ev.Log.BlockNumber != 4 || ev.Confirmations != 1 {
+		t.Fatalf("backfilled event at block %d with %d confirmations", ev.Log.BlockNumber, ev.Confirmations)
+	}
+
+	// The node replays a log already delivered, then retracts it.
+	l := node.logs[len(node.logs)-1]
+	third.sink <- l
+	l.Removed = true
+	third.sink <- l
+	if ev := next(t, s); !ev.Log.Removed || ev.Log.BlockNumber != 4 {
+		t.Fatalf("expected retraction of block 4, got %+v", ev.Log)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v", err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("OnError called %d times, want 1", len(errs))
+	}
+}
+
+func TestDecoderCoversEveryEvent(t *testing.T) {
+	d, err := newDecoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, meta := range map[Contract]testlog.ABIProvider{
+		Factory: binding.CrossGameRewardMetaData,
+		Pool:    binding.CrossGameRewardPoolMetaData,
+		Router:  binding.CrossGameRewardRouterMetaData,
+		WCROSS:  binding.WCROSSMetaData,
+	} {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(d.parsers[kind]), len(parsed.Events); got != want {
+			t.Errorf("%s: %d parsers for %d events", kind, got, want)
+		}
+	}
+	name, data, err := d.decode(Pool, types.Log{Topics: []common.Hash{{0x01}}})
+	if name != "" || data != nil || err != nil {
+		t.Fatalf("unknown topic decoded to %q %v %v", name, data, err)
+	}
+}
+
+func TestStreamSkipsUndecodableLog(t *testing.T) {
+	node := &fakeNode{sessions: make(chan *session, 4)}
+	bad := deposited(t, pool1, 5)
+	bad.Data = bad.Data[:7]
+	node.mine(bad, 1, 0)
+	node.mine(deposited(t, pool1, 6), 2, 0)
+
+	var errs []error
+	s := newStream(node, map[common.Address]Contract{factory: Factory},
+		func(context.Context) (map[common.Address]*big.Int, error) {
+			return map[common.Address]*big.Int{pool1: big.NewInt(1)}, nil
+		},
+		Config{From: 1, MinBackoff: time.Millisecond, OnError: func(err error) { errs = append(errs, err) }})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	if ev := next(t, s); ev.Log.BlockNumber != 2 {
+		t.Fatalf("delivered block %d, want the valid log of block 2", ev.Log.BlockNumber)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v", err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errUndecodable) {
+		t.Fatalf("OnError got %v, want one undecodable log", errs)
+	}
+	if n := len(node.sessions); n != 1 {
+		t.Fatalf("%d subscriptions, want 1", n)
+	}
+	if got := s.Cursor(); got != 3 {
+		t.Fatalf("cursor = %d, want 3", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package subscription keeps generated Watch* subscriptions alive across
+// node restarts and dropped websockets.
+//
+// A Subscription wraps the Watch*/Filter* pair of one event. When the live
+// subscription fails it reconnects with exponential backoff and replays the
+// blocks it missed through Filter*, so consumers see every event exactly
+// once, in block order, on a single typed channel:
+//
+//	sub := subscription.New(net.Client, subscription.Pair[*binding.CrossGameRewardPoolDeposited]{
+//		Watch: func(o *bind.WatchOpts, ch chan<- *binding.CrossGameRewardPoolDeposited) (event.Subscription, error) {
+//			return pool.WatchDeposited(o, ch, nil)
+//		},
+//		Filter: func(o *bind.FilterOpts) (subscription.Iterator, error) {
+//			return pool.FilterDeposited(o, nil)
+//		},
+//	}, subscription.Config{})
+//	go sub.Run(ctx)
+//	for ev := range sub.C() { ... }
+//
+// Events are deduplicated by (block hash, tx hash, log index). A log removed
+// by a reorg is a distinct delivery, so retractions still reach the
+// consumer, and so does the same log re-included in another block.
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/follow"
+)
+
+// Defaults for zero Config fields.
+const (
+	DefaultMinBackoff  = follow.DefaultMinBackoff
+	DefaultMaxBackoff  = follow.DefaultMaxBackoff
+	DefaultChunkSize   = follow.DefaultChunkSize
+	DefaultDedupeDepth = follow.DefaultDedupeDepth
+)
+
+// Iterator is the common surface of the generated *Iterator types returned
+// by Filter* methods. Their Event field is read by reflection.
+type Iterator interface {
+	Next() bool
+	Error() error
+	Close() error
+}
+
+// Pair binds the Watch* and Filter* methods of one event. Filter must
+// apply the same indexed-argument filter as Watch.
+type Pair[T any] struct {
+	Watch  func(opts *bind.WatchOpts, sink chan<- T) (event.Subscription, error)
+	Filter func(opts *bind.FilterOpts) (Iterator, error)
+}
+
+// Config tunes a Subscription. The zero value starts at the current head
+// with the default backoff, chunk size and dedupe depth.
+type Config struct {
+	// From is the first block to deliver; zero starts at the head.
+	From uint64
+	// MinBackoff and MaxBackoff bound the reconnect delay, which doubles
+	// after every failed attempt.
+	MinBackoff, MaxBackoff time.Duration
+	// ChunkSize bounds the block range of each backfill query.
+	ChunkSize uint64
+	// DedupeDepth is how many blocks behind the newest delivery event keys
+	// are remembered.
+	DedupeDepth uint64
+	// OnError receives every connection failure before the retry.
+	OnError func(error)
+}
+
+// Subscription delivers the events of one Pair.
+type Subscription[T any] struct {
+	out  chan T
+	loop *follow.Loop[T]
+}
+
+// New returns a Subscription for pair; call Run to start it. T must be a
+// generated event type, a pointer to a struct with a Raw types.Log field.
+func New[T any](head ethereum.BlockNumberReader, pair Pair[T], cfg Config) *Subscription[T] {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("subscription: %v is not a pointer to a struct", t))
+	}
+	if f, ok := t.Elem().FieldByName("Raw"); !ok || f.Type != reflect.TypeFor[types.Log]() {
+		panic(fmt.Sprintf("subscription: %v has no Raw types.Log field", t))
+	}
+	s := &Subscription[T]{out: make(chan T)}
+	s.loop = follow.New(follow.Source[T]{
+		Head: head,
+		Watch: func(ctx context.Context, sink chan<- T) (ethereum.Subscription, error) {
+			return pair.Watch(&bind.WatchOpts{Context: ctx}, sink)
+		},
+		Filter: func(ctx context.Context, from, to uint64) ([]T, error) {
+			it, err := pair.Filter(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+			if err != nil {
+				return nil, err
+			}
+			return drain[T](it)
+		},
+		Log: raw[T],
+	}, follow.Config{
+		Name:        "subscription",
+		From:        cfg.From,
+		MinBackoff:  cfg.MinBackoff,
+		MaxBackoff:  cfg.MaxBackoff,
+		ChunkSize:   cfg.ChunkSize,
+		DedupeDepth: cfg.DedupeDepth,
+		OnError:     cfg.OnError,
+	}, s.send)
+	return s
+}
+
+// C returns the channel events are delivered on. It is closed when Run
+// returns.
+func (s *Subscription[T]) C() <-chan T {
+	return s.out
+}
+
+// Cursor returns the block to resume from after a restart: passing it as
+// Config.From redelivers at most the events of that one block.
+func (s *Subscription[T]) Cursor() uint64 {
+	return s.loop.Cursor()
+}
+
+// Run delivers events until ctx is done, reconnecting on failure.
+func (s *Subscription[T]) Run(ctx context.Context) error {
+	defer close(s.out)
+	return s.loop.Run(ctx)
+}
+
+func (s *Subscription[T]) send(ctx context.Context, v T) error {
+	select {
+	case s.out <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func raw[T any](v T) types.Log {
+	return reflect.ValueOf(v).Elem().FieldByName("Raw").Interface().(types.Log)
+}
+
+func drain[T any](it Iterator) ([]T, error) {
+	defer it.Close()
+	field := reflect.ValueOf(it).Elem().FieldByName("Event")
+	if !field.IsValid() {
+		return nil, fmt.Errorf("subscription: %T has no Event field", it)
+	}
+	var out []T
+	for it.Next() {
+		out = append(out, field.Interface().(T))
+	}
+	return out, it.Error()
+}
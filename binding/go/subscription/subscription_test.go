@@ -0,0 +1,188 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+type fakeEvent struct {
+	Name string
+	Raw  types.Log
+}
+
+type fakeIterator struct {
+	evs   []*fakeEvent
+	Event *fakeEvent
+}
+
+func (it *fakeIterator) Next() bool {
+	if len(it.evs) == 0 {
+		return false
+	}
+	it.Event, it.evs = it.evs[0], it.evs[1:]
+	return true
+}
+func (it *fakeIterator) Error() error { return nil }
+func (it *fakeIterator) Close() error { return nil }
+
+type session struct {
+	sink chan<- *fakeEvent
+	fail chan error
+}
+
+// fakeChain serves Filter from its recorded events and hands every Watch
+// call to the test as a session.
+type fakeChain struct {
+	mu       sync.Mutex
+	events   []*fakeEvent
+	head     uint64
+	sessions chan *session
+}
+
+func (c *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head, nil
+}
+
+func (c *fakeChain) mine(name string, block uint64, index uint) *fakeEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ev := &fakeEvent{Name: name, Raw: types.Log{BlockNumber: block, Index: index, TxHash: common.Hash{byte(block), byte(index)}}}
+	c.events = append(c.events, ev)
+	c.head = max(c.head, block)
+	return ev
+}
+
+func (c *fakeChain) pair() Pair[*fakeEvent] {
+	return Pair[*fakeEvent]{
+		Watch: func(_ *bind.WatchOpts, sink chan<- *fakeEvent) (event.Subscription, error) {
+			s := &session{sink: sink, fail: make(chan error, 1)}
+			sub := event.NewSubscription(func(quit <-chan struct{}) error {
+				select {
+				case err := <-s.fail:
+					return err
+				case <-quit:
+					return nil
+				}
+			})
+			c.sessions <- s
+			return sub, nil
+		},
+		Filter: func(o *bind.FilterOpts) (Iterator, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			it := &fakeIterator{}
+			// Newest first, to check that backfill sorts.
+			for i := len(c.events) - 1; i >= 0; i-- {
+				if b := c.events[i].Raw.BlockNumber; b >= o.Start && b <= *o.End {
+					it.evs = append(it.evs, c.events[i])
+				}
+			}
+			return it, nil
+		},
+	}
+}
+
+func expect(t *testing.T, sub *Subscription[*fakeEvent], names ...string) {
+	t.Helper()
+	for _, want := range names {
+		select {
+		case ev := <-sub.C():
+			if ev.Name != want {
+				t.Fatalf("got %s, want %s", ev.Name, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestReconnectBackfillsGap(t *testing.T) {
+	chain := &fakeChain{sessions: make(chan *session, 1)}
+	chain.mine("a", 1, 0)
+	chain.mine("c", 3, 0)
+	chain.mine("b", 2, 0)
+
+	var errs []error
+	sub := New(chain, chain.pair(), Config{From: 1, MinBackoff: time.Millisecond, OnError: func(err error) { errs = append(errs, err) }})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sub.Run(ctx) }()
+
+	first := <-chain.sessions
+	expect(t, sub, "a", "b", "c")
+	first.sink <- chain.mine("d", 4, 0)
+	expect(t, sub, "d")
+
+	// Events mined while the connection is down arrive through backfill.
+	chain.mine("f", 5, 1)
+	chain.mine("e", 5, 0)
+	first.fail <- errors.New("websocket: close 1006")
+	second := <-chain.sessions
+	expect(t, sub, "e", "f")
+	if got := sub.Cursor(); got != 6 {
+		t.Fatalf("cursor = %d, want 6", got)
+	}
+
+	// The node may replay events already backfilled.
+	second.sink <- chain.events[len(chain.events)-1]
+	second.sink <- chain.mine("g", 6, 0)
+	expect(t, sub, "g")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v", err)
+	}
+	if _, open := <-sub.C(); open {
+		t.Fatal("channel not closed")
+	}
+	if len(errs) != 1 {
+		t.Fatalf("OnError called %d times, want 1", len(errs))
+	}
+}
+
+func TestRemovedLogIsDelivered(t *testing.T) {
+	chain := &fakeChain{sessions: make(chan *session, 1)}
+	sub := New(chain, chain.pair(), Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sub.Run(ctx)
+
+	s := <-chain.sessions
+	ev := chain.mine("x", 1, 0)
+	s.sink <- ev
+	removed := *ev
+	removed.Name = "x-removed"
+	removed.Raw.Removed = true
+	s.sink <- &removed
+	expect(t, sub, "x", "x-removed")
+}
+
+func TestNewRejectsTypesWithoutRaw(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic")
+		}
+	}()
+	New(&fakeChain{}, Pair[*struct{ X int }]{}, Config{})
+}
+
+func TestNewRejectsNonPointerTypes(t *testing.T) {
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "is not a pointer to a struct") {
+			t.Fatalf("panic %q", msg)
+		}
+	}()
+	New(&fakeChain{}, Pair[fakeEvent]{}, Config{})
+}
@@ -0,0 +1,192 @@
+// Package sweeper recovers reclaimable reward balances from every pool.
+//
+// Pools accumulate reclaimable funds in two ways: rewards that arrive while
+// nothing is deposited, and rewards sent to a token after it was removed.
+// Both show up in getReclaimableAmount(token). Scan lists them across all
+// active and removed reward tokens; Sweep calls reclaimFromPool on the
+// factory for each and reconciles the receipt against the emitted
+// ReclaimedFromPool and TokensReclaimed events.
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+// ErrNotManager is returned when the sending account lacks MANAGER_ROLE.
+// It is the error config.Network.RequireRole wraps.
+var ErrNotManager = config.ErrMissingRole
+
+// Candidate is a reclaimable balance in one pool.
+type Candidate struct {
+	PoolID  *big.Int
+	Pool    common.Address
+	Token   common.Address
+	Removed bool
+	Amount  *big.Int
+}
+
+// Result is the outcome of sweeping one candidate.
+type Result struct {
+	Candidate
+	TxHash common.Hash
+	// Reclaimed is the amount in the factory's ReclaimedFromPool event.
+	Reclaimed *big.Int
+	// Transferred is the amount in the pool's TokensReclaimed event.
+	Transferred *big.Int
+	// Discrepancies lists every way the receipt disagrees with the request.
+	Discrepancies []string
+	Err           error
+}
+
+// OK reports whether the sweep was mined and reconciled cleanly.
+func (r *Result) OK() bool {
+	return r.Err == nil && len(r.Discrepancies) == 0
+}
+
+// Thresholds sets the minimum amount worth sweeping, per token.
+type Thresholds struct {
+	Default *big.Int
+	ByToken map[common.Address]*big.Int
+}
+
+// For returns the threshold that applies to token.
+func (t Thresholds) For(token common.Address) *big.Int {
+	if v, ok := t.ByToken[token]; ok {
+		return v
+	}
+	if t.Default != nil {
+		return t.Default
+	}
+	return common.Big1
+}
+
+// Sweeper scans and sweeps reclaimable amounts on one network.
+type Sweeper struct {
+	net        *config.Network
+	thresholds Thresholds
+}
+
+// New returns a Sweeper for net.
+func New(net *config.Network, thresholds Thresholds) *Sweeper {
+	return &Sweeper{net: net, thresholds: thresholds}
+}
+
+// Scan returns every pool/token pair whose reclaimable amount meets the
+// threshold. Zero amounts are never reported.
+func (s *Sweeper) Scan(opts *bind.CallOpts) ([]Candidate, error) {
+	pools, err := s.net.Pools(opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []Candidate
+	for _, p := range pools {
+		active, err := p.Pool.GetRewardTokens(opts)
+		if err != nil {
+			return nil, fmt.Errorf("sweeper: pool %s reward tokens: %w", p.ID, err)
+		}
+		removed, err := p.Pool.GetRemovedRewardTokens(opts)
+		if err != nil {
+			return nil, fmt.Errorf("sweeper: pool %s removed reward tokens: %w", p.ID, err)
+		}
+		for i, token := range append(active, removed...) {
+			amount, err := p.Pool.GetReclaimableAmount(opts, token)
+			if err != nil {
+				return nil, fmt.Errorf("sweeper: pool %s reclaimable %s: %w", p.ID, token, err)
+			}
+			if amount.Sign() == 0 || amount.Cmp(s.thresholds.For(token)) < 0 {
+				continue
+			}
+			out = append(out, Candidate{
+				PoolID:  p.ID,
+				Pool:    p.Address,
+				Token:   token,
+				Removed: i >= len(active),
+				Amount:  amount,
+			})
+		}
+	}
+	return out, nil
+}
+
+// Sweep reclaims each candidate to treasury, one transaction per candidate.
+// A failing candidate does not stop the rest; its error is kept in the
+// result. The returned error is set only when nothing could be attempted.
+func (s *Sweeper) Sweep(ctx context.Context, tm *txmgr.Manager, treasury common.Address, cands []Candidate) ([]Result, error) {
+	if treasury == (common.Address{}) {
+		return nil, errors.New("sweeper: treasury address is required")
+	}
+	if err := s.net.RequireRole(tm.CallOpts(ctx), config.RoleManager, tm.From()); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(cands))
+	for _, c := range cands {
+		r := Result{Candidate: c}
+		receipt, err := tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+			return s.net.Factory.ReclaimFromPool(o, c.PoolID, c.Token, treasury)
+		})
+		if receipt != nil {
+			r.TxHash = receipt.TxHash
+		}
+		if err != nil {
+			r.Err = err
+			results = append(results, r)
+			continue
+		}
+		s.reconcile(&r, receipt, treasury)
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (s *Sweeper) reconcile(r *Result, receipt *types.Receipt, treasury common.Address) {
+	pool, err := s.net.Pool(r.Pool)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	for _, l := range receipt.Logs {
+		switch l.Address {
+		case s.net.FactoryAddress:
+			ev, err := s.net.Factory.ParseReclaimedFromPool(*l)
+			if err != nil {
+				continue
+			}
+			r.Reclaimed = ev.Amount
+			if ev.PoolId.Cmp(r.PoolID) != 0 || ev.Token != r.Token || ev.To != treasury {
+				r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("ReclaimedFromPool is for pool %s token %s to %s", ev.PoolId, ev.Token, ev.To))
+			}
+		case r.Pool:
+			ev, err := pool.ParseTokensReclaimed(*l)
+			if err != nil {
+				continue
+			}
+			r.Transferred = ev.Amount
+			if ev.Token != r.Token || ev.To != treasury {
+				r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("TokensReclaimed is for token %s to %s", ev.Token, ev.To))
+			}
+		}
+	}
+	switch {
+	case r.Reclaimed == nil:
+		r.Discrepancies = append(r.Discrepancies, "missing ReclaimedFromPool event")
+	case r.Transferred == nil:
+		r.Discrepancies = append(r.Discrepancies, "missing TokensReclaimed event")
+	case r.Reclaimed.Cmp(r.Transferred) != 0:
+		r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("factory reported %s but pool transferred %s", r.Reclaimed, r.Transferred))
+	case r.Transferred.Cmp(r.Amount) < 0:
+		// More may have arrived between scan and sweep; less means something
+		// else drained the reclaimable balance first.
+		r.Discrepancies = append(r.Discrepancies, fmt.Sprintf("transferred %s is below scanned %s", r.Transferred, r.Amount))
+	}
+}
@@ -0,0 +1,96 @@
+package sweeper
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/internal/testlog"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+)
+
+var (
+	factoryAddr  = common.HexToAddress("0xf0")
+	poolAddr     = common.HexToAddress("0xb0")
+	tokenAddr    = common.HexToAddress("0x70")
+	treasuryAddr = common.HexToAddress("0x7e")
+)
+
+func testSweeper(t *testing.T) *Sweeper {
+	t.Helper()
+	factory, err := binding.NewCrossGameReward(factoryAddr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(&config.Network{FactoryAddress: factoryAddr, Factory: factory}, Thresholds{})
+}
+
+func receipt(t *testing.T, reclaimed, transferred int64) *types.Receipt {
+	return &types.Receipt{Logs: []*types.Log{
+		ref(testlog.Event(t, binding.CrossGameRewardPoolMetaData, poolAddr, "TokensReclaimed",
+			[]common.Hash{testlog.Topic(tokenAddr), testlog.Topic(treasuryAddr)},
+			big.NewInt(transferred))),
+		ref(testlog.Event(t, binding.CrossGameRewardMetaData, factoryAddr, "ReclaimedFromPool",
+			[]common.Hash{common.BigToHash(big.NewInt(1)), testlog.Topic(tokenAddr), testlog.Topic(treasuryAddr)},
+			big.NewInt(reclaimed))),
+	}}
+}
+
+// ref returns a pointer to a copy of l, as receipts hold logs.
+func ref(l types.Log) *types.Log { return &l }
+
+func candidate(amount int64) Result {
+	return Result{Candidate: Candidate{PoolID: big.NewInt(1), Pool: poolAddr, Token: tokenAddr, Amount: big.NewInt(amount)}}
+}
+
+func TestReconcileClean(t *testing.T) {
+	s := testSweeper(t)
+	r := candidate(100)
+	s.reconcile(&r, receipt(t, 120, 120), treasuryAddr)
+	if !r.OK() {
+		t.Fatalf("unexpected discrepancies: %v (err %v)", r.Discrepancies, r.Err)
+	}
+	if r.Reclaimed.Int64() != 120 || r.Transferred.Int64() != 120 {
+		t.Fatalf("amounts not captured: %v %v", r.Reclaimed, r.Transferred)
+	}
+}
+
+func TestReconcileMismatch(t *testing.T) {
+	s := testSweeper(t)
+	r := candidate(100)
+	s.reconcile(&r, receipt(t, 100, 90), treasuryAddr)
+	if r.OK() || !strings.Contains(r.Discrepancies[0], "factory reported 100") {
+		t.Fatalf("want amount mismatch, got %v", r.Discrepancies)
+	}
+
+	r = candidate(100)
+	s.reconcile(&r, receipt(t, 100, 100), common.HexToAddress("0xbad"))
+	if len(r.Discrepancies) != 2 {
+		t.Fatalf("want recipient mismatch on both events, got %v", r.Discrepancies)
+	}
+}
+
+func TestReconcileMissingEvent(t *testing.T) {
+	s := testSweeper(t)
+	r := candidate(100)
+	rcpt := receipt(t, 100, 100)
+	rcpt.Logs = rcpt.Logs[:1]
+	s.reconcile(&r, rcpt, treasuryAddr)
+	if len(r.Discrepancies) != 1 || r.Discrepancies[0] != "missing ReclaimedFromPool event" {
+		t.Fatalf("want missing event, got %v", r.Discrepancies)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	th := Thresholds{Default: big.NewInt(10), ByToken: map[common.Address]*big.Int{tokenAddr: big.NewInt(5)}}
+	if th.For(tokenAddr).Int64() != 5 || th.For(poolAddr).Int64() != 10 {
+		t.Fatal("wrong threshold selection")
+	}
+	if (Thresholds{}).For(tokenAddr).Int64() != 1 {
+		t.Fatal("zero-value thresholds must still skip empty balances")
+	}
+}
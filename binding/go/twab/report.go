@@ -0,0 +1,29 @@
+package twab
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes one row per entry.
+func (s *Snapshot) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"pool_id", "pool", "account", "average", "weighted", "held", "end_balance"})
+	for _, e := range s.Entries {
+		cw.Write([]string{
+			strconv.FormatUint(e.PoolID, 10), e.Pool.Hex(), e.Account.Hex(),
+			e.Average.String(), e.Weighted.String(), strconv.FormatUint(e.Held, 10), e.EndBalance.String(),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the snapshot, window included, as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
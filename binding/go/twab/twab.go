@@ -0,0 +1,290 @@
+// Package twab computes time-weighted average balances (TWAB) of pool
+// depositors from indexed Deposited and Withdrawn events.
+//
+// A Window spans either a time range, weighting every balance by the
+// seconds it was held, or a block range, weighting it by the blocks it was
+// held. The balance at an instant is the one after every block mined at or
+// before it. Results can be exported as CSV or JSON and reconciled against
+// the pools' balances(user) at the end of the window.
+package twab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/asof"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+var (
+	// ErrInvalidWindow is returned for windows that end before they start.
+	ErrInvalidWindow = errors.New("twab: invalid window")
+	// ErrIndexBehind is returned when the index does not cover the window.
+	ErrIndexBehind = errors.New("twab: index does not cover the window")
+	// ErrUnknownPool is returned for pool IDs the index has not seen.
+	ErrUnknownPool = errors.New("twab: pool not in index")
+)
+
+// Weighting selects the unit balances are weighted by.
+type Weighting string
+
+// Weightings.
+const (
+	ByTime  Weighting = "time"
+	ByBlock Weighting = "block"
+)
+
+// Window is the range averaged over.
+type Window struct {
+	Weighting Weighting `json:"weighting"`
+	// Start and End are Unix seconds when weighting by time and block
+	// numbers when weighting by block. Both ends are inclusive: a time
+	// window spans End-Start seconds and a block window End-Start+1
+	// blocks.
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	// EndBlock is the block whose state is current at End, used to check
+	// index coverage and to reconcile.
+	EndBlock uint64 `json:"endBlock"`
+}
+
+// NewWindow resolves two points in chain history into a Window.
+func NewWindow(ctx context.Context, r *asof.Reader, w Weighting, from, to asof.Point) (Window, error) {
+	startBlock, startTime, err := r.Block(ctx, from)
+	if err != nil {
+		return Window{}, err
+	}
+	endBlock, endTime, err := r.Block(ctx, to)
+	if err != nil {
+		return Window{}, err
+	}
+	win := Window{Weighting: w, EndBlock: endBlock}
+	switch w {
+	case ByTime:
+		// A point given as a time keeps that exact time rather than the
+		// time of the block it resolved to.
+		win.Start, win.End = unix(from.Time, startTime), unix(to.Time, endTime)
+	case ByBlock:
+		win.Start, win.End = startBlock, endBlock
+	default:
+		return Window{}, fmt.Errorf("%w: weighting %q", ErrInvalidWindow, w)
+	}
+	return win, win.validate()
+}
+
+func unix(exact, block time.Time) uint64 {
+	if !exact.IsZero() {
+		return uint64(exact.Unix())
+	}
+	return uint64(block.Unix())
+}
+
+func (w Window) validate() error {
+	if w.Weighting != ByTime && w.Weighting != ByBlock {
+		return fmt.Errorf("%w: weighting %q", ErrInvalidWindow, w.Weighting)
+	}
+	if w.End < w.Start {
+		return fmt.Errorf("%w: ends at %d before it starts at %d", ErrInvalidWindow, w.End, w.Start)
+	}
+	return nil
+}
+
+// span returns the window as a half-open range of weight units.
+func (w Window) span() (from, to uint64) {
+	if w.Weighting == ByBlock {
+		return w.Start, w.End + 1
+	}
+	return w.Start, w.End
+}
+
+// at returns the position of ev on the window's axis.
+func (w Window) at(ev *indexer.Event) uint64 {
+	if w.Weighting == ByBlock {
+		return ev.Block
+	}
+	return ev.Time
+}
+
+// Entry is one account's average balance in one pool.
+type Entry struct {
+	PoolID  uint64         `json:"poolId"`
+	Pool    common.Address `json:"pool"`
+	Account common.Address `json:"account"`
+	// Average is Weighted divided by the window length, rounded down.
+	Average *big.Int `json:"average"`
+	// Weighted is the balance integrated over the window, in
+	// balance-seconds or balance-blocks.
+	Weighted *big.Int `json:"weighted"`
+	// Held is the number of seconds or blocks the balance was non-zero.
+	Held       uint64   `json:"held"`
+	EndBalance *big.Int `json:"endBalance"`
+}
+
+// Snapshot is the result of Compute.
+type Snapshot struct {
+	Window  Window   `json:"window"`
+	Pools   []uint64 `json:"pools"`
+	Entries []Entry  `json:"entries"`
+}
+
+// Compute averages the balances of every account in the given pools (all
+// indexed pools if none) over w. Accounts with no balance during the window
+// and none at its end are omitted. Entries are ordered by pool, then by
+// descending average.
+func Compute(store *indexer.Store, w Window, poolIDs []uint64) (*Snapshot, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	if store.Head() < w.EndBlock {
+		return nil, fmt.Errorf("%w: index head %d, window ends at block %d", ErrIndexBehind, store.Head(), w.EndBlock)
+	}
+	pools, err := selectPools(store, poolIDs)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Window: w, Entries: []Entry{}}
+	for _, p := range pools {
+		snap.Pools = append(snap.Pools, p.id)
+		evs := store.Events(indexer.Filter{
+			Pools:   []common.Address{p.addr},
+			Kinds:   []indexer.Kind{indexer.KindDeposited, indexer.KindWithdrawn},
+			ToBlock: w.EndBlock,
+		})
+		snap.Entries = append(snap.Entries, average(w, p.id, p.addr, evs)...)
+	}
+	return snap, nil
+}
+
+type poolRef struct {
+	id   uint64
+	addr common.Address
+}
+
+func selectPools(store *indexer.Store, ids []uint64) ([]poolRef, error) {
+	known := store.Pools()
+	var out []poolRef
+	for addr, id := range known {
+		if len(ids) == 0 || slices.Contains(ids, id) {
+			out = append(out, poolRef{id, addr})
+		}
+	}
+	if len(out) < len(ids) {
+		for _, id := range ids {
+			if !slices.ContainsFunc(out, func(p poolRef) bool { return p.id == id }) {
+				return nil, fmt.Errorf("%w: %d", ErrUnknownPool, id)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out, nil
+}
+
+// account is the running state of one account while integrating.
+type account struct {
+	balance  *big.Int
+	since    uint64
+	weighted *big.Int
+	held     uint64
+}
+
+// average integrates the balances changed by evs, which are in block order,
+// over the window.
+func average(w Window, poolID uint64, pool common.Address, evs []indexer.Event) []Entry {
+	from, to := w.span()
+	accts := make(map[common.Address]*account)
+	for i := range evs {
+		ev := &evs[i]
+		x := w.at(ev)
+		if w.Weighting == ByTime && x > w.End {
+			break
+		}
+		a := accts[ev.Account]
+		if a == nil {
+			a = &account{balance: new(big.Int), since: from, weighted: new(big.Int)}
+			accts[ev.Account] = a
+		}
+		a.advance(max(x, from))
+		switch ev.Kind {
+		case indexer.KindDeposited:
+			a.balance.Add(a.balance, ev.Amount)
+		case indexer.KindWithdrawn:
+			a.balance.Sub(a.balance, ev.Amount)
+		}
+	}
+
+	length := new(big.Int).SetUint64(to - from)
+	var out []Entry
+	for addr, a := range accts {
+		a.advance(to)
+		if a.weighted.Sign() == 0 && a.balance.Sign() == 0 {
+			continue
+		}
+		avg := new(big.Int).Set(a.balance)
+		if length.Sign() > 0 {
+			avg.Quo(a.weighted, length)
+		}
+		out = append(out, Entry{
+			PoolID: poolID, Pool: pool, Account: addr,
+			Average: avg, Weighted: a.weighted, Held: a.held, EndBalance: a.balance,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := out[i].Average.Cmp(out[j].Average); c != 0 {
+			return c > 0
+		}
+		return out[i].Account.Cmp(out[j].Account) < 0
+	})
+	return out
+}
+
+// advance accrues the current balance up to x.
+func (a *account) advance(x uint64) {
+	if x <= a.since {
+		return
+	}
+	if a.balance.Sign() > 0 {
+		d := x - a.since
+		a.weighted.Add(a.weighted, new(big.Int).Mul(a.balance, new(big.Int).SetUint64(d)))
+		a.held += d
+	}
+	a.since = x
+}
+
+// Mismatch is an account whose indexed end balance differs from the pool.
+type Mismatch struct {
+	PoolID  uint64         `json:"poolId"`
+	Account common.Address `json:"account"`
+	Indexed *big.Int       `json:"indexed"`
+	OnChain *big.Int       `json:"onChain"`
+}
+
+// Reconcile compares every entry's end balance with balances(account) read
+// from its pool at the window's end block. Reading a past block needs an
+// archive node.
+func Reconcile(ctx context.Context, net *config.Network, snap *Snapshot) ([]Mismatch, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(snap.Window.EndBlock)}
+	var out []Mismatch
+	for _, e := range snap.Entries {
+		pool, err := net.Pool(e.Pool)
+		if err != nil {
+			return nil, err
+		}
+		bal, err := pool.Balances(opts, e.Account)
+		if err != nil {
+			return nil, fmt.Errorf("twab: balance of %s in pool %d: %w", e.Account, e.PoolID, err)
+		}
+		if bal.Cmp(e.EndBalance) != 0 {
+			out = append(out, Mismatch{PoolID: e.PoolID, Account: e.Account, Indexed: e.EndBalance, OnChain: bal})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package twab
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/indexer"
+)
+
+var (
+	pool = common.HexToAddress("0x01")
+	a    = common.HexToAddress("0xaa")
+	b    = common.HexToAddress("0xbb")
+	c    = common.HexToAddress("0xcc")
+)
+
+// testStore has blocks every 10 seconds from time 1000:
+//
+//	block 0: a deposits 100
+//	block 5: b deposits 50
+//	block 8: a withdraws 100
+//	block 12: c deposits 10
+func testStore(t *testing.T) *indexer.Store {
+	ev := func(kind indexer.Kind, acct common.Address, amount int64, block uint64) indexer.Event {
+		return indexer.Event{
+			Kind: kind, PoolID: 1, Pool: pool, Account: acct, Amount: big.NewInt(amount),
+			Block: block, Time: 1000 + 10*block,
+		}
+	}
+	s := indexer.NewStore()
+	if err := s.Append(20,
+		ev(indexer.KindDeposited, a, 100, 0),
+		ev(indexer.KindDeposited, b, 50, 5),
+		ev(indexer.KindWithdrawn, a, 100, 8),
+		ev(indexer.KindDeposited, c, 10, 12),
+	); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func entries(t *testing.T, s *Snapshot) map[common.Address]Entry {
+	t.Helper()
+	out := make(map[common.Address]Entry)
+	for _, e := range s.Entries {
+		out[e.Account] = e
+	}
+	return out
+}
+
+func check(t *testing.T, e Entry, avg, weighted, held, end int64) {
+	t.Helper()
+	if e.Average.Int64() != avg || e.Weighted.Int64() != weighted || e.Held != uint64(held) || e.EndBalance.Int64() != end {
+		t.Errorf("%s: avg %s weighted %s held %d end %s; want %d %d %d %d",
+			e.Account.Hex(), e.Average, e.Weighted, e.Held, e.EndBalance, avg, weighted, held, end)
+	}
+}
+
+func TestTimeWeighted(t *testing.T) {
+	snap, err := Compute(testStore(t), Window{Weighting: ByTime, Start: 1020, End: 1100, EndBlock: 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := entries(t, snap)
+	if len(got) != 2 {
+		t.Fatalf("entries: %+v", snap.Entries)
+	}
+	check(t, got[a], 75, 6000, 60, 0)  // 100 from 1020 to 1080
+	check(t, got[b], 31, 2500, 50, 50) // 50 from 1050 to 1100
+	if snap.Entries[0].Account != a {
+		t.Error("entries not ordered by average")
+	}
+}
+
+func TestBlockWeighted(t *testing.T) {
+	snap, err := Compute(testStore(t), Window{Weighting: ByBlock, Start: 2, End: 10, EndBlock: 10}, []uint64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := entries(t, snap)
+	check(t, got[a], 66, 600, 6, 0)  // blocks 2-7 of 2-10
+	check(t, got[b], 33, 300, 6, 50) // blocks 5-10
+}
+
+func TestComputeErrors(t *testing.T) {
+	s := testStore(t)
+	for _, tc := range []struct {
+		w     Window
+		pools []uint64
+		want  error
+	}{
+		{Window{Weighting: ByBlock, Start: 5, End: 4, EndBlock: 4}, nil, ErrInvalidWindow},
+		{Window{Weighting: "stake", End: 4}, nil, ErrInvalidWindow},
+		{Window{Weighting: ByBlock, End: 30, EndBlock: 30}, nil, ErrIndexBehind},
+		{Window{Weighting: ByBlock, End: 10, EndBlock: 10}, []uint64{1, 2}, ErrUnknownPool},
+	} {
+		if _, err := Compute(s, tc.w, tc.pools); !errors.Is(err, tc.want) {
+			t.Errorf("%+v %v: %v, want %v", tc.w, tc.pools, err, tc.want)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	snap, err := Compute(testStore(t), Window{Weighting: ByBlock, Start: 2, End: 10, EndBlock: 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := snap.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "pool_id,pool,account,average,weighted,held,end_balance" {
+		t.Fatalf("csv:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "1,"+pool.Hex()+","+a.Hex()+",66,600,6,0") {
+		t.Errorf("first row: %s", lines[1])
+	}
+}
@@ -0,0 +1,256 @@
+// Package txmgr signs, sends and confirms transactions for the operator
+// tooling.
+//
+// A Manager owns one signing key and hands out nonces locally, so several
+// transactions can be submitted back to back without waiting for each to
+// be mined. Every send is followed by a wait for the receipt at the
+// configured confirmation depth, and reverted receipts are returned as
+// errors.
+package txmgr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EnvPrivateKey is the environment variable KeyFromEnv reads.
+const EnvPrivateKey = "CGR_PRIVATE_KEY"
+
+var (
+	// ErrReverted is returned when a transaction is mined with a failed
+	// status.
+	ErrReverted = errors.New("txmgr: transaction reverted")
+	// ErrNonceGap is returned by SendAll for a transaction queued behind a
+	// nonce that was not broadcast.
+	ErrNonceGap = errors.New("txmgr: queued behind a nonce that was not broadcast")
+)
+
+// Backend is the chain access a Manager needs. *ethclient.Client satisfies it.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// SendFunc submits one transaction with the given options, typically a
+// generated *Transactor method.
+type SendFunc func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// Manager sends transactions from a single account.
+type Manager struct {
+	backend       Backend
+	key           *ecdsa.PrivateKey
+	from          common.Address
+	chainID       *big.Int
+	confirmations uint64
+	pollInterval  time.Duration
+
+	mu    sync.Mutex
+	nonce *uint64
+}
+
+// New returns a Manager signing with key. Receipts are awaited until they are
+// buried under confirmations blocks.
+func New(ctx context.Context, backend Backend, key *ecdsa.PrivateKey, confirmations uint64) (*Manager, error) {
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("txmgr: chain id: %w", err)
+	}
+	return &Manager{
+		backend:       backend,
+		key:           key,
+		from:          crypto.PubkeyToAddress(key.PublicKey),
+		chainID:       chainID,
+		confirmations: confirmations,
+		pollInterval:  2 * time.Second,
+	}, nil
+}
+
+// KeyFromEnv parses a hex private key from $CGR_PRIVATE_KEY.
+func KeyFromEnv() (*ecdsa.PrivateKey, error) {
+	v := os.Getenv(EnvPrivateKey)
+	if v == "" {
+		return nil, fmt.Errorf("txmgr: %s is not set", EnvPrivateKey)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(v, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("txmgr: %s: %w", EnvPrivateKey, err)
+	}
+	return key, nil
+}
+
+// From returns the sending account.
+func (m *Manager) From() common.Address {
+	return m.from
+}
+
+// Opts returns transaction options carrying the next local nonce. The nonce
+// is consumed; call Reset if the transaction is never broadcast.
+func (m *Manager) Opts(ctx context.Context) (*bind.TransactOpts, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(m.key, m.chainID)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := m.nextNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	return opts, nil
+}
+
+// SignHash signs a 32-byte digest, such as an EIP-712 hash, with the
+// sending key. V is 27 or 28, as contracts expect.
+func (m *Manager) SignHash(hash common.Hash) (v uint8, r, s [32]byte, err error) {
+	sig, err := crypto.Sign(hash[:], m.key)
+	if err != nil {
+		return 0, r, s, fmt.Errorf("txmgr: sign: %w", err)
+	}
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return sig[64] + 27, r, s, nil
+}
+
+// CallOpts returns call options simulating from the sending account.
+func (m *Manager) CallOpts(ctx context.Context) *bind.CallOpts {
+	return &bind.CallOpts{Context: ctx, From: m.from}
+}
+
+func (m *Manager) nextNonce(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.nonce == nil {
+		n, err := m.backend.PendingNonceAt(ctx, m.from)
+		if err != nil {
+			return 0, fmt.Errorf("txmgr: pending nonce: %w", err)
+		}
+		m.nonce = &n
+	}
+	n := *m.nonce
+	*m.nonce++
+	return n, nil
+}
+
+// Reset drops the cached nonce so the next transaction re-reads it from the
+// node.
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	m.nonce = nil
+	m.mu.Unlock()
+}
+
+// Submit sends a transaction without waiting for it to be mined.
+func (m *Manager) Submit(ctx context.Context, send SendFunc) (*types.Transaction, error) {
+	opts, err := m.Opts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := send(opts)
+	if err != nil {
+		// The nonce was never used, or the node rejected it; resync either way.
+		m.Reset()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Send submits a transaction and waits for its confirmed receipt.
+func (m *Manager) Send(ctx context.Context, send SendFunc) (*types.Receipt, error) {
+	tx, err := m.Submit(ctx, send)
+	if err != nil {
+		return nil, err
+	}
+	return m.Wait(ctx, tx)
+}
+
+// SendAll broadcasts transactions signed with nonces from Opts all at once,
+// then waits for them together. Nil entries are skipped; receipts[i] and
+// errs[i] belong to txs[i]. A transaction queued behind a nonce that failed
+// to broadcast is not awaited, since it cannot be mined until the gap is
+// filled; it gets ErrNonceGap and the local nonce is reset so the next
+// transaction resyncs with the node.
+func (m *Manager) SendAll(ctx context.Context, txs []*types.Transaction) (receipts []*types.Receipt, errs []error) {
+	receipts, errs = make([]*types.Receipt, len(txs)), make([]error, len(txs))
+	var wg sync.WaitGroup
+	for i, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.backend.SendTransaction(ctx, tx)
+		}()
+	}
+	wg.Wait()
+
+	gap := uint64(math.MaxUint64)
+	for i, tx := range txs {
+		if tx != nil && errs[i] != nil {
+			gap = min(gap, tx.Nonce())
+		}
+	}
+	if gap != math.MaxUint64 {
+		m.Reset()
+	}
+	for i, tx := range txs {
+		if tx == nil || errs[i] != nil {
+			continue
+		}
+		if tx.Nonce() > gap {
+			errs[i] = fmt.Errorf("%w: %s waits for nonce %d", ErrNonceGap, tx.Hash(), gap)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receipts[i], errs[i] = m.Wait(ctx, tx)
+		}()
+	}
+	wg.Wait()
+	return receipts, errs
+}
+
+// Wait blocks until tx is mined and buried under the confirmation depth. A
+// reverted transaction returns its receipt together with ErrReverted.
+func (m *Manager) Wait(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := bind.WaitMined(ctx, m.backend, tx)
+	if err != nil {
+		return nil, fmt.Errorf("txmgr: wait %s: %w", tx.Hash(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("%w: %s", ErrReverted, tx.Hash())
+	}
+	if m.confirmations == 0 {
+		return receipt, nil
+	}
+	target := receipt.BlockNumber.Uint64() + m.confirmations
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		head, err := m.backend.BlockNumber(ctx)
+		if err == nil && head >= target {
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return receipt, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
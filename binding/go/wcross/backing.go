@@ -0,0 +1,172 @@
+package wcross
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+)
+
+// DefaultInterval is the Monitor polling interval when none is set.
+const DefaultInterval = 30 * time.Second
+
+// Backing is WCROSS supply against the native balance behind it at one
+// block.
+type Backing struct {
+	Block  uint64   `json:"block"`
+	Supply *big.Int `json:"totalSupply"`
+	Native *big.Int `json:"nativeBalance"`
+	// Surplus is Native minus Supply: negative when WCROSS is under-backed,
+	// positive when native CROSS reached the contract without minting.
+	Surplus *big.Int `json:"surplus"`
+}
+
+// OK reports whether totalSupply equals the native balance.
+func (b *Backing) OK() bool {
+	return b.Surplus.Sign() == 0
+}
+
+// chain is what CheckBacking reads. *ethclient.Client satisfies it.
+type chain interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+}
+
+type supplyReader interface {
+	TotalSupply(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// CheckBacking reads totalSupply and the contract's native balance at block,
+// or at the head if block is nil.
+func CheckBacking(ctx context.Context, net *config.Network, block *big.Int) (*Backing, error) {
+	return checkBacking(ctx, net.Client, net.WCROSS, net.WCROSSAddress, block)
+}
+
+func checkBacking(ctx context.Context, c chain, token supplyReader, addr common.Address, block *big.Int) (*Backing, error) {
+	if block == nil {
+		// Pin both reads to one block so a wrap in between cannot skew them.
+		h, err := c.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("wcross: head: %w", err)
+		}
+		block = h.Number
+	}
+	supply, err := token.TotalSupply(&bind.CallOpts{Context: ctx, BlockNumber: block})
+	if err != nil {
+		return nil, fmt.Errorf("wcross: totalSupply at %s: %w", block, err)
+	}
+	native, err := c.BalanceAt(ctx, addr, block)
+	if err != nil {
+		return nil, fmt.Errorf("wcross: native balance at %s: %w", block, err)
+	}
+	return &Backing{Block: block.Uint64(), Supply: supply, Native: native, Surplus: new(big.Int).Sub(native, supply)}, nil
+}
+
+// Monitor checks the backing every Interval and alerts when it diverges.
+// One alert goes out when the backing breaks and one when it is restored;
+// while it stays broken, an alert is repeated only when the surplus
+// changes.
+type Monitor struct {
+	check func(ctx context.Context) (*Backing, error)
+	addr  common.Address
+	sinks []alert.Sink
+	now   func() time.Time
+
+	// Interval is the time between checks. Zero means DefaultInterval.
+	Interval time.Duration
+	// OnCheck, if set, receives every result.
+	OnCheck func(*Backing)
+	// OnError receives read and delivery failures; nil drops them.
+	OnError func(error)
+
+	last *Backing
+}
+
+// NewMonitor returns a Monitor for net delivering to sinks.
+func NewMonitor(net *config.Network, sinks ...alert.Sink) *Monitor {
+	return &Monitor{
+		check: func(ctx context.Context) (*Backing, error) { return CheckBacking(ctx, net, nil) },
+		addr:  net.WCROSSAddress,
+		sinks: sinks,
+		now:   time.Now,
+	}
+}
+
+// Run checks until ctx is done.
+func (m *Monitor) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.Step(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step runs one check and returns the alert it sent, if any.
+func (m *Monitor) Step(ctx context.Context) *alert.Alert {
+	b, err := m.check(ctx)
+	if err != nil {
+		m.report(err)
+		return nil
+	}
+	if m.OnCheck != nil {
+		m.OnCheck(b)
+	}
+	prev := m.last
+	m.last = b
+	var a *alert.Alert
+	switch {
+	case !b.OK() && (prev == nil || prev.Surplus.Cmp(b.Surplus) != 0):
+		a = m.alert(b, "BackingDiverged", "critical")
+	case b.OK() && prev != nil && !prev.OK():
+		a = m.alert(b, "BackingRestored", "info")
+	default:
+		return nil
+	}
+	for _, s := range m.sinks {
+		if err := s.Send(ctx, a); err != nil {
+			m.report(err)
+		}
+	}
+	return a
+}
+
+func (m *Monitor) alert(b *Backing, name, severity string) *alert.Alert {
+	return &alert.Alert{
+		Rule:     "wcross-backing",
+		Severity: severity,
+		At:       m.now(),
+		Event: alert.Event{
+			Name:     name,
+			Source:   alert.SourceWCROSS,
+			Contract: m.addr,
+			Amount:   b.Surplus,
+			Details: map[string]string{
+				"totalSupply":   b.Supply.String(),
+				"nativeBalance": b.Native.String(),
+			},
+			Block: b.Block,
+		},
+	}
+}
+
+func (m *Monitor) report(err error) {
+	if m.OnError != nil {
+		m.OnError(err)
+	}
+}
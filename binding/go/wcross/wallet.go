@@ -0,0 +1,231 @@
+// Package wcross wraps and unwraps native CROSS through the WCROSS contract
+// and checks that WCROSS stays fully backed.
+//
+// A Wallet sends from one txmgr.Manager account. Every transfer is checked
+// against the account's balances before it is signed, so an underfunded
+// wrap or unwrap fails locally with ErrInsufficientBalance instead of
+// reverting on chain. A Monitor compares totalSupply() with the native
+// balance of the WCROSS contract at the same block and alerts when they
+// diverge.
+package wcross
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	binding "github.com/to-nexus/cross-game-reward/binding/go/src"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+var depositData = mustDepositData()
+
+func mustDepositData() []byte {
+	parsed, err := binding.WCROSSMetaData.GetAbi()
+	if err != nil {
+		panic(err) // only fails on a malformed embedded ABI
+	}
+	data, err := parsed.Pack("deposit")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+var (
+	// ErrInsufficientBalance is returned when the sender cannot cover a wrap
+	// or unwrap.
+	ErrInsufficientBalance = errors.New("wcross: insufficient balance")
+	// ErrInvalidAmount is returned for zero or negative amounts.
+	ErrInvalidAmount = errors.New("wcross: amount must be positive")
+)
+
+// Balances are an account's native and wrapped holdings.
+type Balances struct {
+	Native *big.Int `json:"native"`
+	WCROSS *big.Int `json:"wcross"`
+}
+
+// walletChain is the node access a Wallet needs. *ethclient.Client
+// satisfies it.
+type walletChain interface {
+	PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// wrappedReader reads WCROSS balances. *binding.WCROSS satisfies it.
+type wrappedReader interface {
+	BalanceOf(opts *bind.CallOpts, account common.Address) (*big.Int, error)
+	Allowance(opts *bind.CallOpts, owner, spender common.Address) (*big.Int, error)
+}
+
+// Wallet wraps, unwraps and approves WCROSS from the txmgr account.
+type Wallet struct {
+	net    *config.Network
+	tm     *txmgr.Manager
+	chain  walletChain
+	wcross wrappedReader
+
+	// GasReserve is native balance Wrap leaves untouched for gas. Nil
+	// means the most the deposit itself can cost: its estimated gas limit
+	// at the fee cap the transaction will be signed with.
+	GasReserve *big.Int
+}
+
+// NewWallet returns a Wallet sending from tm's account.
+func NewWallet(net *config.Network, tm *txmgr.Manager) *Wallet {
+	return &Wallet{net: net, tm: tm, chain: net.Client, wcross: net.WCROSS}
+}
+
+// Balances returns the sender's pending native balance and WCROSS balance.
+func (w *Wallet) Balances(ctx context.Context) (Balances, error) {
+	native, err := w.chain.PendingBalanceAt(ctx, w.tm.From())
+	if err != nil {
+		return Balances{}, fmt.Errorf("wcross: native balance: %w", err)
+	}
+	wrapped, err := w.wcross.BalanceOf(w.tm.CallOpts(ctx), w.tm.From())
+	if err != nil {
+		return Balances{}, fmt.Errorf("wcross: balance: %w", err)
+	}
+	return Balances{Native: native, WCROSS: wrapped}, nil
+}
+
+// Wrap deposits amount of native CROSS and mints as much WCROSS.
+func (w *Wallet) Wrap(ctx context.Context, amount *big.Int) (*types.Receipt, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	bal, err := w.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bal.Native.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: wrap %s, have %s native", ErrInsufficientBalance, amount, bal.Native)
+	}
+	reserve, err := w.gasReserve(ctx, amount)
+	if err != nil {
+		return nil, err
+	}
+	need := new(big.Int).Add(amount, reserve)
+	if bal.Native.Cmp(need) < 0 {
+		return nil, fmt.Errorf("%w: wrap %s needs %s native, have %s", ErrInsufficientBalance, amount, need, bal.Native)
+	}
+	return w.tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		o.Value = amount
+		return w.net.WCROSS.Deposit(o)
+	})
+}
+
+// gasReserve returns GasReserve or, if it is nil, the gas limit of a
+// deposit of amount times the fee cap bind signs it with.
+func (w *Wallet) gasReserve(ctx context.Context, amount *big.Int) (*big.Int, error) {
+	if w.GasReserve != nil {
+		return w.GasReserve, nil
+	}
+	to := w.net.WCROSSAddress
+	gas, err := w.chain.EstimateGas(ctx, ethereum.CallMsg{From: w.tm.From(), To: &to, Value: amount, Data: depositData})
+	if err != nil {
+		return nil, fmt.Errorf("wcross: estimate deposit gas: %w", err)
+	}
+	head, err := w.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wcross: head: %w", err)
+	}
+	var fee *big.Int
+	if head.BaseFee != nil {
+		tip, err := w.chain.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("wcross: tip cap: %w", err)
+		}
+		fee = new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	} else if fee, err = w.chain.SuggestGasPrice(ctx); err != nil {
+		return nil, fmt.Errorf("wcross: gas price: %w", err)
+	}
+	return fee.Mul(fee, new(big.Int).SetUint64(gas)), nil
+}
+
+// Unwrap burns amount of WCROSS and returns as much native CROSS to the
+// sender.
+func (w *Wallet) Unwrap(ctx context.Context, amount *big.Int) (*types.Receipt, error) {
+	if err := w.checkWrapped(ctx, amount); err != nil {
+		return nil, err
+	}
+	return w.tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return w.net.WCROSS.Withdraw(o, amount)
+	})
+}
+
+// UnwrapTo burns amount of the sender's WCROSS and sends the native CROSS to
+// recipient.
+func (w *Wallet) UnwrapTo(ctx context.Context, recipient common.Address, amount *big.Int) (*types.Receipt, error) {
+	if recipient == (common.Address{}) {
+		return nil, errors.New("wcross: recipient is the zero address")
+	}
+	if err := w.checkWrapped(ctx, amount); err != nil {
+		return nil, err
+	}
+	return w.tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return w.net.WCROSS.WithdrawTo(o, recipient, amount)
+	})
+}
+
+func (w *Wallet) checkWrapped(ctx context.Context, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+	bal, err := w.wcross.BalanceOf(w.tm.CallOpts(ctx), w.tm.From())
+	if err != nil {
+		return fmt.Errorf("wcross: balance: %w", err)
+	}
+	if bal.Cmp(amount) < 0 {
+		return fmt.Errorf("%w: unwrap %s, have %s WCROSS", ErrInsufficientBalance, amount, bal)
+	}
+	return nil
+}
+
+// Allowance returns how much WCROSS spender may move for the sender.
+func (w *Wallet) Allowance(ctx context.Context, spender common.Address) (*big.Int, error) {
+	a, err := w.wcross.Allowance(w.tm.CallOpts(ctx), w.tm.From(), spender)
+	if err != nil {
+		return nil, fmt.Errorf("wcross: allowance: %w", err)
+	}
+	return a, nil
+}
+
+// Approve sets spender's allowance to amount.
+func (w *Wallet) Approve(ctx context.Context, spender common.Address, amount *big.Int) (*types.Receipt, error) {
+	return w.tm.Send(ctx, func(o *bind.TransactOpts) (*types.Transaction, error) {
+		return w.net.WCROSS.Approve(o, spender, amount)
+	})
+}
+
+// EnsureAllowance approves amount for spender unless the current allowance
+// already covers it, in which case it returns a nil receipt.
+func (w *Wallet) EnsureAllowance(ctx context.Context, spender common.Address, amount *big.Int) (*types.Receipt, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	cur, err := w.Allowance(ctx, spender)
+	if err != nil {
+		return nil, err
+	}
+	if cur.Cmp(amount) >= 0 {
+		return nil, nil
+	}
+	return w.Approve(ctx, spender, amount)
+}
+
+// Revoke sets spender's allowance to zero.
+func (w *Wallet) Revoke(ctx context.Context, spender common.Address) (*types.Receipt, error) {
+	return w.Approve(ctx, spender, new(big.Int))
+}
@@ -0,0 +1,209 @@
+package wcross
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/to-nexus/cross-game-reward/binding/go/alert"
+	"github.com/to-nexus/cross-game-reward/binding/go/config"
+	"github.com/to-nexus/cross-game-reward/binding/go/txmgr"
+)
+
+var token = common.HexToAddress("0xc0")
+
+type fakeChain struct {
+	head   uint64
+	supply map[uint64]int64
+	native map[uint64]int64
+}
+
+func (f *fakeChain) HeaderByNumber(_ context.Context, n *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(f.head)}, nil
+}
+
+func (f *fakeChain) BalanceAt(_ context.Context, _ common.Address, block *big.Int) (*big.Int, error) {
+	return big.NewInt(f.native[block.Uint64()]), nil
+}
+
+func (f *fakeChain) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(f.supply[opts.BlockNumber.Uint64()]), nil
+}
+
+func TestCheckBackingPinsBlock(t *testing.T) {
+	c := &fakeChain{head: 7, supply: map[uint64]int64{7: 100, 8: 150}, native: map[uint64]int64{7: 100, 8: 120}}
+	b, err := checkBacking(context.Background(), c, c, token, nil)
+	if err != nil || !b.OK() || b.Block != 7 {
+		t.Fatalf("head: %+v %v", b, err)
+	}
+	b, err = checkBacking(context.Background(), c, c, token, big.NewInt(8))
+	if err != nil || b.OK() || b.Surplus.Int64() != -30 {
+		t.Fatalf("block 8: %+v %v", b, err)
+	}
+}
+
+type sinkFunc func(a *alert.Alert)
+
+func (f sinkFunc) Send(_ context.Context, a *alert.Alert) error {
+	f(a)
+	return nil
+}
+
+func TestMonitorTransitions(t *testing.T) {
+	c := &fakeChain{supply: map[uint64]int64{}, native: map[uint64]int64{}}
+	var sent []string
+	m := &Monitor{
+		check: func(ctx context.Context) (*Backing, error) { return checkBacking(ctx, c, c, token, nil) },
+		addr:  token,
+		sinks: []alert.Sink{sinkFunc(func(a *alert.Alert) { sent = append(sent, a.Name+" "+a.Amount.String()) })},
+		now:   func() time.Time { return time.Unix(0, 0) },
+	}
+	for _, s := range []struct {
+		supply, native int64
+	}{
+		{100, 100}, // backed: nothing
+		{100, 90},  // diverged
+		{100, 90},  // unchanged: no repeat
+		{100, 80},  // worse: repeat
+		{80, 80},   // restored
+		{80, 80},   // still backed: nothing
+	} {
+		c.head++
+		c.supply[c.head], c.native[c.head] = s.supply, s.native
+		m.Step(context.Background())
+	}
+	want := "BackingDiverged -10,BackingDiverged -20,BackingRestored 0"
+	if got := strings.Join(sent, ","); got != want {
+		t.Fatalf("alerts %q, want %q", got, want)
+	}
+}
+
+func TestAlertText(t *testing.T) {
+	m := &Monitor{addr: token, now: func() time.Time { return time.Unix(0, 0) }}
+	a := m.alert(&Backing{Block: 9, Supply: big.NewInt(5), Native: big.NewInt(4), Surplus: big.NewInt(-1)}, "BackingDiverged", "critical")
+	want := "[CRITICAL] wcross-backing: BackingDiverged on wcross " + token.Hex() + " amount -1 nativeBalance=4 totalSupply=5 in block 9"
+	if got := a.Text(); got != want {
+		t.Errorf("text %q\nwant %q", got, want)
+	}
+}
+
+type fakeWallet struct {
+	native, wrapped, allowance int64
+	baseFee                    *big.Int
+	estimates                  int
+}
+
+func (f *fakeWallet) PendingBalanceAt(context.Context, common.Address) (*big.Int, error) {
+	return big.NewInt(f.native), nil
+}
+
+func (f *fakeWallet) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeWallet) SuggestGasPrice(context.Context) (*big.Int, error) { return big.NewInt(7), nil }
+
+func (f *fakeWallet) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func (f *fakeWallet) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	f.estimates++
+	if !bytes.Equal(msg.Data, depositData) {
+		return 0, errors.New("not a deposit")
+	}
+	return 100, nil
+}
+
+func (f *fakeWallet) BalanceOf(*bind.CallOpts, common.Address) (*big.Int, error) {
+	return big.NewInt(f.wrapped), nil
+}
+
+func (f *fakeWallet) Allowance(*bind.CallOpts, common.Address, common.Address) (*big.Int, error) {
+	return big.NewInt(f.allowance), nil
+}
+
+// chainID is a txmgr.Backend that only answers ChainID; the tests below
+// fail before anything is sent.
+type chainID struct{ txmgr.Backend }
+
+func (chainID) ChainID(context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func testWallet(t *testing.T, f *fakeWallet) *Wallet {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, err := txmgr.New(context.Background(), chainID{}, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Wallet{net: &config.Network{WCROSSAddress: token}, tm: tm, chain: f, wcross: f}
+}
+
+func TestGasReserve(t *testing.T) {
+	f := &fakeWallet{baseFee: big.NewInt(2)}
+	w := testWallet(t, f)
+	// 100 gas at a fee cap of tip 1 + 2 * base fee 2.
+	if r, err := w.gasReserve(context.Background(), big.NewInt(1)); err != nil || r.Int64() != 500 {
+		t.Errorf("dynamic fee reserve %v, %v; want 500", r, err)
+	}
+	f.baseFee = nil
+	if r, err := w.gasReserve(context.Background(), big.NewInt(1)); err != nil || r.Int64() != 700 {
+		t.Errorf("legacy reserve %v, %v; want 700", r, err)
+	}
+	w.GasReserve = big.NewInt(3)
+	if r, err := w.gasReserve(context.Background(), big.NewInt(1)); err != nil || r.Int64() != 3 || f.estimates != 2 {
+		t.Errorf("set reserve %v, %v after %d estimates", r, err, f.estimates)
+	}
+}
+
+func TestWalletRejectsUnderfunded(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeWallet{native: 1000, wrapped: 10, allowance: 50, baseFee: big.NewInt(2)}
+	w := testWallet(t, f)
+
+	// Wrapping the whole balance leaves nothing for the deposit's gas.
+	if _, err := w.Wrap(ctx, big.NewInt(1000)); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("wrap all: %v", err)
+	}
+	if _, err := w.Wrap(ctx, big.NewInt(501)); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("wrap into the reserve: %v", err)
+	}
+	f.estimates = 0
+	if _, err := w.Wrap(ctx, big.NewInt(1001)); !errors.Is(err, ErrInsufficientBalance) || f.estimates != 0 {
+		t.Errorf("wrap more than held: %v after %d estimates", err, f.estimates)
+	}
+	if _, err := w.Unwrap(ctx, big.NewInt(11)); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("unwrap: %v", err)
+	}
+	if _, err := w.UnwrapTo(ctx, common.Address{1}, big.NewInt(11)); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("unwrap to: %v", err)
+	}
+	if _, err := w.UnwrapTo(ctx, common.Address{}, big.NewInt(1)); err == nil {
+		t.Error("unwrap to the zero address accepted")
+	}
+	for _, amount := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1)} {
+		if _, err := w.Wrap(ctx, amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("wrap %v: %v", amount, err)
+		}
+		if _, err := w.Unwrap(ctx, amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("unwrap %v: %v", amount, err)
+		}
+		if _, err := w.EnsureAllowance(ctx, common.Address{1}, amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ensure allowance %v: %v", amount, err)
+		}
+	}
+	if r, err := w.EnsureAllowance(ctx, common.Address{1}, big.NewInt(50)); r != nil || err != nil {
+		t.Errorf("covered allowance: %v %v", r, err)
+	}
+}